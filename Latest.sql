@@ -36,10 +36,63 @@ CREATE TABLE bookings (
     total_price DECIMAL(10, 2) NOT NULL,
     
     -- 👇 UPDATE 1: Add 'refunded', 'absent', and 'expired' to the list
-    status ENUM('pending', 'confirmed', 'canceled', 'present', 'refunded', 'absent', 'expired') DEFAULT 'pending',
+    -- 👇 UPDATE 17: 'refund_initiated' while a Razorpay refund waits for the bank
+    status ENUM('pending', 'confirmed', 'canceled', 'present', 'refunded', 'absent', 'expired', 'refund_requested', 'refund_initiated', 'refund_rejected') DEFAULT 'pending',
     
     -- 👇 UPDATE 2: Add this new column
     razorpay_payment_id VARCHAR(255) NULL,
+
+    -- 👇 UPDATE 3: Pending bookings hold their slot until this time (NULL once paid/canceled)
+    hold_expires_at DATETIME NULL,
+
+    -- 👇 UPDATE 4: Occurrence of a recurring series (NULL for one-off bookings)
+    series_id INT NULL,
+
+    -- 👇 UPDATE 5: Court/table inside the venue (NULL = whole venue, e.g. an owner block)
+    court_id INT NULL,
+
+    -- 👇 UPDATE 6: Line items of the price (JSON), frozen at booking time
+    price_breakdown TEXT NULL,
+
+    -- 👇 UPDATE 7: Owner blocks are stored as bookings too; this tells them apart
+    booking_type ENUM('booking', 'block') NOT NULL DEFAULT 'booking',
+
+    -- 👇 UPDATE 8: Reschedules (same booking ID); reschedule_of marks the hold for a paid move
+    reschedule_count INT NOT NULL DEFAULT 0,
+    reschedule_of INT NULL,
+
+    -- 👇 UPDATE 9: Amount refunded under the venue's cancellation policy
+    refund_amount DECIMAL(10, 2) NULL,
+
+    -- 👇 UPDATE 10: Promo codes (total_price is after the discount)
+    discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    coupon_code VARCHAR(50) NULL,
+
+    -- 👇 UPDATE 11: Wallet (part of total_price paid from the wallet) and refund destination
+    wallet_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    refund_method ENUM('source', 'wallet') NOT NULL DEFAULT 'source',
+
+    -- 👇 UPDATE 12: Paid from a prepaid pack/pass (total_price is 0)
+    pack_purchase_id INT NULL,
+
+    -- 👇 UPDATE 13: Blocks synced from an external calendar (booking_type 'block')
+    calendar_source_id INT NULL,
+    external_uid VARCHAR(255) NULL,
+    INDEX idx_bookings_calendar_source (calendar_source_id, external_uid),
+
+    -- 👇 UPDATE 14: Ticket check-in (who scanned it and when)
+    checked_in_at DATETIME NULL,
+    checked_in_by INT NULL,
+
+    -- 👇 UPDATE 15: Offline scanner device that checked the player in
+    checked_in_device VARCHAR(100) NULL,
+
+    -- 👇 UPDATE 16: Razorpay order (matched by payment webhooks) and the refund outcome it reports
+    razorpay_order_id VARCHAR(100) NULL,
+    refund_status ENUM('processed', 'failed') NULL,
+    razorpay_refund_id VARCHAR(100) NULL,
+    INDEX idx_bookings_order (razorpay_order_id),
+    INDEX idx_bookings_payment (razorpay_payment_id),
     
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id),
@@ -64,6 +117,634 @@ INSERT INTO `bookings` (`id`, `user_id`, `venue_id`, `start_time`, `end_time`, `
 
 -- --------------------------------------------------------
 
+--
+-- Table structure for table `booking_series`
+--
+
+CREATE TABLE booking_series (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    venue_id INT NOT NULL,
+    court_id INT NULL,
+    frequency ENUM('daily', 'weekly') NOT NULL,
+    interval_count INT NOT NULL DEFAULT 1,
+    start_time DATETIME NOT NULL,
+    end_time DATETIME NOT NULL,
+    until_date DATE NULL,
+    occurrence_count INT NULL,
+    status ENUM('active', 'canceled') NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (venue_id) REFERENCES venues(id)
+);
+
+ALTER TABLE bookings
+  ADD KEY `series_id` (`series_id`),
+  ADD CONSTRAINT `bookings_series_fk` FOREIGN KEY (`series_id`) REFERENCES `booking_series` (`id`);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `booking_status_history`
+--
+
+CREATE TABLE booking_status_history (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    booking_id INT NOT NULL,
+    from_status VARCHAR(32) NULL,  -- NULL for the creation entry
+    to_status VARCHAR(32) NOT NULL,
+    actor_user_id INT NULL,        -- NULL when the system made the change
+    actor_role ENUM('player', 'owner', 'admin', 'staff', 'system') NOT NULL DEFAULT 'system',
+    reason VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_history_booking (booking_id, created_at),
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `booking_reschedules`
+--
+
+CREATE TABLE booking_reschedules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    booking_id INT NOT NULL,
+    old_start_time DATETIME NOT NULL,
+    old_end_time DATETIME NOT NULL,
+    new_start_time DATETIME NOT NULL,
+    new_end_time DATETIME NOT NULL,
+    old_court_id INT NULL,
+    new_court_id INT NULL,
+    price_difference DECIMAL(10, 2) NOT NULL DEFAULT 0, -- > 0 charged, < 0 refunded
+    payment_id VARCHAR(255) NULL,
+    refund_status ENUM('none', 'refunded', 'failed') NOT NULL DEFAULT 'none',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE
+);
+
+ALTER TABLE bookings
+  ADD CONSTRAINT `bookings_reschedule_fk` FOREIGN KEY (`reschedule_of`) REFERENCES `bookings` (`id`);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `cancellation_policy_tiers`
+--
+
+CREATE TABLE cancellation_policy_tiers (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    venue_id INT NOT NULL,
+    min_hours_before INT NOT NULL,  -- Tier applies when canceled at least this many hours before start
+    refund_percent INT NOT NULL,    -- 0-100
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_venue_tier (venue_id, min_hours_before),
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `coupons`
+--
+
+CREATE TABLE coupons (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255) NULL,
+    discount_type ENUM('percent', 'flat') NOT NULL,
+    discount_value DECIMAL(10, 2) NOT NULL,
+    max_discount DECIMAL(10, 2) NOT NULL DEFAULT 0,     -- 0 = no cap
+    min_order_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    valid_from DATETIME NULL,
+    valid_until DATETIME NULL,
+    max_uses INT NOT NULL DEFAULT 0,                     -- 0 = unlimited
+    max_uses_per_user INT NOT NULL DEFAULT 0,            -- 0 = unlimited
+    venue_id INT NULL,                                   -- NULL = every venue
+    sport_category VARCHAR(100) NULL,                    -- NULL = every sport
+    first_booking_only BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+--
+-- Table structure for table `coupon_redemptions`
+--
+
+CREATE TABLE coupon_redemptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    coupon_id INT NOT NULL,
+    user_id INT NOT NULL,
+    booking_id INT NOT NULL,
+    discount_amount DECIMAL(10, 2) NOT NULL,
+    status ENUM('reserved', 'redeemed', 'released') NOT NULL DEFAULT 'reserved',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_redemption_booking (booking_id),
+    FOREIGN KEY (coupon_id) REFERENCES coupons(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `courts`
+--
+
+CREATE TABLE courts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    venue_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    sport_category VARCHAR(50) NOT NULL DEFAULT '',
+    price_per_hour DECIMAL(10, 2) NULL, -- NULL = use the venue's price
+    is_active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE
+);
+
+ALTER TABLE bookings
+  ADD KEY `court_id` (`court_id`),
+  ADD CONSTRAINT `bookings_court_fk` FOREIGN KEY (`court_id`) REFERENCES `courts` (`id`);
+
+ALTER TABLE booking_series
+  ADD CONSTRAINT `booking_series_court_fk` FOREIGN KEY (`court_id`) REFERENCES `courts` (`id`);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `price_rules`
+--
+
+CREATE TABLE price_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    venue_id INT NOT NULL,
+    court_id INT NULL, -- NULL = every court of the venue
+    name VARCHAR(100) NOT NULL,
+    days VARCHAR(20) NOT NULL DEFAULT '', -- e.g. '0,6' (0 = Sunday); empty = every day
+    start_time VARCHAR(5) NOT NULL, -- 'HH:MM' IST
+    end_time VARCHAR(5) NOT NULL,   -- 'HH:MM' IST, '24:00' = midnight
+    price_per_hour DECIMAL(10, 2) NOT NULL,
+    priority INT NOT NULL DEFAULT 0, -- higher wins when rules overlap
+    is_active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE,
+    FOREIGN KEY (court_id) REFERENCES courts(id) ON DELETE CASCADE
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `waitlist_entries`
+--
+
+CREATE TABLE waitlist_entries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    venue_id INT NOT NULL,
+    court_id INT NULL,
+    start_time DATETIME NOT NULL,
+    end_time DATETIME NOT NULL,
+    status ENUM('waiting', 'offered', 'claimed', 'expired', 'canceled') NOT NULL DEFAULT 'waiting',
+    offer_booking_id INT NULL, -- pending booking holding the slot for this player
+    offer_expires_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    KEY venue_slot (venue_id, status, start_time),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE,
+    FOREIGN KEY (court_id) REFERENCES courts(id),
+    FOREIGN KEY (offer_booking_id) REFERENCES bookings(id)
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `wallet_accounts`
+-- Double-entry ledger: one account per player plus system accounts (code set, user_id NULL).
+-- The entries of every transaction sum to zero; balance caches the sum of an account's entries.
+--
+
+CREATE TABLE wallet_accounts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NULL UNIQUE,
+    code VARCHAR(50) NULL UNIQUE,       -- 'gateway', 'booking_holds', 'booking_revenue'
+    balance DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE wallet_transactions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    kind VARCHAR(30) NOT NULL,          -- topup, booking_hold, booking_capture, booking_release, refund
+    reference VARCHAR(100) NOT NULL,    -- e.g. 'booking:42', 'topup:7'
+    description VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_wallet_txn (kind, reference)
+);
+
+CREATE TABLE wallet_entries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    account_id INT NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL,     -- > 0 credit, < 0 debit
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_entries_account (account_id, created_at),
+    FOREIGN KEY (transaction_id) REFERENCES wallet_transactions(id),
+    FOREIGN KEY (account_id) REFERENCES wallet_accounts(id)
+);
+
+CREATE TABLE wallet_topups (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    razorpay_order_id VARCHAR(255) NULL,
+    razorpay_payment_id VARCHAR(255) NULL,
+    status ENUM('pending', 'completed') NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `venue_packages` (prepaid hour packs and unlimited passes)
+--
+
+CREATE TABLE venue_packages (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    venue_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    kind ENUM('hours', 'unlimited') NOT NULL,
+    hours DECIMAL(6, 2) NOT NULL DEFAULT 0,   -- Hour packs only
+    validity_days INT NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    days VARCHAR(20) NULL,                    -- e.g. "1,2,3,4,5"; NULL = every day
+    start_time VARCHAR(5) NULL,               -- "HH:MM" IST; NULL = no time window
+    end_time VARCHAR(5) NULL,
+    is_active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE
+);
+
+CREATE TABLE pack_purchases (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    package_id INT NOT NULL,
+    user_id INT NOT NULL,
+    venue_id INT NOT NULL,
+    kind ENUM('hours', 'unlimited') NOT NULL,
+    hours_total DECIMAL(6, 2) NOT NULL DEFAULT 0,
+    price DECIMAL(10, 2) NOT NULL,
+    status ENUM('pending', 'active') NOT NULL DEFAULT 'pending',
+    valid_from DATETIME NULL,
+    valid_until DATETIME NULL,
+    razorpay_order_id VARCHAR(255) NULL,
+    razorpay_payment_id VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_pack_purchases_user (user_id, venue_id, status, valid_until),
+    FOREIGN KEY (package_id) REFERENCES venue_packages(id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE
+);
+
+CREATE TABLE pack_usages (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    purchase_id INT NOT NULL,
+    booking_id INT NOT NULL UNIQUE,
+    hours DECIMAL(6, 2) NOT NULL,
+    status ENUM('used', 'returned') NOT NULL DEFAULT 'used',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (purchase_id) REFERENCES pack_purchases(id) ON DELETE CASCADE,
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE
+);
+
+ALTER TABLE `bookings`
+  ADD CONSTRAINT `bookings_pack_purchase_fk` FOREIGN KEY (`pack_purchase_id`) REFERENCES `pack_purchases` (`id`);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `calendar_feeds` (iCal subscription URLs)
+--
+
+CREATE TABLE calendar_feeds (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    venue_id INT NULL,                    -- NULL = the user's own bookings
+    token CHAR(48) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at DATETIME NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `calendar_sources` (external calendars imported as blocks)
+--
+
+CREATE TABLE calendar_sources (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    venue_id INT NOT NULL,
+    court_id INT NULL,                    -- NULL = blocks the whole venue
+    name VARCHAR(100) NOT NULL,
+    kind ENUM('url', 'upload') NOT NULL,
+    url VARCHAR(1000) NULL,
+    created_by INT NOT NULL,              -- Synced blocks are owned by this user
+    last_synced_at DATETIME NULL,
+    last_error VARCHAR(255) NULL,
+    is_active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE,
+    FOREIGN KEY (court_id) REFERENCES courts(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+ALTER TABLE `bookings`
+  ADD CONSTRAINT `bookings_calendar_source_fk` FOREIGN KEY (`calendar_source_id`) REFERENCES `calendar_sources` (`id`);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `venue_staff` (front-desk users who can check players in)
+--
+
+CREATE TABLE venue_staff (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    venue_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_venue_staff (venue_id, user_id),
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `venue_tax_profiles` (GST registration printed on invoices)
+--
+
+CREATE TABLE venue_tax_profiles (
+    venue_id INT PRIMARY KEY,
+    legal_name VARCHAR(255) NOT NULL,
+    gstin CHAR(15) NOT NULL,
+    address TEXT NOT NULL,
+    state_code CHAR(2) NOT NULL,          -- Where the venue is (place of supply)
+    gst_rate DECIMAL(5,2) NOT NULL DEFAULT 18.00,
+    sac_code VARCHAR(8) NOT NULL DEFAULT '999652',
+    invoice_prefix VARCHAR(4) NOT NULL DEFAULT 'INV',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_gstin_prefix (gstin, invoice_prefix),
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE
+);
+
+--
+-- Table structure for table `invoice_sequences` (gapless numbering per venue, financial year and document type)
+--
+
+CREATE TABLE invoice_sequences (
+    venue_id INT NOT NULL,
+    financial_year CHAR(7) NOT NULL,      -- e.g. '2026-27'
+    doc_type ENUM('invoice', 'credit_note') NOT NULL,
+    last_number INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (venue_id, financial_year, doc_type),
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE
+);
+
+--
+-- Table structure for table `invoices` (tax invoices and credit notes; party details are copied at issue)
+--
+
+CREATE TABLE invoices (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    venue_id INT NOT NULL,
+    booking_id INT NOT NULL,
+    user_id INT NOT NULL,
+    doc_type ENUM('invoice', 'credit_note') NOT NULL,
+    number VARCHAR(16) NOT NULL,
+    financial_year CHAR(7) NOT NULL,
+    original_invoice_id INT NULL,         -- Credit notes: the invoice they reverse
+    issued_at DATETIME NOT NULL,
+    supplier_name VARCHAR(255) NOT NULL,
+    supplier_gstin CHAR(15) NOT NULL,
+    supplier_address TEXT NOT NULL,
+    supplier_state CHAR(2) NOT NULL,
+    buyer_name VARCHAR(255) NOT NULL,
+    buyer_gstin CHAR(15) NULL,
+    buyer_address TEXT NULL,
+    buyer_state CHAR(2) NULL,
+    place_of_supply CHAR(2) NOT NULL,
+    sac_code VARCHAR(8) NOT NULL,
+    description VARCHAR(500) NOT NULL,
+    hours DECIMAL(6,2) NOT NULL,
+    taxable_value DECIMAL(10,2) NOT NULL,
+    cgst_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    cgst_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    sgst_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    sgst_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    igst_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    igst_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    total DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_invoice_number (venue_id, financial_year, doc_type, number),
+    INDEX idx_invoices_booking (booking_id),
+    FOREIGN KEY (venue_id) REFERENCES venues(id),
+    FOREIGN KEY (booking_id) REFERENCES bookings(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (original_invoice_id) REFERENCES invoices(id)
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `venue_commission_rates` (platform commission overrides; others use the default)
+--
+
+CREATE TABLE venue_commission_rates (
+    venue_id INT PRIMARY KEY,
+    commission_percent DECIMAL(5,2) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE CASCADE
+);
+
+--
+-- Table structure for table `settlement_payouts` (periodic statements of what each owner is owed)
+--
+
+CREATE TABLE settlement_payouts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    owner_id INT NOT NULL,
+    period_start DATETIME NOT NULL,
+    period_end DATETIME NOT NULL,
+    gross DECIMAL(12,2) NOT NULL DEFAULT 0,
+    refunds DECIMAL(12,2) NOT NULL DEFAULT 0,
+    commission DECIMAL(12,2) NOT NULL DEFAULT 0,
+    gateway_fees DECIMAL(12,2) NOT NULL DEFAULT 0,
+    net DECIMAL(12,2) NOT NULL DEFAULT 0,
+    entry_count INT NOT NULL DEFAULT 0,
+    status ENUM('pending', 'paid') NOT NULL DEFAULT 'pending',
+    paid_at DATETIME NULL,
+    paid_by INT NULL,
+    reference VARCHAR(100) NULL,          -- Bank transfer UTR
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_owner_period (owner_id, period_end),
+    FOREIGN KEY (owner_id) REFERENCES users(id),
+    FOREIGN KEY (paid_by) REFERENCES users(id)
+);
+
+--
+-- Table structure for table `settlement_entries` (owner ledger: one line per sale, refund or adjustment)
+--
+
+CREATE TABLE settlement_entries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    venue_id INT NOT NULL,
+    owner_id INT NOT NULL,
+    booking_id INT NULL,
+    pack_purchase_id INT NULL,
+    entry_type ENUM('booking', 'refund', 'adjustment', 'pack_purchase') NOT NULL,
+    reference VARCHAR(64) NOT NULL,       -- e.g. 'booking:12', 'refund:12'; makes recording idempotent
+    gross DECIMAL(10,2) NOT NULL,         -- Negative for money going back to the player
+    commission_rate DECIMAL(5,2) NOT NULL,
+    commission DECIMAL(10,2) NOT NULL,
+    gateway_fee DECIMAL(10,2) NOT NULL DEFAULT 0,
+    net DECIMAL(10,2) NOT NULL,           -- Owed to the owner: gross - commission - gateway_fee
+    payout_id INT NULL,                   -- Set once the line is on a statement
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_settlement_reference (reference),
+    INDEX idx_settlement_owner (owner_id, payout_id),
+    FOREIGN KEY (venue_id) REFERENCES venues(id),
+    FOREIGN KEY (owner_id) REFERENCES users(id),
+    FOREIGN KEY (booking_id) REFERENCES bookings(id),
+    FOREIGN KEY (pack_purchase_id) REFERENCES pack_purchases(id),
+    FOREIGN KEY (payout_id) REFERENCES settlement_payouts(id)
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `webhook_events` (Razorpay deliveries, deduplicated by event ID)
+--
+
+CREATE TABLE webhook_events (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    event_id VARCHAR(100) NOT NULL,       -- X-Razorpay-Event-Id
+    event_type VARCHAR(50) NOT NULL,
+    payload MEDIUMTEXT NOT NULL,
+    status ENUM('received', 'processed', 'ignored', 'failed') NOT NULL DEFAULT 'received',
+    error TEXT NULL,
+    attempts INT NOT NULL DEFAULT 1,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    processed_at DATETIME NULL,
+    UNIQUE KEY uniq_webhook_event (event_id)
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `payment_orders` (every checkout created with the payment gateway)
+--
+
+CREATE TABLE payment_orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id VARCHAR(100) NOT NULL,
+    provider VARCHAR(20) NOT NULL,        -- 'razorpay' or 'fake'
+    purpose ENUM('booking', 'series', 'topup', 'pack') NOT NULL,
+    reference_id INT NOT NULL,            -- Booking, series, top-up or pack purchase ID
+    user_id INT NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'INR',
+    receipt VARCHAR(64) NOT NULL,
+    status ENUM('created', 'attempted', 'paid', 'expired', 'refunded') NOT NULL DEFAULT 'created',
+    last_checked_at DATETIME NULL,        -- Last asked about by the reconciliation job
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_payment_order (order_id),
+    INDEX idx_payment_orders_reference (purpose, reference_id),
+    INDEX idx_payment_orders_status (status, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `payment_attempts` (payments made against an order)
+--
+
+CREATE TABLE payment_attempts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id VARCHAR(100) NOT NULL,
+    payment_id VARCHAR(100) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL,          -- Gateway payment status: created, authorized, captured, failed, refunded
+    source ENUM('checkout', 'webhook', 'reconcile') NOT NULL,
+    error TEXT NULL,
+    gateway_response TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_payment_attempt (payment_id),
+    INDEX idx_payment_attempts_order (order_id),
+    FOREIGN KEY (order_id) REFERENCES payment_orders(order_id)
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `idempotency_keys` (responses replayed to retried POSTs, per user)
+--
+
+CREATE TABLE idempotency_keys (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    idem_key VARCHAR(100) NOT NULL,       -- Idempotency-Key header
+    fingerprint CHAR(64) NOT NULL,        -- SHA-256 of method, path and body
+    status ENUM('processing', 'completed') NOT NULL DEFAULT 'processing',
+    response_code INT NULL,
+    response_content_type VARCHAR(100) NULL,
+    response_body MEDIUMBLOB NULL,
+    started_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    UNIQUE KEY uniq_idempotency_key (user_id, idem_key),
+    INDEX idx_idempotency_expiry (expires_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- --------------------------------------------------------
+
+--
+-- Table structure for table `refunds` (money given back on gateway payments, tracked until the bank confirms it)
+--
+
+CREATE TABLE refunds (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    reference VARCHAR(100) NOT NULL,      -- e.g. 'cancel:12', 'reschedule:7', 'late:pay_X'; makes refunding idempotent
+    booking_id INT NULL,
+    user_id INT NOT NULL,
+    payment_id VARCHAR(100) NOT NULL,
+    gateway_refund_id VARCHAR(100) NULL,  -- Of the latest accepted attempt
+    amount DECIMAL(10, 2) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    status ENUM('initiated', 'processed', 'failed') NOT NULL DEFAULT 'initiated',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    next_retry_at DATETIME NULL,          -- Set while a failed refund is waiting to be retried
+    processed_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_refund_reference (reference),
+    UNIQUE KEY uniq_gateway_refund (gateway_refund_id),
+    INDEX idx_refunds_payment (payment_id),
+    INDEX idx_refunds_booking (booking_id),
+    INDEX idx_refunds_retry (status, next_retry_at),
+    FOREIGN KEY (booking_id) REFERENCES bookings(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- --------------------------------------------------------
+
 --
 -- Table structure for table `notifications`
 --
@@ -229,7 +910,10 @@ CREATE TABLE `venues` (
   `opening_time` varchar(10) NOT NULL DEFAULT '06:00',
   `closing_time` varchar(10) NOT NULL DEFAULT '23:00',
   `lunch_start_time` varchar(10) DEFAULT NULL,
-  `lunch_end_time` varchar(10) DEFAULT NULL
+  `lunch_end_time` varchar(10) DEFAULT NULL,
+  `slot_duration_minutes` int(11) NOT NULL DEFAULT 60,
+  `max_reschedules` int(11) NOT NULL DEFAULT 1,
+  `reschedule_cutoff_hours` int(11) NOT NULL DEFAULT 2
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
 
 --
@@ -274,7 +958,7 @@ INSERT INTO `venue_photos` (`id`, `venue_id`, `image_url`, `created_at`) VALUES
 --
 ALTER TABLE `bookings`
   ADD PRIMARY KEY (`id`),
-  ADD UNIQUE KEY `unique_booking` (`venue_id`,`start_time`),
+  ADD KEY `venue_slot` (`venue_id`,`start_time`,`end_time`),
   ADD KEY `user_id` (`user_id`);
 
 --
@@ -0,0 +1,111 @@
+// api/idempotency.go
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyTTL is how long a key's response is kept for replay
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// maxIdempotentBody is the largest body fingerprinted; bigger requests with a key are refused
+const maxIdempotentBody = 1 << 20
+
+// idempotencyRecorder copies the response as it is written so it can be stored
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes a POST safe to retry. When the client sends an Idempotency-Key header,
+// the first response is stored for ttl and replayed for any retry with the same key, instead of
+// running the handler again. Reusing a key for a different request is rejected.
+// Keys are per user, so this must come after AuthMiddleware. Requests without a key run as usual.
+func IdempotencyMiddleware(ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 100 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 100 characters"})
+			return
+		}
+		userID := c.MustGet("userID").(int64)
+
+		// 1. Fingerprint the request: method, path and body
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBody+1))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Could not read request body"})
+			return
+		}
+		if len(body) > maxIdempotentBody {
+			// Running the handler on a cut-off body (and fingerprinting only part of it) is never right
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256([]byte(c.Request.Method + "\n" + c.Request.URL.RequestURI() + "\n" + string(body)))
+		fingerprint := hex.EncodeToString(sum[:])
+
+		// 2. Claim the key, or find what it was used for
+		existing, err := idempotency.Claim(userID, key, fingerprint, ttl)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not check Idempotency-Key"})
+			return
+		}
+		if existing != nil {
+			if existing.Fingerprint != fingerprint {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "This Idempotency-Key was already used for a different request"})
+				return
+			}
+			if existing.Status != idempotency.StatusCompleted {
+				c.Header("Retry-After", "1")
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress"})
+				return
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(existing.ResponseCode, existing.ContentType, existing.Body)
+			c.Abort()
+			return
+		}
+
+		// 3. Run the request and keep its response. Server errors free the key for a retry.
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			if !completed {
+				_ = idempotency.Release(userID, key) // The handler panicked
+			}
+		}()
+
+		c.Next()
+
+		completed = true
+		if status := recorder.Status(); status >= http.StatusInternalServerError {
+			_ = idempotency.Release(userID, key)
+		} else {
+			_ = idempotency.Complete(userID, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// api/idempotency_test.go
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotencyRejectsOversizedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handled := false
+	r.POST("/bookings",
+		func(c *gin.Context) { c.Set("userID", int64(1)) },
+		IdempotencyMiddleware(time.Hour),
+		func(c *gin.Context) { handled = true },
+	)
+
+	body := bytes.Repeat([]byte("a"), maxIdempotentBody+1)
+	req := httptest.NewRequest(http.MethodPost, "/bookings", bytes.NewReader(body))
+	req.Header.Set("Idempotency-Key", "k1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d, want 413", w.Code)
+	}
+	if handled {
+		t.Fatal("the handler should not run on a cut-off body")
+	}
+}
@@ -3,12 +3,18 @@ package api
 
 import (
 	"github.com/JkD004/playarena-backend/booking"
+	"github.com/JkD004/playarena-backend/coupon"
+	"github.com/JkD004/playarena-backend/invoice"
 	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/pack"
 	"github.com/JkD004/playarena-backend/payment"
+	"github.com/JkD004/playarena-backend/refund"
 	"github.com/JkD004/playarena-backend/settings"
+	"github.com/JkD004/playarena-backend/settlement"
 	"github.com/JkD004/playarena-backend/team"
 	"github.com/JkD004/playarena-backend/user"
 	"github.com/JkD004/playarena-backend/venue"
+	"github.com/JkD004/playarena-backend/wallet"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,6 +23,9 @@ func SetupRoutes(router *gin.Engine) {
 	// Global Middleware
 	router.Use(MaintenanceMiddleware())
 
+	// Retried POSTs with the same Idempotency-Key get the first response instead of a second booking/order
+	idempotent := IdempotencyMiddleware(IdempotencyKeyTTL)
+
 	v1 := router.Group("/api/v1")
 	{
 		// ==========================================
@@ -40,6 +49,12 @@ func SetupRoutes(router *gin.Engine) {
 		v1.GET("/venues/:id", venue.GetVenueByIDHandler)
 		v1.GET("/venues/:id/photos", venue.GetVenuePhotosHandler)
 		v1.GET("/venues/:id/slots", booking.GetBookedSlotsHandler)
+		v1.GET("/venues/:id/slots/grid", booking.GetSlotGridHandler)
+		v1.GET("/venues/:id/courts", venue.GetCourtsHandler)
+		v1.GET("/venues/:id/price-rules", venue.GetPriceRulesHandler)
+		v1.GET("/venues/:id/cancellation-policy", venue.GetCancellationPolicyHandler)
+		v1.GET("/venues/:id/packages", pack.GetVenuePackagesHandler)
+		v1.POST("/bookings/quote", booking.QuoteBookingHandler)
 		v1.GET("/venues/:id/reviews", venue.GetReviewsHandler)
 
 		// --- General ---
@@ -47,8 +62,12 @@ func SetupRoutes(router *gin.Engine) {
 		v1.GET("/debug/email", booking.TestLiveEmailHandler)
 
 		// --- Downloads ---
-		// Publicly accessible to allow direct email links to work
+		// Publicly accessible to allow direct email links to work; the links carry a signed token
 		v1.GET("/bookings/:id/ticket", booking.DownloadTicketHandler)
+		v1.GET("/bookings/:id/calendar.ics", booking.DownloadBookingICSHandler)
+		v1.GET("/tickets/keys", booking.TicketKeysHandler) // Public keys for offline ticket verification
+		v1.POST("/payment/webhook", payment.RazorpayWebhookHandler) // Razorpay events, checked by signature
+		v1.GET("/calendar/:token", booking.CalendarFeedHandler) // Subscribed by calendar apps; the token is the credential
 
 		// ==========================================
 		//        USER ROUTES (Player, Owner, Admin)
@@ -60,14 +79,69 @@ func SetupRoutes(router *gin.Engine) {
 		v1.POST("/profile/avatar", AuthMiddleware("player", "owner", "admin"), user.UploadProfilePicHandler)
 
 		// --- Booking & Payments ---
-		v1.POST("/bookings", AuthMiddleware("player", "owner", "admin"), booking.CreateBookingHandler)
+		v1.POST("/bookings", AuthMiddleware("player", "owner", "admin"), idempotent, booking.CreateBookingHandler)
 		v1.GET("/bookings/mine", AuthMiddleware("player", "owner", "admin"), booking.GetUserBookingsHandler)
 		v1.PATCH("/bookings/:id/cancel", AuthMiddleware("player", "owner", "admin"), booking.CancelBookingHandler)
+		v1.POST("/bookings/:id/reschedule", AuthMiddleware("player", "owner", "admin"), idempotent, booking.RescheduleBookingHandler)
+		v1.GET("/bookings/:id/reschedules", AuthMiddleware("player", "owner", "admin"), booking.GetReschedulesHandler)
+		v1.GET("/bookings/:id/timeline", AuthMiddleware("player", "owner", "admin"), booking.GetBookingTimelineHandler)
+
+		// --- Recurring Series (player who booked, venue owner or admin) ---
+		v1.POST("/bookings/series/preview", AuthMiddleware("player", "owner", "admin"), booking.PreviewSeriesHandler)
+		v1.POST("/bookings/series", AuthMiddleware("player", "owner", "admin"), idempotent, booking.CreateSeriesHandler)
+		v1.GET("/bookings/series/:id", AuthMiddleware("player", "owner", "admin"), booking.GetSeriesHandler)
+		v1.PATCH("/bookings/series/:id/cancel", AuthMiddleware("player", "owner", "admin"), booking.CancelSeriesHandler)
+		v1.PATCH("/bookings/series/:id/occurrences/:bookingId/skip", AuthMiddleware("player", "owner", "admin"), booking.SkipOccurrenceHandler)
+		v1.POST("/payment/create-order/series/:id", AuthMiddleware("player", "owner", "admin"), idempotent, payment.CreateSeriesOrderHandler)
+
+		// --- Waitlist (offers arrive as held bookings, paid through the normal order flow) ---
+		v1.POST("/waitlist", AuthMiddleware("player", "owner", "admin"), booking.JoinWaitlistHandler)
+		v1.GET("/waitlist/mine", AuthMiddleware("player", "owner", "admin"), booking.GetMyWaitlistHandler)
+		v1.DELETE("/waitlist/:id", AuthMiddleware("player", "owner", "admin"), booking.LeaveWaitlistHandler)
 		
-		v1.POST("/bookings/:id/pay", AuthMiddleware("player", "owner", "admin"), booking.ProcessPaymentHandler) // Manual/Test
-		v1.POST("/payment/create-order/:id", AuthMiddleware("player", "owner", "admin"), payment.CreateOrderHandler)
+		v1.POST("/payment/create-order/:id", AuthMiddleware("player", "owner", "admin"), idempotent, payment.CreateOrderHandler)
+
+		// Wallet
+		v1.GET("/wallet", AuthMiddleware("player", "owner", "admin"), wallet.GetWalletHandler)
+		v1.POST("/wallet/topup", AuthMiddleware("player", "owner", "admin"), idempotent, wallet.CreateTopupHandler)
+		v1.POST("/wallet/topup/verify", AuthMiddleware("player", "owner", "admin"), wallet.VerifyTopupHandler)
+
+		// Calendar feeds
+		v1.GET("/calendar-feeds", AuthMiddleware("player", "owner", "admin"), booking.GetCalendarFeedsHandler)
+		v1.POST("/calendar-feeds", AuthMiddleware("player", "owner", "admin"), booking.CreateCalendarFeedHandler)
+		v1.DELETE("/calendar-feeds/:id", AuthMiddleware("player", "owner", "admin"), booking.RevokeCalendarFeedHandler)
+
+		// Prepaid packs & passes
+		v1.POST("/packages/:id/purchase", AuthMiddleware("player", "owner", "admin"), idempotent, pack.PurchasePackageHandler)
+		v1.POST("/pack-purchases/:id/verify", AuthMiddleware("player", "owner", "admin"), pack.VerifyPurchaseHandler)
+		v1.GET("/pack-purchases/:id/usage", AuthMiddleware("player", "owner", "admin"), pack.GetPurchaseUsageHandler)
+		v1.GET("/packs/mine", AuthMiddleware("player", "owner", "admin"), pack.GetMyPurchasesHandler)
+
+		// GST invoices & credit notes
+		v1.POST("/bookings/:id/invoice", AuthMiddleware("player", "owner", "admin"), booking.IssueInvoiceHandler)
+		v1.GET("/bookings/:id/invoices", AuthMiddleware("player", "owner", "admin"), booking.GetBookingInvoicesHandler)
+		v1.GET("/bookings/:id/refunds", AuthMiddleware("player", "owner", "admin"), booking.GetBookingRefundsHandler)
+		v1.GET("/invoices/:id/pdf", AuthMiddleware("player", "owner", "admin"), invoice.DownloadInvoiceHandler)
+		v1.GET("/venues/:id/tax-profile", AuthMiddleware("owner", "admin"), invoice.GetTaxProfileHandler)
+		v1.PUT("/venues/:id/tax-profile", AuthMiddleware("owner", "admin"), invoice.SaveTaxProfileHandler)
+		v1.GET("/venues/:id/invoices", AuthMiddleware("owner", "admin"), invoice.GetVenueInvoicesHandler)
+
+		// Coupons
+		v1.POST("/coupons/validate", AuthMiddleware("player", "owner", "admin"), booking.PreviewCouponHandler)
+		v1.GET("/coupons", AuthMiddleware("owner", "admin"), coupon.GetCouponsHandler)
+		v1.POST("/coupons", AuthMiddleware("owner", "admin"), coupon.CreateCouponHandler)
+		v1.DELETE("/coupons/:id", AuthMiddleware("owner", "admin"), coupon.DeleteCouponHandler)
+		v1.GET("/coupons/:id/redemptions", AuthMiddleware("owner", "admin"), coupon.GetCouponReportHandler)
+
 		v1.POST("/payment/verify", AuthMiddleware("player", "owner", "admin"), payment.VerifyPaymentHandler)
-		v1.POST("/bookings/verify", booking.VerifyTicketHandler)
+		v1.POST("/payment/failed", AuthMiddleware("player", "owner", "admin"), payment.PaymentFailedHandler)
+		if payment.FakeGatewayEnabled() {
+			v1.POST("/payment/fake/checkout", AuthMiddleware("player", "owner", "admin"), payment.FakeCheckoutHandler) // Local development only
+			v1.POST("/bookings/:id/pay", AuthMiddleware("player", "owner", "admin"), booking.ProcessPaymentHandler)    // Manual/Test, confirms without paying
+		}
+		v1.POST("/bookings/verify", AuthMiddleware("player", "owner", "admin"), booking.VerifyTicketHandler) // Check-in; venue owner/staff only (checked in the service)
+		v1.GET("/venues/:id/checkin-manifest", AuthMiddleware("player", "owner", "admin"), booking.GetCheckInManifestHandler) // Owner/staff
+		v1.POST("/venues/:id/checkins/sync", AuthMiddleware("player", "owner", "admin"), booking.SyncCheckInsHandler)        // Owner/staff
 
 		// --- Teams & Chat ---
 		v1.POST("/teams", AuthMiddleware("player", "owner", "admin"), team.CreateTeamHandler)
@@ -91,8 +165,27 @@ func SetupRoutes(router *gin.Engine) {
 		// --- Venue Management ---
 		v1.POST("/venues", AuthMiddleware("player", "owner", "admin"), venue.CreateVenueHandler) // Players can become owners by creating
 		v1.PUT("/venues/:id", AuthMiddleware("owner", "admin"), venue.UpdateVenueHandler)
+		v1.PUT("/venues/:id/reschedule-policy", AuthMiddleware("owner", "admin"), venue.UpdateReschedulePolicyHandler)
+		v1.PUT("/venues/:id/cancellation-policy", AuthMiddleware("owner", "admin"), venue.UpdateCancellationPolicyHandler)
+		v1.POST("/venues/:id/packages", AuthMiddleware("owner", "admin"), pack.CreatePackageHandler)
+		v1.DELETE("/packages/:id", AuthMiddleware("owner", "admin"), pack.DeletePackageHandler)
+		v1.GET("/venues/:id/pack-purchases", AuthMiddleware("owner", "admin"), pack.GetVenuePurchasesHandler)
+		v1.GET("/venues/:id/staff", AuthMiddleware("owner", "admin"), venue.GetVenueStaffHandler)
+		v1.POST("/venues/:id/staff", AuthMiddleware("owner", "admin"), venue.AddVenueStaffHandler)
+		v1.DELETE("/venues/:id/staff/:userId", AuthMiddleware("owner", "admin"), venue.RemoveVenueStaffHandler)
+		v1.GET("/venues/:id/calendar-sources", AuthMiddleware("owner", "admin"), booking.GetCalendarSourcesHandler)
+		v1.POST("/venues/:id/calendar-sources", AuthMiddleware("owner", "admin"), booking.CreateCalendarSourceHandler)
+		v1.POST("/venues/:id/calendar-sources/upload", AuthMiddleware("owner", "admin"), booking.UploadCalendarHandler)
+		v1.POST("/calendar-sources/:id/sync", AuthMiddleware("owner", "admin"), booking.SyncCalendarSourceHandler)
+		v1.DELETE("/calendar-sources/:id", AuthMiddleware("owner", "admin"), booking.DeleteCalendarSourceHandler)
 		v1.POST("/venues/:id/photos", AuthMiddleware("owner", "admin"), venue.UploadVenuePhotoHandler)
 		v1.DELETE("/photos/:id", AuthMiddleware("owner", "admin"), venue.DeleteVenuePhotoHandler)
+		v1.POST("/venues/:id/courts", AuthMiddleware("owner", "admin"), venue.CreateCourtHandler)
+		v1.PUT("/courts/:id", AuthMiddleware("owner", "admin"), venue.UpdateCourtHandler)
+		v1.DELETE("/courts/:id", AuthMiddleware("owner", "admin"), venue.DeleteCourtHandler)
+		v1.POST("/venues/:id/price-rules", AuthMiddleware("owner", "admin"), venue.CreatePriceRuleHandler)
+		v1.PUT("/price-rules/:id", AuthMiddleware("owner", "admin"), venue.UpdatePriceRuleHandler)
+		v1.DELETE("/price-rules/:id", AuthMiddleware("owner", "admin"), venue.DeletePriceRuleHandler)
 		v1.GET("/venues/mine", AuthMiddleware("owner", "admin"), venue.GetOwnerVenuesHandler)
 		v1.POST("/reviews/:id/reply", AuthMiddleware("owner", "admin"), venue.ReplyReviewHandler)
 
@@ -108,6 +201,12 @@ func SetupRoutes(router *gin.Engine) {
 		v1.GET("/owner/stats/by-venue", AuthMiddleware("owner", "admin"), booking.GetOwnerGroupedStatsHandler)
 		v1.GET("/owner/stats/global", AuthMiddleware("owner"), booking.GetOwnerGlobalStatsHandler)
 
+		// --- Settlements (admins pass ?owner_id=) ---
+		v1.GET("/owner/settlements/balance", AuthMiddleware("owner", "admin"), settlement.GetBalanceHandler)
+		v1.GET("/owner/settlements/statements", AuthMiddleware("owner", "admin"), settlement.GetStatementsHandler)
+		v1.GET("/owner/settlements/statements/:id", AuthMiddleware("owner", "admin"), settlement.GetStatementHandler)
+		v1.GET("/owner/settlements/statements/:id/csv", AuthMiddleware("owner", "admin"), settlement.ExportStatementHandler)
+
 		// ==========================================
 		//            ADMIN ROUTES (Admin Only)
 		// ==========================================
@@ -130,6 +229,19 @@ func SetupRoutes(router *gin.Engine) {
 		v1.GET("/admin/stats/global", AuthMiddleware("admin"), booking.GetAdminGlobalStatsHandler)
 		v1.GET("/admin/stats/grouped", AuthMiddleware("admin"), booking.GetGroupedStatsHandler) // (Duplicate alias kept for compatibility)
 
+		// --- Payouts ---
+		v1.GET("/admin/payouts", AuthMiddleware("admin"), settlement.GetPayoutsHandler)
+		v1.POST("/admin/payouts/generate", AuthMiddleware("admin"), settlement.GeneratePayoutsHandler)
+		v1.PATCH("/admin/payouts/:id/paid", AuthMiddleware("admin"), settlement.MarkPayoutPaidHandler)
+		v1.GET("/admin/payment-orders", AuthMiddleware("admin"), payment.GetPaymentOrdersHandler)
+		v1.POST("/admin/payment-orders/reconcile", AuthMiddleware("admin"), payment.ReconcilePaymentsHandler)
+		v1.GET("/admin/payment-orders/:order_id", AuthMiddleware("admin"), payment.GetPaymentOrderHandler)
+		v1.GET("/admin/refunds", AuthMiddleware("admin"), refund.GetRefundsHandler)
+		v1.POST("/admin/refunds/retry", AuthMiddleware("admin"), refund.RetryDueRefundsHandler)
+		v1.POST("/admin/refunds/:id/retry", AuthMiddleware("admin"), refund.RetryRefundHandler)
+		v1.GET("/admin/venues/:id/commission", AuthMiddleware("admin"), settlement.GetCommissionHandler)
+		v1.PUT("/admin/venues/:id/commission", AuthMiddleware("admin"), settlement.SetCommissionHandler)
+
 		// --- System ---
 		v1.PUT("/admin/terms", AuthMiddleware("admin"), settings.UpdateTermsHandler)
 	}
@@ -3,20 +3,17 @@ package booking
 
 import (
 	//"github.com/JkD004/playarena-backend/venue"
-	"crypto/hmac"
-	"crypto/sha256"
-	"crypto/subtle"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"log"
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
-	"github.com/JkD004/playarena-backend/gateway"
 	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/pkg/ticket"
 	"github.com/JkD004/playarena-backend/pkg/utils"
 	"github.com/JkD004/playarena-backend/user"
 	"github.com/JkD004/playarena-backend/venue"
@@ -62,6 +59,25 @@ func TestLiveEmailHandler(c *gin.Context) {
 	})
 }
 
+// QuoteBookingHandler handles POST /api/v1/bookings/quote
+// Same body as a booking; returns the price and its line items without reserving the slot.
+func QuoteBookingHandler(c *gin.Context) {
+	var req CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	quote, err := QuoteBooking(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	quote.Cancellation, _ = venue.GetCancellationPolicy(req.VenueID)
+
+	c.JSON(http.StatusOK, quote)
+}
+
 // CreateBookingHandler handles POST requests to create a booking
 func CreateBookingHandler(c *gin.Context) {
 	var req CreateBookingRequest
@@ -75,6 +91,10 @@ func CreateBookingHandler(c *gin.Context) {
 
 	newBooking, err := CreateNewBooking(&req, userID)
 	if err != nil {
+		if errors.Is(err, ErrSlotUnavailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -124,11 +144,15 @@ func CreateBookingHandler(c *gin.Context) {
 
 
 			downloadLink := fmt.Sprintf(
-				"%s/bookings/%d/ticket?download=true",
+				"%s/bookings/%d/ticket?download=true&token=%s",
 				baseURL,
 				newBooking.ID,
+				LinkToken(newBooking.ID),
 			)
 
+			// Served by the API itself so calendar apps can open it directly
+			calendarLink := apiURL(c, fmt.Sprintf("/bookings/%d/calendar.ics?token=%s", newBooking.ID, LinkToken(newBooking.ID)))
+
 			// ================================
 			// 5. EMAIL CONTENT
 			// ================================
@@ -144,7 +168,7 @@ func CreateBookingHandler(c *gin.Context) {
 <p><strong>Date:</strong> %s</p>
 <p><strong>Time:</strong> %s - %s</p>
 
-<p><strong>Total Price:</strong> ₹%.2f</p>
+<p><strong>Total Price:</strong> %s</p>
 
 <br>
 
@@ -153,9 +177,15 @@ text-decoration:none;border-radius:5px;font-weight:bold;">
 Download Ticket
 </a>
 
+<a href="%s" style="margin-left:10px;color:#008CBA;font-weight:bold;">
+Add to Calendar
+</a>
+
 <br><br>
 <p>If the button doesn't work, click here:</p>
 <p><a href="%s">%s</a></p>
+<p>To add the booking to your calendar:</p>
+<p><a href="%s">%s</a></p>
 
 <br>
 <p>Thank you for choosing <b>SportGrid</b>!</p>
@@ -165,10 +195,13 @@ Download Ticket
 				dateStr,
 				startTimeStr,
 				endTimeStr,
-				newBooking.TotalPrice,
+				newBooking.TotalPrice.Format(),
 				downloadLink,
+				calendarLink,
 				downloadLink,
 				downloadLink,
+				calendarLink,
+				calendarLink,
 			)
 
 			// ================================
@@ -183,61 +216,20 @@ Download Ticket
 	}
 
 	// ---------------- RESPONSE ----------------
+	newBooking.LinkToken = LinkToken(newBooking.ID)
 	c.JSON(http.StatusCreated, newBooking)
 }
 
-// VerifyTicketHandler checks if a scanned QR code is valid
-func VerifyTicketHandler(c *gin.Context) {
-	var req struct {
-		QRCodeString string `json:"qr_code" binding:"required"`
-	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "message": "Invalid Request"})
-		return
-	}
-
-	// 1. Split the content "BookingID|Signature"
-	parts := strings.Split(req.QRCodeString, "|")
-	if len(parts) != 2 {
-		c.JSON(http.StatusOK, gin.H{"valid": false, "message": "Fake Ticket: Invalid Format"})
-		return
-	}
-
-	bookingIDStr := parts[0]
-	providedSignature := parts[1]
-
-	// 2. Re-create the signature using OUR secret
-	secretKey := os.Getenv("TICKET_SECRET_KEY")
-	if secretKey == "" {
-		secretKey = "default_unsafe_secret"
-	}
-
-	h := hmac.New(sha256.New, []byte(secretKey))
-	h.Write([]byte(bookingIDStr))
-	expectedSignature := hex.EncodeToString(h.Sum(nil))
-
-	// 3. Compare signatures
-	// We use subtle.ConstantTimeCompare to prevent timing attacks
-	if subtle.ConstantTimeCompare([]byte(providedSignature), []byte(expectedSignature)) != 1 {
-		c.JSON(http.StatusOK, gin.H{"valid": false, "message": "Fake Ticket: Signature Mismatch"})
-		return
-	}
-
-	// 4. (Optional) Check if booking exists in DB and is for today
-	// ... Database logic here ...
-
-	c.JSON(http.StatusOK, gin.H{
-		"valid":      true,
-		"booking_id": bookingIDStr,
-		"message":    "Ticket Verified Successfully ✅",
-	})
-}
-
 // DownloadTicketHandler generates and serves the PDF
 func DownloadTicketHandler(c *gin.Context) {
 	bookingID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
 
+	// 🔒 The link carries a token signed for this booking (see LinkToken)
+	if !ValidLinkToken(bookingID, c.Query("token")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+
 	// 1. Fetch Booking
 	booking, err := FindBookingByID(bookingID)
 	if err != nil {
@@ -245,6 +237,12 @@ func DownloadTicketHandler(c *gin.Context) {
 		return
 	}
 
+	// Only a paid booking gets a ticket (not a hold, a canceled booking or a blocked slot)
+	if booking.Status != StatusConfirmed || booking.BookingType == "block" {
+		c.JSON(http.StatusConflict, gin.H{"error": "A ticket is only available for a confirmed booking"})
+		return
+	}
+
 	// 2. Fetch User and Venue Details
 	venueData, _ := venue.GetVenueByID(booking.VenueID)
 	userData, _ := user.GetUserByID(booking.UserID)
@@ -257,6 +255,7 @@ func DownloadTicketHandler(c *gin.Context) {
 		venueData.Name,     // venueName
 		venueData.Address,  // venueAddress
 		booking.ID,         // bookingID
+		booking.VenueID,    // venueID (carried in the signed ticket)
 		userData.ID,        // userID
 		booking.StartTime,  // startTime
 		booking.EndTime,    // endTime
@@ -293,28 +292,19 @@ func GetUserBookingsHandler(c *gin.Context) {
 		bookings = make([]Booking, 0)
 	}
 
-	// ----------------------------------------------------
-	// NEW: Generate Signed QR Codes for each booking
-	// ----------------------------------------------------
-	secretKey := os.Getenv("TICKET_SECRET_KEY")
-	if secretKey == "" {
-		secretKey = "default_unsafe_secret"
-	}
-
-	// Loop through each booking and sign it
+	// Signed QR tickets, only for bookings that can still be checked in
 	for i := range bookings {
-		// 1. Create Data
-		data := fmt.Sprintf("%d", bookings[i].ID)
-
-		// 2. Create Signature
-		h := hmac.New(sha256.New, []byte(secretKey))
-		h.Write([]byte(data))
-		signature := hex.EncodeToString(h.Sum(nil))
-
-		// 3. Attach to the struct
-		bookings[i].QRCode = fmt.Sprintf("%s|%s", data, signature)
+		bookings[i].LinkToken = LinkToken(bookings[i].ID)
+		if bookings[i].Status != StatusConfirmed {
+			continue
+		}
+		code, err := ticket.Issue(bookings[i].ID, bookings[i].VenueID, bookings[i].StartTime, bookings[i].EndTime)
+		if err != nil {
+			log.Println("Error issuing ticket:", err)
+			continue
+		}
+		bookings[i].QRCode = code
 	}
-	// ----------------------------------------------------
 
 	c.JSON(http.StatusOK, bookings)
 }
@@ -329,9 +319,22 @@ func CancelBookingHandler(c *gin.Context) {
 
 	userID := c.MustGet("userID").(int64)
 
+	// Optional body: {"refund_to": "wallet"} for an instant wallet refund
+	var body struct {
+		RefundTo string `json:"refund_to"`
+	}
+	_ = c.ShouldBindJSON(&body)
+	if body.RefundTo == "" {
+		body.RefundTo = RefundToSource
+	}
+	if body.RefundTo != RefundToSource && body.RefundTo != RefundToWallet {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refund_to must be 'source' or 'wallet'"})
+		return
+	}
+
 	// --- THIS IS THE FIX ---
 	// Call CancelBooking, not CancelUserBooking
-	err = CancelBooking(bookingID, userID)
+	err = CancelBooking(bookingID, userID, body.RefundTo)
 	// ---------------------
 	if err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
@@ -428,6 +431,10 @@ func BlockSlotHandler(c *gin.Context) {
 	err := BlockVenueSlot(&req, userID)
 
 	if err != nil {
+		if errors.Is(err, ErrSlotUnavailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -457,6 +464,12 @@ func ProcessPaymentHandler(c *gin.Context) {
 		return
 	}
 
+	// Only registered with the fake gateway, and still only for the player's own booking
+	if b, err := FindBookingByID(id); err != nil || b.UserID != c.MustGet("userID").(int64) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+
 	// FIX: Pass a placeholder string as the second argument
 	// Real Razorpay payments use the VerifyPaymentHandler in the payment package.
 	err = ProcessPayment(id, "simulated_payment_id")
@@ -482,8 +495,18 @@ func GetBookedSlotsHandler(c *gin.Context) {
 		return
 	}
 
+	// Optional: only the slots that block one court (its own bookings + whole-venue blocks)
+	var courtID int64
+	if courtStr := c.Query("court_id"); courtStr != "" {
+		courtID, err = strconv.ParseInt(courtStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+			return
+		}
+	}
+
 	// We skip the service layer for this simple read-only query to keep it quick
-	slots, err := GetBookedSlotsForDate(venueID, dateStr)
+	slots, err := GetBookedSlotsForDate(venueID, courtID, dateStr)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch slots"})
 		return
@@ -583,6 +606,16 @@ func HandleRefundDecisionHandler(c *gin.Context) {
 		return
 	}
 
+	// Only the venue's owner (or an admin) decides on refunds
+	userRole := c.MustGet("userRole").(string)
+	if userRole != "admin" {
+		isOwner, err := venue.IsVenueOwner(b.VenueID, c.MustGet("userID").(int64))
+		if err != nil || !isOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only decide refunds for your own venues"})
+			return
+		}
+	}
+
 	// 2. Validate state
 	if b.Status != "refund_requested" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "This booking is not pending a refund request"})
@@ -592,25 +625,37 @@ func HandleRefundDecisionHandler(c *gin.Context) {
 	userID := b.UserID
 	var newStatus string
 	var msg string
+	var pending bool // A Razorpay refund leaves the booking 'refund_initiated' until the bank processes it
 
 	if req.Decision == "approve" {
-		// --- CALL RAZORPAY ---
-		if b.PaymentID == "" {
+		// --- CALL RAZORPAY (or credit the wallet) ---
+		if b.PaymentID == "" && b.WalletAmount == 0 && b.RefundMethod != RefundToWallet {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing payment ID"})
 			return
 		}
-		// FIX: 'gateway' is now imported correctly
-		err := gateway.InitiateRefund(b.PaymentID, b.TotalPrice)
+		// A tiered cancellation whose automatic refund failed keeps its partial amount
+		amount := b.TotalPrice
+		if b.RefundAmount > 0 {
+			amount = b.RefundAmount
+		} else if err := RecordRefundAmount(b.ID, amount); err != nil {
+			// Settlement and the credit note reverse the recorded amount
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record the refund"})
+			return
+		}
+		pending, err = refundBooking(b, amount, b.RefundMethod, "refund request approved by the venue")
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Razorpay Refund Failed: " + err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Refund Failed: " + err.Error()})
 			return
 		}
-		newStatus = "refunded"
-		msg = "Your refund request has been APPROVED. Money sent to source."
+		newStatus = StatusRefunded
+		msg = "Your refund request has been APPROVED. The refund to your original payment method has been started; we'll let you know once it's processed."
+		if b.RefundMethod == RefundToWallet {
+			msg = "Your refund request has been APPROVED. The money has been added to your wallet."
+		}
 
 	} else if req.Decision == "reject" {
 		// --- NO REFUND ---
-		newStatus = "refund_rejected"
+		newStatus = StatusRefundRejected
 		msg = "Your refund request has been REJECTED by the venue owner."
 
 	} else {
@@ -620,7 +665,13 @@ func HandleRefundDecisionHandler(c *gin.Context) {
 
 	// 3. Update Status
 	// FIX: Removed 'booking.' prefix
-	err = UpdateBookingStatusDirect(bookingID, newStatus)
+	actor := actorFor(c.MustGet("userID").(int64), userRole)
+	reason := "refund decision by venue: " + req.Decision
+	if newStatus == StatusRefunded {
+		newStatus, err = finishRefund(bookingID, pending, actor, reason)
+	} else {
+		err = TransitionBooking(bookingID, newStatus, actor, reason)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status"})
 		return
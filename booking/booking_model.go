@@ -1,7 +1,26 @@
 // booking/booking_model.go
 package booking
 
-import "time"
+import (
+	"errors"
+	"time"
+
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// HoldDuration is how long a pending booking keeps its slot while the player pays
+const HoldDuration = 10 * time.Minute
+
+// Where a cancellation refund goes
+const (
+	RefundToSource = "source" // Back through Razorpay (days), wallet part back to the wallet
+	RefundToWallet = "wallet" // Everything to the wallet, instantly
+)
+
+// ErrSlotUnavailable is returned when the requested time overlaps another booking or an active hold
+var ErrSlotUnavailable = errors.New("this time slot is no longer available")
 
 type Booking struct {
 	ID            int64     `json:"id"`
@@ -11,21 +30,54 @@ type Booking struct {
 	VenueID       int64     `json:"venue_id"`
 	VenueName     string    `json:"venue_name"`     // Added for joins
 	VenueAddress  string    `json:"venue_address"`  // Added for joins
+	CourtID       int64     `json:"court_id,omitempty"`   // 0 = the whole venue
+	CourtName     string    `json:"court_name,omitempty"` // Added for joins
 	SportCategory string    `json:"sport_category"` // Added for joins
 	StartTime     time.Time `json:"start_time"`
 	EndTime       time.Time `json:"end_time"`
-	TotalPrice    float64   `json:"total_price"`
+	TotalPrice    money.Money   `json:"total_price"`
 	Status        string    `json:"status"`
 	PaymentID     string    `json:"razorpay_payment_id"` // <--- ADDED THIS FIELD
 	CreatedAt     time.Time `json:"created_at"`
 	QRCode        string    `json:"qr_code" gorm:"-"` // <--- ADD THIS
+	// LinkToken opens the ticket and calendar downloads without logging in (?token=...)
+	LinkToken     string    `json:"link_token,omitempty"`
+	// HoldExpiresAt is set while the booking is 'pending'; after it passes the slot is released
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty"`
+	SeriesID      int64      `json:"series_id,omitempty"` // Set when the booking is one occurrence of a recurring series
+	BookingType   string     `json:"booking_type,omitempty"` // 'booking' or 'block' (owner closed the slot)
+	RescheduleCount int      `json:"reschedule_count"`
+	// RescheduleOf is set on the temporary hold for a paid move; the original booking keeps its ID
+	RescheduleOf  int64      `json:"reschedule_of,omitempty"`
+	// DiscountAmount was taken off the quote by CouponCode; TotalPrice is what the player pays
+	DiscountAmount money.Money   `json:"discount_amount,omitempty"`
+	CouponCode     string    `json:"coupon_code,omitempty"`
+	// WalletAmount is the part of TotalPrice paid from the player's wallet; Razorpay charges the rest
+	WalletAmount   money.Money   `json:"wallet_amount,omitempty"`
+	RefundMethod   string    `json:"refund_method,omitempty"` // 'source' or 'wallet'
+	// PackPurchaseID is set when the booking was paid from a prepaid pack/pass (TotalPrice is then 0)
+	PackPurchaseID int64     `json:"pack_purchase_id,omitempty"`
+	// CalendarSourceID is set on blocks synced from an external calendar; ExternalUID is the event's UID there
+	CalendarSourceID int64   `json:"calendar_source_id,omitempty"`
+	ExternalUID      string  `json:"-"`
+	// Set when the ticket was scanned at the venue (status 'present')
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInBy   int64      `json:"checked_in_by,omitempty"`
+	CheckedInDevice string   `json:"checked_in_device,omitempty"` // Set for check-ins synced from an offline scanner
+	// RefundAmount is what a cancellation refunds (or will refund once the owner approves)
+	RefundAmount  money.Money    `json:"refund_amount,omitempty"`
+	// PriceBreakdown is the quote the price was taken from (peak/off-peak line items)
+	PriceBreakdown []venue.PriceLineItem `json:"price_breakdown,omitempty"`
 }
 
 // Add a struct for the request body, as users won't send everything
 type CreateBookingRequest struct {
 	VenueID   int64     `json:"venue_id" binding:"required"`
+	CourtID   int64     `json:"court_id"` // Required when the venue has courts; for blocks, 0 blocks every court
 	StartTime time.Time `json:"start_time" binding:"required"`
 	EndTime   time.Time `json:"end_time" binding:"required"`
+	CouponCode string   `json:"coupon_code"` // Optional promo code
+	SkipPack   bool     `json:"skip_pack"`   // Pay normally even if a prepaid pack covers the slot
 	// Price will be calculated on the backend
 }
 
@@ -35,14 +87,20 @@ type AdminBookingView struct {
 	VenueID       int64     `json:"venue_id"`
 	VenueName     string    `json:"venue_name"`
 	SportCategory string    `json:"sport_category"`
+	CourtID       int64     `json:"court_id,omitempty"`
+	CourtName     string    `json:"court_name,omitempty"`
 	UserID        int64     `json:"user_id"`
 	UserFirstName string    `json:"user_first_name"` // <-- ADD THIS
 	UserLastName  string    `json:"user_last_name"`  // <-- ADD THIS
 	StartTime     time.Time `json:"start_time"`
 	EndTime       time.Time `json:"end_time"`
-	TotalPrice    float64   `json:"total_price"`
+	TotalPrice    money.Money   `json:"total_price"`
 	Status        string    `json:"status"`
 	UserPhone     string    `json:"user_phone"`
+	BookingType   string    `json:"booking_type,omitempty"`
+	// Synced blocks carry the name of the external calendar they came from
+	CalendarSourceID   int64  `json:"calendar_source_id,omitempty"`
+	CalendarSourceName string `json:"calendar_source_name,omitempty"`
 }
 
 // OwnerStats defines the data for the owner's dashboard
@@ -52,7 +110,7 @@ type OwnerStats struct {
 	PresentBookings   int64   `json:"present_bookings"`   // New
 	CanceledBookings  int64   `json:"canceled_bookings"`  // New
 	RefundedBookings  int64   `json:"refunded_bookings"`  // New
-	TotalRevenue      float64 `json:"total_revenue"`
+	TotalRevenue      money.Money `json:"total_revenue"`
 	PopularTime       string  `json:"popular_time"`
 }
 
@@ -62,10 +120,12 @@ type VenueStats struct {
 	VenueName     string  `json:"venue_name"`
 	SportCategory string  `json:"sport_category"`
 	TotalBookings int64   `json:"total_bookings"`
-	TotalRevenue  float64 `json:"total_revenue"`
+	TotalRevenue  money.Money `json:"total_revenue"`
 } // booking/booking_model.go
 
 type BookedSlot struct {
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
+	CourtID   int64     `json:"court_id"` // 0 = the whole venue is taken
+	Type      string    `json:"type"`     // 'booking' or 'block'
 }
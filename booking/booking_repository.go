@@ -3,26 +3,91 @@ package booking
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
 )
 
+// activeSlotFilter matches the rows that occupy a slot: paid bookings plus pending ones whose hold is still running.
+// It expects one time.Time argument (now) to compare the hold against.
+const activeSlotFilter = `(status IN ('confirmed', 'present') OR (status = 'pending' AND hold_expires_at > ?))`
+
+// courtScope narrows an overlap query to the bookings that compete with courtID.
+// A whole-venue booking (court 0) competes with every court; a court booking competes
+// with the same court and with whole-venue bookings/blocks.
+func courtScope(courtID int64) (string, []interface{}) {
+	if courtID == 0 {
+		return "", nil
+	}
+	return " AND (court_id = ? OR court_id IS NULL)", []interface{}{courtID}
+}
+
 // CreateBooking inserts a new booking into the database
 func CreateBooking(booking *Booking) error {
+	return insertBooking(db.DB, booking)
+}
+
+// sqlExecer lets the insert run on either the pool or an open transaction
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertBooking(ex sqlExecer, booking *Booking) error {
 	query := `
-		INSERT INTO bookings (user_id, venue_id, start_time, end_time, total_price, status)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO bookings (user_id, venue_id, court_id, start_time, end_time, total_price, status, hold_expires_at, series_id, price_breakdown, booking_type, reschedule_of,
+		                      discount_amount, coupon_code, pack_purchase_id, calendar_source_id, external_uid)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, NULLIF(?, ''))
 	`
-	result, err := db.DB.Exec(query,
+	// court_id is NULL when the whole venue is booked, series_id is NULL for one-off bookings
+	var courtID, seriesID, rescheduleOf, packPurchaseID, calendarSourceID sql.NullInt64
+	if booking.CourtID != 0 {
+		courtID = sql.NullInt64{Int64: booking.CourtID, Valid: true}
+	}
+	if booking.SeriesID != 0 {
+		seriesID = sql.NullInt64{Int64: booking.SeriesID, Valid: true}
+	}
+	if booking.RescheduleOf != 0 {
+		rescheduleOf = sql.NullInt64{Int64: booking.RescheduleOf, Valid: true}
+	}
+	if booking.PackPurchaseID != 0 {
+		packPurchaseID = sql.NullInt64{Int64: booking.PackPurchaseID, Valid: true}
+	}
+	if booking.CalendarSourceID != 0 {
+		calendarSourceID = sql.NullInt64{Int64: booking.CalendarSourceID, Valid: true}
+	}
+	breakdown, err := encodeBreakdown(booking.PriceBreakdown)
+	if err != nil {
+		return err
+	}
+	if booking.BookingType == "" {
+		booking.BookingType = "booking"
+	}
+
+	result, err := ex.Exec(query,
 		booking.UserID,
 		booking.VenueID,
+		courtID,
 		booking.StartTime,
 		booking.EndTime,
 		booking.TotalPrice,
 		booking.Status,
+		booking.HoldExpiresAt,
+		seriesID,
+		breakdown,
+		booking.BookingType,
+		rescheduleOf,
+		booking.DiscountAmount,
+		booking.CouponCode,
+		packPurchaseID,
+		calendarSourceID,
+		booking.ExternalUID,
 	)
 	if err != nil {
 		log.Println("Error inserting booking:", err)
@@ -31,23 +96,133 @@ func CreateBooking(booking *Booking) error {
 
 	id, _ := result.LastInsertId()
 	booking.ID = id
+
+	// First timeline entry
+	actor, reason := Actor{UserID: booking.UserID, Role: "player"}, "booking created"
+	switch {
+	case booking.CalendarSourceID != 0:
+		actor, reason = SystemActor, fmt.Sprintf("synced from external calendar #%d", booking.CalendarSourceID)
+	case booking.BookingType == "block":
+		actor.Role, reason = "owner", "slot blocked by venue"
+	case booking.RescheduleOf != 0:
+		reason = fmt.Sprintf("hold for rescheduling booking #%d", booking.RescheduleOf)
+	case booking.SeriesID != 0:
+		reason = fmt.Sprintf("occurrence of series #%d", booking.SeriesID)
+	case booking.PackPurchaseID != 0:
+		reason = fmt.Sprintf("paid from pack purchase #%d", booking.PackPurchaseID)
+	}
+	return insertStatusChange(ex, booking.ID, "", booking.Status, actor, reason)
+}
+
+// encodeBreakdown keeps the line items as JSON so later rule changes don't rewrite old prices
+func encodeBreakdown(items []venue.PriceLineItem) (sql.NullString, error) {
+	if len(items) == 0 {
+		return sql.NullString{}, nil
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(raw), Valid: true}, nil
+}
+
+// ReserveSlot atomically checks the slot and inserts the booking.
+// The venue row is locked for the duration of the transaction so two requests
+// for the same venue are serialized; the loser gets ErrSlotUnavailable.
+func ReserveSlot(booking *Booking) error {
+	return reserveSlotWith(booking, nil)
+}
+
+// reserveSlotWith is ReserveSlot with an extra step (afterInsert) run in the same transaction,
+// so whatever pays for the booking is taken together with the slot or not at all.
+func reserveSlotWith(booking *Booking, afterInsert func(tx *sql.Tx) error) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting reservation transaction:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// 1. Lock the venue row (serializes all reservations for this venue)
+	if err := lockVenueForBooking(tx, booking.VenueID); err != nil {
+		return err
+	}
+
+	// 2. Overlap check against confirmed bookings and live holds
+	count, err := countOverlapping(tx, booking.VenueID, booking.CourtID, booking.StartTime, booking.EndTime)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSlotUnavailable
+	}
+
+	// 3. Insert
+	if err := insertBooking(tx, booking); err != nil {
+		return err
+	}
+	if afterInsert != nil {
+		if err := afterInsert(tx); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// lockVenueForBooking takes a row lock on the venue until the transaction ends
+func lockVenueForBooking(tx *sql.Tx, venueID int64) error {
+	var id int64
+	err := tx.QueryRow(`SELECT id FROM venues WHERE id = ? FOR UPDATE`, venueID).Scan(&id)
+	if err != nil {
+		log.Println("Error locking venue for reservation:", err)
+		return err
+	}
 	return nil
 }
 
-// IsSlotAvailable checks for overlapping bookings
-func IsSlotAvailable(venueID int64, startTime, endTime time.Time) (bool, error) {
+// countOverlapping counts bookings/holds that overlap [startTime, endTime) inside a transaction
+func countOverlapping(tx *sql.Tx, venueID int64, courtID int64, startTime, endTime time.Time) (int, error) {
+	return countOverlappingExcept(tx, venueID, courtID, startTime, endTime, 0)
+}
+
+// countOverlappingExcept is countOverlapping ignoring one booking (the one being moved) and its holds
+func countOverlappingExcept(tx *sql.Tx, venueID int64, courtID int64, startTime, endTime time.Time, excludeID int64) (int, error) {
 	var count int
-	// FIX: Only block slots that are 'confirmed' or 'present'.
-	// 'canceled' AND 'absent' slots should be ignored (available).
+	scope, scopeArgs := courtScope(courtID)
 	query := `
 		SELECT COUNT(*) FROM bookings
 		WHERE venue_id = ?
-		AND status IN ('confirmed', 'present') 
+		AND ` + activeSlotFilter + `
 		AND start_time < ?
 		AND end_time > ?
-	`
+		AND id <> ?
+		AND (reschedule_of IS NULL OR reschedule_of <> ?)` + scope
+
+	args := append([]interface{}{venueID, time.Now(), endTime, startTime, excludeID, excludeID}, scopeArgs...)
+	err := tx.QueryRow(query, args...).Scan(&count)
+	if err != nil {
+		log.Println("Error checking slot overlap:", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// IsSlotAvailable checks for overlapping bookings (courtID 0 = the whole venue)
+func IsSlotAvailable(venueID int64, courtID int64, startTime, endTime time.Time) (bool, error) {
+	var count int
+	// Paid bookings and pending bookings that still hold the slot both block it.
+	// 'canceled' AND 'absent' slots should be ignored (available).
+	scope, scopeArgs := courtScope(courtID)
+	query := `
+		SELECT COUNT(*) FROM bookings
+		WHERE venue_id = ?
+		AND ` + activeSlotFilter + `
+		AND start_time < ?
+		AND end_time > ?` + scope
 
-	err := db.DB.QueryRow(query, venueID, endTime, startTime).Scan(&count)
+	args := append([]interface{}{venueID, time.Now(), endTime, startTime}, scopeArgs...)
+	err := db.DB.QueryRow(query, args...).Scan(&count)
 	if err != nil {
 		log.Println("Error checking slot availability:", err)
 		return false, err
@@ -61,11 +236,13 @@ func FindBookingsByUserID(userID int64) ([]Booking, error) {
 		SELECT 
 			b.id, b.user_id, u.first_name, u.last_name,
 			b.venue_id, v.name, v.address, v.sport_category, 
-			b.start_time, b.end_time, b.total_price, b.status, b.created_at
+			b.start_time, b.end_time, b.total_price, b.status, b.created_at,
+			COALESCE(b.series_id, 0), COALESCE(b.court_id, 0), COALESCE(c.name, '')
 		FROM bookings b
 		JOIN venues v ON b.venue_id = v.id
 		JOIN users u ON b.user_id = u.id
-		WHERE b.user_id = ?
+		LEFT JOIN courts c ON b.court_id = c.id
+		WHERE b.user_id = ? AND b.reschedule_of IS NULL
 		ORDER BY b.start_time DESC
 	`
 
@@ -94,6 +271,9 @@ func FindBookingsByUserID(userID int64) ([]Booking, error) {
 			&booking.TotalPrice,
 			&booking.Status,
 			&booking.CreatedAt,
+			&booking.SeriesID,
+			&booking.CourtID,
+			&booking.CourtName,
 		); err != nil {
 			log.Println("Error scanning booking row:", err)
 			continue
@@ -104,27 +284,81 @@ func FindBookingsByUserID(userID int64) ([]Booking, error) {
 	return bookings, nil
 }
 
-// UpdateBookingStatus updates a booking's status
-func UpdateBookingStatus(bookingID int64, userID int64, newStatus string) error {
-	query := `
-		UPDATE bookings 
-		SET status = ? 
-		WHERE id = ? AND user_id = ?
-	`
+// UpdateBookingStatus changes the status of the player's own booking
+func UpdateBookingStatus(bookingID int64, userID int64, newStatus string, reason string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	result, err := db.DB.Exec(query, newStatus, bookingID, userID)
+	var count int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM bookings WHERE id = ? AND user_id = ?`, bookingID, userID).Scan(&count)
 	if err != nil {
 		log.Println("Error updating booking status:", err)
 		return err
 	}
+	if count == 0 {
+		return errors.New("booking not found or you do not have permission")
+	}
+
+	if _, err := transitionTx(tx, bookingID, "", newStatus, Actor{UserID: userID, Role: "player"}, reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	rowsAffected, err := result.RowsAffected()
+// SetBookingRefund closes a paid booking that is being canceled, recording the refund it gets.
+// Only a still-confirmed booking is touched, so a double cancel can't refund twice.
+func SetBookingRefund(bookingID int64, newStatus string, refundAmount money.Money, refundMethod string, actor Actor, reason string) error {
+	tx, err := db.DB.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
+	if _, err := transitionTx(tx, bookingID, StatusConfirmed, newStatus, actor, reason); err != nil {
+		if errors.Is(err, ErrIllegalTransition) {
+			return errors.New("booking is no longer confirmed")
+		}
+		return err
+	}
+	if refundMethod != RefundToWallet {
+		refundMethod = RefundToSource
+	}
+	query := `UPDATE bookings SET refund_amount = ?, refund_method = ? WHERE id = ?`
+	if _, err := tx.Exec(query, refundAmount, refundMethod, bookingID); err != nil {
+		log.Println("Error recording booking refund:", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecordRefundAmount stores what is being refunded on a booking that didn't record it when it was canceled
+func RecordRefundAmount(bookingID int64, amount money.Money) error {
+	_, err := db.DB.Exec(`UPDATE bookings SET refund_amount = ? WHERE id = ? AND COALESCE(refund_amount, 0) = 0`, amount, bookingID)
+	if err != nil {
+		log.Println("Error recording booking refund:", err)
+	}
+	return err
+}
+
+// SetBookingDiscount applies a coupon to a booking that hasn't been paid yet
+func SetBookingDiscount(bookingID int64, totalPrice, discount money.Money, code string) error {
+	query := `
+		UPDATE bookings
+		SET total_price = ?, discount_amount = ?, coupon_code = ?
+		WHERE id = ? AND status = 'pending' AND coupon_code IS NULL
+	`
+	result, err := db.DB.Exec(query, totalPrice, discount, code, bookingID)
+	if err != nil {
+		log.Println("Error applying booking discount:", err)
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return errors.New("booking not found or you do not have permission")
+		return errors.New("a coupon can only be added once to an unpaid booking")
 	}
 	return nil
 }
@@ -135,11 +369,15 @@ func FindBookingsByVenueID(venueID int64) ([]AdminBookingView, error) {
 		SELECT 
 			b.id, b.venue_id, v.name, v.sport_category, b.user_id, 
 			u.first_name, u.last_name, COALESCE(u.phone, 'N/A'),
-			b.start_time, b.end_time, b.total_price, b.status
+			b.start_time, b.end_time, b.total_price, b.status,
+			COALESCE(b.court_id, 0), COALESCE(c.name, ''),
+			b.booking_type, COALESCE(b.calendar_source_id, 0), COALESCE(cs.name, '')
 		FROM bookings b
 		JOIN venues v ON b.venue_id = v.id
 		JOIN users u ON b.user_id = u.id 
-		WHERE b.venue_id = ?
+		LEFT JOIN courts c ON b.court_id = c.id
+		LEFT JOIN calendar_sources cs ON b.calendar_source_id = cs.id
+		WHERE b.venue_id = ? AND b.reschedule_of IS NULL
 		ORDER BY b.start_time DESC
 	`
 	rows, err := db.DB.Query(query, venueID)
@@ -165,6 +403,11 @@ func FindBookingsByVenueID(venueID int64) ([]AdminBookingView, error) {
 			&b.EndTime,
 			&b.TotalPrice,
 			&b.Status,
+			&b.CourtID,
+			&b.CourtName,
+			&b.BookingType,
+			&b.CalendarSourceID,
+			&b.CalendarSourceName,
 		); err != nil {
 			log.Println("Error scanning booking row:", err)
 			continue
@@ -293,7 +536,7 @@ func GetOwnerPopularTime(ownerID int64, venueID int64) (string, error) {
 }
 
 // GetPlatformBookingStats calculates total bookings and revenue for the whole platform
-func GetPlatformBookingStats() (int64, float64, error) {
+func GetPlatformBookingStats() (int64, money.Money, error) {
 	query := `
 		SELECT 
 			COUNT(id), 
@@ -303,7 +546,7 @@ func GetPlatformBookingStats() (int64, float64, error) {
 	`
 
 	var totalBookings int64
-	var totalRevenue float64
+	var totalRevenue money.Money
 
 	err := db.DB.QueryRow(query).Scan(&totalBookings, &totalRevenue)
 	if err != nil {
@@ -440,15 +683,66 @@ func GetOwnerVenueStatsGrouped(ownerID int64) ([]VenueStats, error) {
 	return statsList, nil
 }
 
-// ConfirmBookingPayment updates status to 'confirmed' after payment
+// ConfirmBookingPayment updates status to 'confirmed' after payment.
+// The browser and the payment webhook both report a payment; the second one gets ErrPaymentAlreadyApplied.
+// A payment arriving after the hold ran out still confirms the booking if nobody took the slot meanwhile;
+// otherwise the booking is canceled and ErrHoldLost returned so the payment gets refunded.
 func ConfirmBookingPayment(bookingID int64, paymentID string) error {
-	query := `UPDATE bookings SET status = 'confirmed', razorpay_payment_id = ? WHERE id = ?`
-	_, err := db.DB.Exec(query, paymentID, bookingID)
+	var venueID int64
+	if err := db.DB.QueryRow(`SELECT venue_id FROM bookings WHERE id = ?`, bookingID).Scan(&venueID); err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Same lock as a new reservation, so nobody books the slot while it is checked
+	if err := lockVenueForBooking(tx, venueID); err != nil {
+		return err
+	}
+
+	var status, recorded string
+	var courtID int64
+	var startTime, endTime time.Time
+	var holdExpiresAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT status, COALESCE(razorpay_payment_id, ''), COALESCE(court_id, 0), start_time, end_time, hold_expires_at
+		FROM bookings WHERE id = ? FOR UPDATE
+	`, bookingID).Scan(&status, &recorded, &courtID, &startTime, &endTime, &holdExpiresAt)
 	if err != nil {
+		return err
+	}
+	if status != StatusPending && recorded == paymentID {
+		return ErrPaymentAlreadyApplied
+	}
+
+	if status == StatusPending && holdExpiresAt.Valid && !holdExpiresAt.Time.After(time.Now()) {
+		count, err := countOverlappingExcept(tx, venueID, courtID, startTime, endTime, bookingID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			if _, err := transitionTx(tx, bookingID, StatusPending, StatusCanceled, SystemActor, "hold expired and the slot was taken before payment "+paymentID+" arrived"); err != nil {
+				return err
+			}
+			if err := tx.Commit(); err != nil {
+				return err
+			}
+			return ErrHoldLost
+		}
+	}
+
+	if _, err := transitionTx(tx, bookingID, "", StatusConfirmed, SystemActor, "payment "+paymentID+" verified"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE bookings SET razorpay_payment_id = ? WHERE id = ?`, paymentID, bookingID); err != nil {
 		log.Println("Error confirming payment:", err)
 		return err
 	}
-	return nil
+	return tx.Commit()
 }
 
 // FindBookingByID fetches a single booking by its ID
@@ -457,34 +751,82 @@ func ConfirmBookingPayment(bookingID int64, paymentID string) error {
 func FindBookingByID(bookingID int64) (*Booking, error) {
 	// Added razorpay_payment_id to the query
 	query := `
-		SELECT id, user_id, venue_id, start_time, end_time, total_price, status, created_at, COALESCE(razorpay_payment_id, '')
+		SELECT id, user_id, venue_id, start_time, end_time, total_price, status, created_at, COALESCE(razorpay_payment_id, ''), hold_expires_at,
+		       COALESCE(series_id, 0), COALESCE(court_id, 0), price_breakdown, booking_type,
+		       reschedule_count, COALESCE(reschedule_of, 0), COALESCE(refund_amount, 0),
+		       discount_amount, COALESCE(coupon_code, ''), wallet_amount, refund_method, COALESCE(pack_purchase_id, 0),
+		       COALESCE(calendar_source_id, 0), checked_in_at, COALESCE(checked_in_by, 0),
+		       COALESCE(checked_in_device, '')
 		FROM bookings
 		WHERE id = ?
 	`
 	var b Booking
+	var holdExpiresAt, checkedInAt sql.NullTime
+	var breakdown sql.NullString
 	// We scan directly into the struct field now
 	err := db.DB.QueryRow(query, bookingID).Scan(
 		&b.ID, &b.UserID, &b.VenueID, &b.StartTime, &b.EndTime,
 		&b.TotalPrice, &b.Status, &b.CreatedAt, &b.PaymentID, // <--- Fixed Scan
+		&holdExpiresAt, &b.SeriesID, &b.CourtID, &breakdown, &b.BookingType,
+		&b.RescheduleCount, &b.RescheduleOf, &b.RefundAmount,
+		&b.DiscountAmount, &b.CouponCode, &b.WalletAmount, &b.RefundMethod, &b.PackPurchaseID,
+		&b.CalendarSourceID, &checkedInAt, &b.CheckedInBy,
+		&b.CheckedInDevice,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if holdExpiresAt.Valid {
+		b.HoldExpiresAt = &holdExpiresAt.Time
+	}
+	if checkedInAt.Valid {
+		b.CheckedInAt = &checkedInAt.Time
+	}
+	if breakdown.Valid {
+		if err := json.Unmarshal([]byte(breakdown.String), &b.PriceBreakdown); err != nil {
+			log.Println("Error decoding price breakdown:", err)
+		}
+	}
 	return &b, nil
 }
 
 
-// GetBookedSlotsForDate fetches confirmed OR present bookings
-func GetBookedSlotsForDate(venueID int64, dateStr string) ([]BookedSlot, error) {
+// GetBookedSlotsForDate fetches confirmed OR present bookings, plus slots held by unpaid bookings.
+// With a courtID only the slots that block that court are returned (its own + whole-venue ones).
+func GetBookedSlotsForDate(venueID int64, courtID int64, dateStr string) ([]BookedSlot, error) {
+	scope, scopeArgs := courtScope(courtID)
 	query := `
-		SELECT start_time, end_time 
+		SELECT start_time, end_time, COALESCE(court_id, 0), booking_type
 		FROM bookings 
 		WHERE venue_id = ? 
 		AND DATE(start_time) = ? 
-		AND status IN ('confirmed', 'present')
+		AND ` + activeSlotFilter + scope + `
+		ORDER BY start_time
 	`
 
-	rows, err := db.DB.Query(query, venueID, dateStr)
+	args := append([]interface{}{venueID, dateStr, time.Now()}, scopeArgs...)
+	return queryBookedSlots(query, args...)
+}
+
+// GetBookedSlotsInRange fetches the slots taken anywhere inside [from, to)
+func GetBookedSlotsInRange(venueID int64, courtID int64, from, to time.Time) ([]BookedSlot, error) {
+	scope, scopeArgs := courtScope(courtID)
+	query := `
+		SELECT start_time, end_time, COALESCE(court_id, 0), booking_type
+		FROM bookings
+		WHERE venue_id = ?
+		AND start_time < ?
+		AND end_time > ?
+		AND ` + activeSlotFilter + scope + `
+		ORDER BY start_time
+	`
+
+	args := append([]interface{}{venueID, to, from, time.Now()}, scopeArgs...)
+	return queryBookedSlots(query, args...)
+}
+
+func queryBookedSlots(query string, args ...interface{}) ([]BookedSlot, error) {
+	rows, err := db.DB.Query(query, args...)
 	if err != nil {
 		log.Println("Error querying booked slots:", err)
 		return nil, err
@@ -496,8 +838,7 @@ func GetBookedSlotsForDate(venueID int64, dateStr string) ([]BookedSlot, error)
 
 	for rows.Next() {
 		var s BookedSlot
-		// Corrected: Scan only the 2 columns we asked for into 's'
-		if err := rows.Scan(&s.StartTime, &s.EndTime); err != nil {
+		if err := rows.Scan(&s.StartTime, &s.EndTime, &s.CourtID, &s.Type); err != nil {
 			log.Println("Error scanning slot:", err)
 			continue
 		}
@@ -508,41 +849,32 @@ func GetBookedSlotsForDate(venueID int64, dateStr string) ([]BookedSlot, error)
 }
 
 // UpdateBookingStatusByOwner updates status if the requester owns the venue
-func UpdateBookingStatusByOwner(bookingID int64, ownerID int64, newStatus string) error {
+func UpdateBookingStatusByOwner(bookingID int64, ownerID int64, newStatus string, reason string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// We use a JOIN to verify the link between Booking -> Venue -> Owner
+	var count int
 	query := `
-		UPDATE bookings b
+		SELECT COUNT(*) FROM bookings b
 		JOIN venues v ON b.venue_id = v.id
-		SET b.status = ?
 		WHERE b.id = ? AND v.owner_id = ?
 	`
-
-	result, err := db.DB.Exec(query, newStatus, bookingID, ownerID)
-	if err != nil {
+	if err := tx.QueryRow(query, bookingID, ownerID).Scan(&count); err != nil {
 		log.Println("Error updating booking by owner:", err)
 		return err
 	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
+	if count == 0 {
 		return errors.New("booking not found or you do not own this venue")
 	}
-	return nil
-}
 
-// UpdateBookingStatusDirect allows admins to update status without ownership check
-func UpdateBookingStatusDirect(bookingID int64, newStatus string) error {
-	query := `UPDATE bookings SET status = ? WHERE id = ?`
-	_, err := db.DB.Exec(query, newStatus, bookingID)
-	if err != nil {
-		log.Println("Error updating booking directly:", err)
+	if _, err := transitionTx(tx, bookingID, "", newStatus, Actor{UserID: ownerID, Role: "owner"}, reason); err != nil {
 		return err
 	}
-	return nil
+	return tx.Commit()
 }
 
 // booking/booking_repository.go
@@ -736,22 +1068,86 @@ func GetGlobalStatsForPlatform() (*OwnerStats, error) {
 
 // booking/booking_repository.go
 
-// AutoCancelPendingBookings cancels bookings that have been 'pending' for too long
-func AutoCancelPendingBookings(minutes int) (int64, error) {
-	// Query: Set status to 'canceled' IF status is 'pending' AND created_at is older than X minutes
-	// We check against UTC time since that's standard for servers
+// AutoCancelPendingBookings cancels bookings whose hold has run out and returns them,
+// so the caller can hand the freed slots to the waitlist.
+// Rows created before holds existed have no hold_expires_at, so those fall back to 'pending for X minutes'.
+func AutoCancelPendingBookings(minutes int) ([]Booking, error) {
 	query := `
-		UPDATE bookings 
-		SET status = 'canceled' 
+		SELECT id, user_id, venue_id, COALESCE(court_id, 0), start_time, end_time
+		FROM bookings
 		WHERE status = 'pending' 
-		AND created_at < DATE_SUB(NOW(), INTERVAL ? MINUTE)
+		AND (
+			hold_expires_at <= ?
+			OR (hold_expires_at IS NULL AND created_at < DATE_SUB(NOW(), INTERVAL ? MINUTE))
+		)
 	`
-	
-	result, err := db.DB.Exec(query, minutes)
+	rows, err := db.DB.Query(query, time.Now(), minutes)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	
-	rows, _ := result.RowsAffected()
-	return rows, nil
+	expired := make([]Booking, 0)
+	for rows.Next() {
+		var b Booking
+		if err := rows.Scan(&b.ID, &b.UserID, &b.VenueID, &b.CourtID, &b.StartTime, &b.EndTime); err != nil {
+			log.Println("Error scanning expired hold:", err)
+			continue
+		}
+		expired = append(expired, b)
+	}
+	rows.Close()
+
+	// Cancel one by one; a payment that lands in between keeps its booking
+	canceled := make([]Booking, 0, len(expired))
+	for _, b := range expired {
+		if err := expireHold(b.ID); err != nil {
+			if !errors.Is(err, ErrIllegalTransition) {
+				log.Println("Error canceling expired hold:", err)
+			}
+			continue
+		}
+		b.Status = StatusCanceled
+		canceled = append(canceled, b)
+	}
+	return canceled, nil
+}
+
+func expireHold(bookingID int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := transitionTx(tx, bookingID, StatusPending, StatusCanceled, SystemActor, "payment hold expired"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReleaseHold cancels a pending booking so its slot is free again (e.g. the payment failed)
+func ReleaseHold(bookingID int64, userID int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM bookings WHERE id = ? AND user_id = ?`, bookingID, userID).Scan(&count)
+	if err != nil {
+		log.Println("Error releasing hold:", err)
+		return err
+	}
+	if count == 0 {
+		return errors.New("no active hold found for this booking")
+	}
+
+	actor := Actor{UserID: userID, Role: "player"}
+	if _, err := transitionTx(tx, bookingID, StatusPending, StatusCanceled, actor, "hold released by player"); err != nil {
+		if errors.Is(err, ErrIllegalTransition) {
+			return errors.New("no active hold found for this booking")
+		}
+		return err
+	}
+	return tx.Commit()
 }
@@ -3,88 +3,152 @@ package booking
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"time"
+	"github.com/JkD004/playarena-backend/coupon"
 	"github.com/JkD004/playarena-backend/notification"
 	"github.com/JkD004/playarena-backend/venue"
-	//"github.com/JkD004/playarena-backend/gateway"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
 )
 
-// CreateNewBooking handles the business logic
-func CreateNewBooking(req *CreateBookingRequest, userID int64) (*Booking, error) {
+// QuoteBooking prices a requested slot (with its line items) without reserving anything
+func QuoteBooking(req *CreateBookingRequest) (*venue.PriceQuote, error) {
 	// 1. Get Venue details for pricing
 	venueToBook, err := venue.GetVenueByID(req.VenueID)
 	if err != nil {
 		return nil, errors.New("venue not found or not available for booking")
 	}
 
-	// 2. Calculate Duration
-	duration := req.EndTime.Sub(req.StartTime)
-	if duration <= 0 {
+	// 2. Resolve the court (venues split into courts/tables are booked per court)
+	court, err := venue.ResolveCourt(req.VenueID, req.CourtID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Validate Duration and the venue's hours / slot grid
+	if !req.EndTime.After(req.StartTime) {
 		return nil, errors.New("end time must be after start time")
 	}
-	
-	// 3. Calculate Price
-	totalPrice := duration.Hours() * venueToBook.PricePerHour
+	if err := venue.CheckBookable(venueToBook, req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
 
-	// 4. Check Availability
-	available, err := IsSlotAvailable(req.VenueID, req.StartTime, req.EndTime)
+	// 4. Calculate Price (price rules, then court override, then the venue price)
+	quote, err := venue.QuotePrice(venueToBook, court, req.StartTime, req.EndTime)
 	if err != nil {
-		return nil, errors.New("error checking slot availability")
+		log.Println("Service error quoting booking:", err)
+		return nil, errors.New("failed to calculate price")
 	}
-	if !available {
-		return nil, errors.New("this time slot is no longer available")
+	return quote, nil
+}
+
+// CreateNewBooking handles the business logic
+func CreateNewBooking(req *CreateBookingRequest, userID int64) (*Booking, error) {
+	quote, err := QuoteBooking(req)
+	if err != nil {
+		return nil, err
 	}
 
 	if req.StartTime.Before(time.Now().Add(-2 * time.Minute)) {
 		return nil, errors.New("cannot book a time slot in the past")
 	}
 
-	// 5. Create Booking Object
+	// Promo code (optional)
+	var cp *coupon.Coupon
+	var discount money.Money
+	if req.CouponCode != "" {
+		cp, discount, err = evaluateCoupon(req.CouponCode, userID, req.VenueID, quote.Total)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A prepaid pack/pass pays for the slot unless a coupon was given or the player opts out
+	if cp == nil && !req.SkipPack {
+		packBooking, err := bookWithPack(req, quote, userID)
+		if err != nil {
+			return nil, err
+		}
+		if packBooking != nil {
+			return packBooking, nil
+		}
+	}
+
+	// Create Booking Object (held for HoldDuration while the player pays)
+	holdExpiresAt := time.Now().Add(HoldDuration)
 	newBooking := &Booking{
-		UserID:     userID,
-		VenueID:    req.VenueID,
-		StartTime:  req.StartTime,
-		EndTime:    req.EndTime,
-		TotalPrice: totalPrice,
-		Status:     "pending", // Default to pending until payment
+		UserID:         userID,
+		VenueID:        req.VenueID,
+		CourtID:        req.CourtID,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		TotalPrice:     quote.Total - discount,
+		PriceBreakdown: quote.Items,
+		Status:         "pending", // Default to pending until payment
+		HoldExpiresAt:  &holdExpiresAt,
+		DiscountAmount: discount,
+	}
+	if cp != nil {
+		newBooking.CouponCode = cp.Code
 	}
 
-	// 6. Save to DB
-	err = CreateBooking(newBooking)
+	// Check availability and save in one transaction
+	err = ReserveSlot(newBooking)
 	if err != nil {
+		if errors.Is(err, ErrSlotUnavailable) {
+			return nil, err
+		}
 		log.Println("Service error creating booking:", err)
 		return nil, errors.New("failed to create booking")
 	}
 
+	// The caps are re-checked under a lock; losing the race gives the slot back
+	if cp != nil {
+		if err := coupon.Reserve(cp, userID, newBooking.ID, discount); err != nil {
+			_ = TransitionBooking(newBooking.ID, StatusCanceled, SystemActor, "coupon could not be applied")
+			return nil, err
+		}
+	}
+
 	return newBooking, nil
 }
 
 // BlockVenueSlot creates a "blocked" booking (Owner/Admin only)
 func BlockVenueSlot(req *CreateBookingRequest, userID int64) error {
-	// 1. Check availability
-	available, err := IsSlotAvailable(req.VenueID, req.StartTime, req.EndTime)
-	if err != nil {
-		return errors.New("error checking slot availability")
+	if !req.EndTime.After(req.StartTime) {
+		return errors.New("end time must be after start time")
 	}
-	if !available {
-		return errors.New("this slot is already booked or blocked")
+
+	// court_id 0 blocks the whole venue (every court); otherwise just that court
+	if req.CourtID != 0 {
+		court, err := venue.FindCourtByID(req.CourtID)
+		if err != nil || court.VenueID != req.VenueID {
+			return errors.New("court not found at this venue")
+		}
 	}
 
-	// 2. Create a "Blocked" booking
+	// Create a "Blocked" booking
 	// We use 'confirmed' status so it takes up the slot.
 	// We set TotalPrice to 0 because it's an internal block.
 	newBooking := &Booking{
-		UserID:     userID,
-		VenueID:    req.VenueID,
-		StartTime:  req.StartTime,
-		EndTime:    req.EndTime,
-		TotalPrice: 0,           // <--- FIX: Set to 0 for blocks
-		Status:     "confirmed", // <--- FIX: Confirmed immediately
+		UserID:      userID,
+		VenueID:     req.VenueID,
+		CourtID:     req.CourtID,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		TotalPrice:  0,           // <--- FIX: Set to 0 for blocks
+		Status:      "confirmed", // <--- FIX: Confirmed immediately
+		BookingType: "block",     // Shown as 'blocked' in the slot grid
 	}
 
-	err = CreateBooking(newBooking)
+	// Availability check + insert happen atomically
+	err := ReserveSlot(newBooking)
 	if err != nil {
+		if errors.Is(err, ErrSlotUnavailable) {
+			return err
+		}
 		log.Println("Service error blocking slot:", err)
 		return errors.New("failed to block slot")
 	}
@@ -106,12 +170,32 @@ func ProcessPayment(bookingID int64, paymentID string) error { // <--- Added pay
 		return err
 	}
 
+	AfterPaymentConfirmed(bookingID)
+
 	// 3. Notification
 	message := "Payment successful! Your booking has been confirmed."
 	_ = notification.CreateNotification(booking.UserID, message, "success")
 
 	return nil
 }
+
+// ReleaseBookingHold frees the slot of an unpaid booking, e.g. when the payment failed
+func ReleaseBookingHold(bookingID int64, userID int64) error {
+	booking, err := FindBookingByID(bookingID)
+	if err != nil {
+		return errors.New("booking not found")
+	}
+
+	err = ReleaseHold(bookingID, userID)
+	if err != nil {
+		return err
+	}
+	onSlotReleased(booking)
+
+	_ = notification.CreateNotification(userID, "Payment was not completed. Your slot has been released.", "warning")
+	return nil
+}
+
 // --- Getters & Helpers ---
 
 // GetBookingsForUser is the service-layer function
@@ -120,8 +204,9 @@ func GetBookingsForUser(userID int64) ([]Booking, error) {
 }
 
 
-// CancelBooking handles the logic for canceling a booking by the PLAYER
-func CancelBooking(bookingID int64, userID int64) error {
+// CancelBooking handles the logic for canceling a booking by the PLAYER.
+// refundMethod ('source' or 'wallet') says where the refund of a paid booking goes.
+func CancelBooking(bookingID int64, userID int64, refundMethod string) error {
 	booking, err := FindBookingByID(bookingID)
 	if err != nil {
 		return errors.New("booking not found")
@@ -131,40 +216,125 @@ func CancelBooking(bookingID int64, userID int64) error {
 		return errors.New("unauthorized")
 	}
 
+	// Venues with a tiered policy refund automatically
+	tiers, err := venue.FindCancellationTiersByVenueID(booking.VenueID)
+	if err != nil {
+		return errors.New("could not load cancellation policy")
+	}
+	if booking.PackPurchaseID != 0 {
+		return cancelPackBooking(booking, tiers)
+	}
+	if len(tiers) > 0 {
+		return cancelWithPolicy(booking, tiers, refundMethod)
+	}
+
 	// Time check (e.g., 2 hours before)
 	if time.Now().After(booking.StartTime.Add(-2 * time.Hour)) {
 		return errors.New("cannot cancel less than 2 hours before start")
 	}
 
-	var newStatus string
 	var notifMsg string
 
 	if booking.Status == "pending" {
 		// Unpaid -> Just cancel
-		newStatus = "canceled"
 		notifMsg = "Booking canceled."
+		err = UpdateBookingStatus(bookingID, userID, StatusCanceled, "canceled by player")
 	} else if booking.Status == "confirmed" {
-		// Paid -> DO NOT REFUND YET. Set to Requested.
-		newStatus = "refund_requested"
+		// Paid -> DO NOT REFUND YET. Set to Requested (remembering where the refund should go).
 		notifMsg = "Cancellation requested. Waiting for venue owner approval for refund."
-		
+		actor := Actor{UserID: userID, Role: "player"}
+		err = SetBookingRefund(bookingID, StatusRefundRequested, booking.TotalPrice, refundMethod, actor, "canceled by player")
+
 		// TODO: Send notification to OWNER here as well
 	} else {
 		return errors.New("cannot cancel this booking")
 	}
-
-	err = UpdateBookingStatus(bookingID, userID, newStatus)
 	if err != nil {
 		return err
 	}
 
 	// Notify Player
 	_ = notification.CreateNotification(userID, notifMsg, "warning")
+
+	// The slot is free again (a refund request no longer holds it)
+	onSlotReleased(booking)
 	
 	return nil
 }
 
 
+// cancelWithPolicy cancels a booking under the venue's refund tiers. A paid booking is refunded
+// its tier's share straight away; a refund the gateway turns down is retried in the background.
+// If the refund can't even be recorded it falls back to an owner-approved request.
+func cancelWithPolicy(b *Booking, tiers []venue.CancellationTier, refundMethod string) error {
+	notifMsg, err := applyCancellationPolicy(b, tiers, refundMethod)
+	if err != nil {
+		return err
+	}
+
+	_ = notification.CreateNotification(b.UserID, notifMsg, "warning")
+
+	// The slot is free again
+	onSlotReleased(b)
+	return nil
+}
+
+// applyCancellationPolicy does the status change and refund of cancelWithPolicy and returns
+// the message for the player
+func applyCancellationPolicy(b *Booking, tiers []venue.CancellationTier, refundMethod string) (string, error) {
+	hoursBefore := time.Until(b.StartTime).Hours()
+	if hoursBefore <= 0 {
+		return "", errors.New("cannot cancel a booking that has already started")
+	}
+
+	var notifMsg string
+	switch b.Status {
+	case "pending":
+		// Unpaid -> Just cancel
+		if err := UpdateBookingStatus(b.ID, b.UserID, StatusCanceled, "canceled by player"); err != nil {
+			return "", err
+		}
+		notifMsg = "Booking canceled."
+
+	case "confirmed":
+		percent := venue.RefundPercentFor(tiers, hoursBefore)
+		amount := b.TotalPrice.Percent(float64(percent))
+		actor := Actor{UserID: b.UserID, Role: "player"}
+		reason := fmt.Sprintf("canceled by player %.1fh before start, %d%% refund tier", hoursBefore, percent)
+
+		switch {
+		case amount <= 0:
+			if err := SetBookingRefund(b.ID, StatusCanceled, 0, refundMethod, actor, reason); err != nil {
+				return "", err
+			}
+			notifMsg = "Booking canceled. No refund applies under the venue's cancellation policy."
+		default:
+			// Claim the booking first so a second cancel can't trigger another refund
+			if err := SetBookingRefund(b.ID, StatusRefundRequested, amount, refundMethod, actor, reason); err != nil {
+				return "", err
+			}
+			pending, err := refundBooking(b, amount, refundMethod, fmt.Sprintf("canceled %.1fh before start, %d%% refund tier", hoursBefore, percent))
+			if err != nil {
+				log.Println("Automatic refund failed, leaving it for the owner:", err)
+				notifMsg = fmt.Sprintf("Booking canceled. Your refund of %s (%d%%) will be processed by the venue.", amount.Format(), percent)
+			} else {
+				if _, err := finishRefund(b.ID, pending, SystemActor, fmt.Sprintf("automatic refund of %s to %s", amount, refundMethod)); err != nil {
+					log.Println("Error updating refunded booking:", err)
+				}
+				if refundMethod == RefundToWallet {
+					notifMsg = fmt.Sprintf("Booking canceled. %s (%d%%) has been added to your wallet.", amount.Format(), percent)
+				} else {
+					notifMsg = fmt.Sprintf("Booking canceled. %s (%d%%) is being refunded to your original payment method; we'll let you know once it's processed.", amount.Format(), percent)
+				}
+			}
+		}
+
+	default:
+		return "", errors.New("cannot cancel this booking")
+	}
+	return notifMsg, nil
+}
+
 // GetBookingsForVenue is the service-layer function
 func GetBookingsForVenue(venueID int64) ([]AdminBookingView, error) {
 	return FindBookingsByVenueID(venueID)
@@ -283,6 +453,27 @@ func init() {
 	_ = time.Duration(0)
 }
 
+// refundByVenue cancels a paid booking on the venue's side with a full refund to the original
+// payment method, spread over its checkout and any reschedule top-ups like every cancellation
+// refund (refundBooking). The refund is recorded first, so settlement and credit notes reverse exactly it;
+// if it can't be sent the booking stays 'refund_requested' and the owner can approve it again.
+// Returns the booking's new status.
+func refundByVenue(b *Booking, actor Actor, reason string) (string, error) {
+	if err := SetBookingRefund(b.ID, StatusRefundRequested, b.TotalPrice, RefundToSource, actor, reason); err != nil {
+		return "", err
+	}
+	pending, err := refundBooking(b, b.TotalPrice, RefundToSource, reason)
+	if err != nil {
+		log.Println("Venue refund failed, leaving it for the owner:", err)
+		return StatusRefundRequested, nil
+	}
+	status, err := finishRefund(b.ID, pending, actor, fmt.Sprintf("full refund of %s", b.TotalPrice))
+	if err != nil {
+		return StatusRefundRequested, err
+	}
+	return status, nil
+}
+
 // ManageBookingAttendance handles OWNER/ADMIN actions
 func ManageBookingAttendance(bookingID int64, userID int64, userRole string, action string) error {
 	// action can be: 'present', 'absent', 'cancel'
@@ -305,18 +496,40 @@ func ManageBookingAttendance(bookingID int64, userID int64, userRole string, act
 	} else if action == "cancel" {
 		// Owner is canceling the booking (e.g., rain, maintenance)
 		if booking.Status == "confirmed" {
-			newStatus = "refunded" // Owner cancels paid slot -> MUST Refund
-		} else {
-			newStatus = "canceled"
+			// Owner cancels paid slot -> MUST Refund
+			if userRole != "admin" {
+				if isOwner, err := venue.IsVenueOwner(booking.VenueID, userID); err != nil || !isOwner {
+					return errors.New("booking not found or you do not own this venue")
+				}
+			}
+			if _, err := refundByVenue(booking, actorFor(userID, userRole), "marked cancel by venue"); err != nil {
+				return err
+			}
+			msg := fmt.Sprintf("The venue canceled your booking on %s. Your full payment of %s is being refunded.",
+				booking.StartTime.In(venue.IST).Format("02 Jan 2006, 03:04 PM"), booking.TotalPrice.Format())
+			_ = notification.CreateNotification(booking.UserID, msg, "warning")
+			onSlotReleased(booking)
+			return nil
 		}
+		newStatus = "canceled"
 	} else {
 		return errors.New("invalid action")
 	}
 
 	// 3. Apply Update
+	reason := "marked " + action + " by venue"
 	if userRole == "admin" {
-		return UpdateBookingStatusDirect(bookingID, newStatus)
+		err = TransitionBooking(bookingID, newStatus, actorFor(userID, userRole), reason)
 	} else {
-		return UpdateBookingStatusByOwner(bookingID, userID, newStatus)
+		err = UpdateBookingStatusByOwner(bookingID, userID, newStatus, reason)
 	}
+	if err != nil {
+		return err
+	}
+
+	// 4. A canceled/refunded slot goes to the waitlist
+	if action == "cancel" {
+		onSlotReleased(booking)
+	}
+	return nil
 }
@@ -0,0 +1,149 @@
+// booking/booking_state.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/pack"
+)
+
+// Booking statuses
+const (
+	StatusPending         = "pending"          // Slot held, waiting for payment
+	StatusConfirmed       = "confirmed"        // Paid (or blocked by the venue)
+	StatusPresent         = "present"          // Player checked in
+	StatusAbsent          = "absent"           // No-show
+	StatusCanceled        = "canceled"         // Closed without a refund
+	StatusRefundRequested = "refund_requested" // Paid booking canceled, refund waiting for the owner
+	StatusRefundInitiated = "refund_initiated" // Refund sent to the gateway, waiting for the bank to process it
+	StatusRefunded        = "refunded"
+	StatusRefundRejected  = "refund_rejected"
+)
+
+// ErrIllegalTransition is returned when a status change is not allowed from the current status
+var ErrIllegalTransition = errors.New("illegal booking status change")
+
+// transitions lists every status a booking may move to from each status.
+// Statuses without an entry (canceled, refunded, refund_rejected) are final.
+var transitions = map[string][]string{
+	StatusPending:         {StatusConfirmed, StatusCanceled},
+	StatusConfirmed:       {StatusPresent, StatusAbsent, StatusCanceled, StatusRefundRequested, StatusRefundInitiated, StatusRefunded},
+	StatusPresent:         {StatusAbsent}, // Attendance can be corrected
+	StatusAbsent:          {StatusPresent},
+	StatusRefundRequested: {StatusRefundInitiated, StatusRefunded, StatusRefundRejected},
+	StatusRefundInitiated: {StatusRefunded},
+}
+
+// CanTransition tells whether a booking in status `from` may move to `to`
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Actor is whoever caused a status change
+type Actor struct {
+	UserID int64  // 0 for the system
+	Role   string // 'player', 'owner', 'admin', 'staff' or 'system'
+}
+
+// SystemActor is used for changes made by workers and payment callbacks
+var SystemActor = Actor{Role: "system"}
+
+// actorFor builds the actor from the authenticated user
+func actorFor(userID int64, userRole string) Actor {
+	return Actor{UserID: userID, Role: userRole}
+}
+
+// StatusChange is one entry of a booking's timeline
+type StatusChange struct {
+	ID          int64     `json:"id"`
+	BookingID   int64     `json:"booking_id"`
+	FromStatus  string    `json:"from_status,omitempty"` // Empty for the creation entry
+	ToStatus    string    `json:"to_status"`
+	ActorUserID int64     `json:"actor_user_id,omitempty"`
+	ActorRole   string    `json:"actor_role"`
+	Reason      string    `json:"reason,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// transitionTx moves a booking to `to` inside tx and records it in the history.
+// The row is locked first; expectFrom (if not empty) additionally pins the current status,
+// which guards compare-and-set flows such as "cancel only if still pending".
+// Returns the status the booking had before.
+func transitionTx(tx *sql.Tx, bookingID int64, expectFrom string, to string, actor Actor, reason string) (string, error) {
+	var from string
+	err := tx.QueryRow(`SELECT status FROM bookings WHERE id = ? FOR UPDATE`, bookingID).Scan(&from)
+	if err == sql.ErrNoRows {
+		return "", errors.New("booking not found")
+	}
+	if err != nil {
+		log.Println("Error locking booking:", err)
+		return "", err
+	}
+
+	if expectFrom != "" && from != expectFrom {
+		return from, fmt.Errorf("%w: booking is %s, not %s", ErrIllegalTransition, from, expectFrom)
+	}
+	if !CanTransition(from, to) {
+		return from, fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+
+	// Only a pending booking holds its slot through hold_expires_at
+	_, err = tx.Exec(`UPDATE bookings SET status = ?, hold_expires_at = NULL WHERE id = ?`, to, bookingID)
+	if err != nil {
+		log.Println("Error updating booking status:", err)
+		return from, err
+	}
+
+	if err := insertStatusChange(tx, bookingID, from, to, actor, reason); err != nil {
+		return from, err
+	}
+
+	// Wallet money set aside for an unpaid booking follows its fate
+	if from == StatusPending && (to == StatusConfirmed || to == StatusCanceled) {
+		if err := settleWalletHoldTx(tx, bookingID, to); err != nil {
+			return from, err
+		}
+	}
+
+	// Credit or debit the venue owner's settlement ledger
+	if err := recordSettlementTx(tx, bookingID, from, to); err != nil {
+		return from, err
+	}
+
+	// A refunded booking paid from a pack gives its hours back (no-op for other bookings)
+	if to == StatusRefunded {
+		if err := pack.ReturnUsageTx(tx, bookingID); err != nil {
+			return from, err
+		}
+		// An invoiced booking gets a credit note for what was refunded
+		if err := issueCreditNoteTx(tx, bookingID); err != nil {
+			return from, err
+		}
+	}
+	return from, nil
+}
+
+// TransitionBooking applies a single status change in its own transaction
+func TransitionBooking(bookingID int64, to string, actor Actor, reason string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting status transaction:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := transitionTx(tx, bookingID, "", to, actor, reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,119 @@
+// booking/calendar_handler.go
+package booking
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedURL builds the public URL of a feed from the incoming request
+func feedURL(c *gin.Context, token string) string {
+	return apiURL(c, "/calendar/"+token+".ics")
+}
+
+// apiURL builds a public URL of this API (path is below /api/v1) from the incoming request
+func apiURL(c *gin.Context, path string) string {
+	scheme := "https"
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	} else if c.Request.TLS == nil {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/api/v1%s", scheme, c.Request.Host, path)
+}
+
+// CreateCalendarFeedHandler handles POST /api/v1/calendar-feeds
+func CreateCalendarFeedHandler(c *gin.Context) {
+	var req struct {
+		VenueID int64 `json:"venue_id"` // Optional: a venue's schedule instead of my bookings
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+
+	// 🔒 SECURITY CHECK (venue feeds: owner/admin only, checked in the service)
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	feed, err := NewCalendarFeed(userID, userRole, req.VenueID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	feed.URL = feedURL(c, feed.Token)
+	c.JSON(http.StatusCreated, feed)
+}
+
+// GetCalendarFeedsHandler handles GET /api/v1/calendar-feeds
+func GetCalendarFeedsHandler(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	feeds, err := FindCalendarFeedsByUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch calendar feeds"})
+		return
+	}
+	for i := range feeds {
+		feeds[i].URL = feedURL(c, feeds[i].Token)
+	}
+	c.JSON(http.StatusOK, feeds)
+}
+
+// RevokeCalendarFeedHandler handles DELETE /api/v1/calendar-feeds/:id
+func RevokeCalendarFeedHandler(c *gin.Context) {
+	feedID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	if err := RevokeCalendarFeed(feedID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked"})
+}
+
+// CalendarFeedHandler handles GET /api/v1/calendar/:token (public, the token is the credential)
+func CalendarFeedHandler(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	ics, err := BuildCalendarFeed(token)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
+
+// DownloadBookingICSHandler handles GET /api/v1/bookings/:id/calendar.ics?token=... (link from the confirmation email)
+func DownloadBookingICSHandler(c *gin.Context) {
+	bookingID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	// 🔒 The link carries a token signed for this booking (see LinkToken)
+	if !ValidLinkToken(bookingID, c.Query("token")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+
+	ics, err := BuildBookingICS(bookingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=BOOKING-%d.ics", bookingID))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
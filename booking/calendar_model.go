@@ -0,0 +1,19 @@
+// booking/calendar_model.go
+package booking
+
+import "time"
+
+// CalendarFeed is a subscribable iCal URL. The token in the URL is the only credential,
+// since calendar apps can't send our auth header; revoking the feed kills the URL.
+type CalendarFeed struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	VenueID   int64      `json:"venue_id,omitempty"` // 0 = the user's own bookings
+	Token     string     `json:"token"`
+	URL       string     `json:"url,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CalendarFeedHistory is how far back a feed goes; older bookings are left out
+const CalendarFeedHistory = 60 * 24 * time.Hour
@@ -0,0 +1,84 @@
+// booking/calendar_repository.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// CreateCalendarFeed stores a new feed token
+func CreateCalendarFeed(feed *CalendarFeed) error {
+	var venueID sql.NullInt64
+	if feed.VenueID != 0 {
+		venueID = sql.NullInt64{Int64: feed.VenueID, Valid: true}
+	}
+	result, err := db.DB.Exec(
+		`INSERT INTO calendar_feeds (user_id, venue_id, token) VALUES (?, ?, ?)`,
+		feed.UserID, venueID, feed.Token,
+	)
+	if err != nil {
+		log.Println("Error inserting calendar feed:", err)
+		return err
+	}
+	feed.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// FindCalendarFeedByToken returns a live (not revoked) feed
+func FindCalendarFeedByToken(token string) (*CalendarFeed, error) {
+	var f CalendarFeed
+	err := db.DB.QueryRow(`
+		SELECT id, user_id, COALESCE(venue_id, 0), token, created_at
+		FROM calendar_feeds
+		WHERE token = ? AND revoked_at IS NULL
+	`, token).Scan(&f.ID, &f.UserID, &f.VenueID, &f.Token, &f.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// FindCalendarFeedsByUserID lists a user's live feeds
+func FindCalendarFeedsByUserID(userID int64) ([]CalendarFeed, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, user_id, COALESCE(venue_id, 0), token, created_at
+		FROM calendar_feeds
+		WHERE user_id = ? AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		log.Println("Error fetching calendar feeds:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := make([]CalendarFeed, 0)
+	for rows.Next() {
+		var f CalendarFeed
+		if err := rows.Scan(&f.ID, &f.UserID, &f.VenueID, &f.Token, &f.CreatedAt); err != nil {
+			log.Println("Error scanning calendar feed:", err)
+			continue
+		}
+		feeds = append(feeds, f)
+	}
+	return feeds, nil
+}
+
+// RevokeCalendarFeed disables a feed owned by userID
+func RevokeCalendarFeed(feedID int64, userID int64) error {
+	result, err := db.DB.Exec(
+		`UPDATE calendar_feeds SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		feedID, userID,
+	)
+	if err != nil {
+		log.Println("Error revoking calendar feed:", err)
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.New("calendar feed not found")
+	}
+	return nil
+}
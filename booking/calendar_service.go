@@ -0,0 +1,152 @@
+// booking/calendar_service.go
+package booking
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/utils"
+	"github.com/JkD004/playarena-backend/user"
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// NewCalendarFeed creates a feed of the user's bookings, or (venueID != 0) of a venue's schedule
+func NewCalendarFeed(userID int64, userRole string, venueID int64) (*CalendarFeed, error) {
+	if venueID != 0 && userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, errors.New("failed to create calendar feed")
+	}
+	feed := &CalendarFeed{UserID: userID, VenueID: venueID, Token: hex.EncodeToString(raw), CreatedAt: time.Now()}
+	if err := CreateCalendarFeed(feed); err != nil {
+		return nil, errors.New("failed to create calendar feed")
+	}
+	return feed, nil
+}
+
+// cancelledStatus tells calendar apps to drop the event
+func cancelledStatus(status string) bool {
+	return status == StatusCanceled || status == StatusRefundRequested || status == StatusRefundInitiated || status == StatusRefunded || status == StatusRefundRejected
+}
+
+func bookingUID(bookingID int64) string {
+	return fmt.Sprintf("booking-%d@sportgrid", bookingID)
+}
+
+func eventTitle(sport, venueName, courtName string) string {
+	title := venueName
+	if sport != "" {
+		title = sport + " at " + venueName
+	}
+	if courtName != "" {
+		title += " (" + courtName + ")"
+	}
+	return title
+}
+
+// BuildCalendarFeed renders the .ics for a feed token
+func BuildCalendarFeed(token string) ([]byte, error) {
+	feed, err := FindCalendarFeedByToken(token)
+	if err != nil {
+		return nil, errors.New("calendar feed not found")
+	}
+	since := time.Now().Add(-CalendarFeedHistory)
+
+	if feed.VenueID == 0 {
+		bookings, err := FindBookingsByUserID(feed.UserID)
+		if err != nil {
+			return nil, err
+		}
+		events := make([]utils.CalendarEvent, 0, len(bookings))
+		for _, b := range bookings {
+			if b.Status == StatusPending || b.StartTime.Before(since) {
+				continue
+			}
+			events = append(events, utils.CalendarEvent{
+				UID:         bookingUID(b.ID),
+				Start:       b.StartTime,
+				End:         b.EndTime,
+				Summary:     eventTitle(b.SportCategory, b.VenueName, b.CourtName),
+				Location:    b.VenueAddress,
+				Description: fmt.Sprintf("Booking #%d", b.ID),
+				Cancelled:   cancelledStatus(b.Status),
+			})
+		}
+		return utils.GenerateICS("SportGrid bookings", events), nil
+	}
+
+	// The feed stops working if its creator no longer manages the venue
+	if err := venue.VerifyVenueOwnership(feed.VenueID, feed.UserID); err != nil {
+		u, userErr := user.GetUserByID(feed.UserID)
+		if userErr != nil || u.Role != "admin" {
+			return nil, errors.New("calendar feed not found")
+		}
+	}
+
+	bookings, err := FindBookingsByVenueID(feed.VenueID)
+	if err != nil {
+		return nil, err
+	}
+	calName := "Venue schedule"
+	if len(bookings) > 0 {
+		calName = bookings[0].VenueName + " schedule"
+	}
+	events := make([]utils.CalendarEvent, 0, len(bookings))
+	for _, b := range bookings {
+		// Synced blocks are left out so two calendars importing each other don't echo
+		if b.Status == StatusPending || b.StartTime.Before(since) || b.CalendarSourceID != 0 {
+			continue
+		}
+		summary := fmt.Sprintf("%s %s", b.UserFirstName, b.UserLastName)
+		if b.CourtName != "" {
+			summary += " - " + b.CourtName
+		}
+		events = append(events, utils.CalendarEvent{
+			UID:         bookingUID(b.BookingID),
+			Start:       b.StartTime,
+			End:         b.EndTime,
+			Summary:     summary,
+			Description: fmt.Sprintf("Booking #%d\nPhone: %s\nStatus: %s", b.BookingID, b.UserPhone, b.Status),
+			Cancelled:   cancelledStatus(b.Status),
+		})
+	}
+	return utils.GenerateICS(calName, events), nil
+}
+
+// BuildBookingICS renders a single booking as an .ics file (e.g. "Add to calendar" in the email)
+func BuildBookingICS(bookingID int64) ([]byte, error) {
+	b, err := FindBookingByID(bookingID)
+	if err != nil || b.RescheduleOf != 0 {
+		return nil, errors.New("booking not found")
+	}
+	v, err := venue.GetVenueByID(b.VenueID)
+	if err != nil {
+		return nil, errors.New("venue not found")
+	}
+
+	courtName := ""
+	if b.CourtID != 0 {
+		if court, err := venue.FindCourtByID(b.CourtID); err == nil {
+			courtName = court.Name
+		}
+	}
+	event := utils.CalendarEvent{
+		UID:         bookingUID(b.ID),
+		Start:       b.StartTime,
+		End:         b.EndTime,
+		Summary:     eventTitle(v.SportCategory, v.Name, courtName),
+		Location:    v.Address,
+		Description: fmt.Sprintf("Booking #%d", b.ID),
+		Cancelled:   cancelledStatus(b.Status),
+		Sequence:    b.RescheduleCount, // Moving the booking updates the same event
+	}
+	return utils.GenerateICS("SportGrid booking", []utils.CalendarEvent{event}), nil
+}
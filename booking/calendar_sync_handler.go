@@ -0,0 +1,181 @@
+// booking/calendar_sync_handler.go
+package booking
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JkD004/playarena-backend/venue"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCalendarSourcesHandler handles GET /api/v1/venues/:id/calendar-sources
+func GetCalendarSourcesHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	list, err := FindCalendarSourcesByVenueID(venueID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch calendar sources"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// CreateCalendarSourceHandler handles POST /api/v1/venues/:id/calendar-sources
+func CreateCalendarSourceHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var req struct {
+		Name    string `json:"name" binding:"required"`
+		URL     string `json:"url" binding:"required"`
+		CourtID int64  `json:"court_id"` // Optional: block one court instead of the whole venue
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	source, result, err := AddCalendarSource(&CalendarSource{
+		VenueID:   venueID,
+		CourtID:   req.CourtID,
+		Name:      req.Name,
+		URL:       req.URL,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"source": source, "sync": result})
+}
+
+// UploadCalendarHandler handles POST /api/v1/venues/:id/calendar-sources/upload (multipart "file")
+func UploadCalendarHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ".ics file is required"})
+		return
+	}
+	if file.Size > MaxCalendarFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Calendar file is too large"})
+		return
+	}
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer src.Close()
+
+	// Optional form fields: source_id (re-upload into an existing source), name, court_id
+	sourceID, _ := strconv.ParseInt(c.PostForm("source_id"), 10, 64)
+	courtID, _ := strconv.ParseInt(c.PostForm("court_id"), 10, 64)
+
+	source, result, err := ImportCalendarFile(&CalendarSource{
+		VenueID:   venueID,
+		CourtID:   courtID,
+		Name:      c.PostForm("name"),
+		CreatedBy: userID,
+	}, sourceID, src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"source": source, "sync": result})
+}
+
+// SyncCalendarSourceHandler handles POST /api/v1/calendar-sources/:id/sync
+func SyncCalendarSourceHandler(c *gin.Context) {
+	sourceID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	source, err := calendarSourceForManager(sourceID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := SyncCalendarSource(source)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteCalendarSourceHandler handles DELETE /api/v1/calendar-sources/:id
+func DeleteCalendarSourceHandler(c *gin.Context) {
+	sourceID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	source, err := calendarSourceForManager(sourceID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := RemoveCalendarSource(source); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar source removed and its blocks released"})
+}
@@ -0,0 +1,49 @@
+// booking/calendar_sync_model.go
+package booking
+
+import "time"
+
+// Calendar source kinds
+const (
+	CalendarSourceURL    = "url"    // Fetched again by the worker every CalendarSyncInterval
+	CalendarSourceUpload = "upload" // An uploaded .ics; uploading again replaces its blocks
+)
+
+const (
+	CalendarSyncInterval = 15 * time.Minute
+	CalendarSyncHorizon  = 90 * 24 * time.Hour // Only events starting within this window become blocks
+	MaxCalendarFileSize  = 5 << 20
+)
+
+// CalendarSource is an external calendar whose events are mirrored as blocked slots
+type CalendarSource struct {
+	ID           int64      `json:"id"`
+	VenueID      int64      `json:"venue_id"`
+	CourtID      int64      `json:"court_id,omitempty"` // 0 = blocks the whole venue
+	Name         string     `json:"name"`
+	Kind         string     `json:"kind"`
+	URL          string     `json:"url,omitempty"`
+	CreatedBy    int64      `json:"created_by"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	BlockCount   int        `json:"block_count"` // Live synced blocks
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CalendarSyncResult summarizes one sync run
+type CalendarSyncResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+	Conflicts int `json:"conflicts"` // Events overlapping a real booking; not blocked
+	Skipped   int `json:"skipped"`   // Past or out-of-window events, and repeating ones we can't expand
+}
+
+// syncedBlock is a live block previously created from a source
+type syncedBlock struct {
+	ID        int64
+	UID       string
+	StartTime time.Time
+	EndTime   time.Time
+}
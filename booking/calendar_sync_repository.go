@@ -0,0 +1,226 @@
+// booking/calendar_sync_repository.go
+package booking
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/pkg/utils"
+)
+
+// CreateCalendarSource stores a new source
+func CreateCalendarSource(s *CalendarSource) error {
+	var courtID sql.NullInt64
+	if s.CourtID != 0 {
+		courtID = sql.NullInt64{Int64: s.CourtID, Valid: true}
+	}
+	result, err := db.DB.Exec(`
+		INSERT INTO calendar_sources (venue_id, court_id, name, kind, url, created_by)
+		VALUES (?, ?, ?, ?, NULLIF(?, ''), ?)
+	`, s.VenueID, courtID, s.Name, s.Kind, s.URL, s.CreatedBy)
+	if err != nil {
+		log.Println("Error inserting calendar source:", err)
+		return err
+	}
+	s.ID, _ = result.LastInsertId()
+	return nil
+}
+
+const calendarSourceColumns = `
+	cs.id, cs.venue_id, COALESCE(cs.court_id, 0), cs.name, cs.kind, COALESCE(cs.url, ''), cs.created_by,
+	cs.last_synced_at, COALESCE(cs.last_error, ''), cs.created_at,
+	(SELECT COUNT(*) FROM bookings b WHERE b.calendar_source_id = cs.id AND b.status = 'confirmed' AND b.end_time > NOW())
+`
+
+func queryCalendarSources(where string, args ...interface{}) ([]CalendarSource, error) {
+	rows, err := db.DB.Query(`SELECT `+calendarSourceColumns+` FROM calendar_sources cs WHERE cs.is_active = 1 AND `+where, args...)
+	if err != nil {
+		log.Println("Error fetching calendar sources:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]CalendarSource, 0)
+	for rows.Next() {
+		var s CalendarSource
+		var lastSynced sql.NullTime
+		if err := rows.Scan(&s.ID, &s.VenueID, &s.CourtID, &s.Name, &s.Kind, &s.URL, &s.CreatedBy,
+			&lastSynced, &s.LastError, &s.CreatedAt, &s.BlockCount); err != nil {
+			log.Println("Error scanning calendar source:", err)
+			continue
+		}
+		if lastSynced.Valid {
+			s.LastSyncedAt = &lastSynced.Time
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// FindCalendarSourcesByVenueID lists a venue's active sources
+func FindCalendarSourcesByVenueID(venueID int64) ([]CalendarSource, error) {
+	return queryCalendarSources(`cs.venue_id = ? ORDER BY cs.created_at`, venueID)
+}
+
+// FindCalendarSourceByID fetches an active source
+func FindCalendarSourceByID(sourceID int64) (*CalendarSource, error) {
+	list, err := queryCalendarSources(`cs.id = ?`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &list[0], nil
+}
+
+// FindDueCalendarSources returns the URL sources not synced since `before`
+func FindDueCalendarSources(before time.Time) ([]CalendarSource, error) {
+	return queryCalendarSources(`cs.kind = 'url' AND (cs.last_synced_at IS NULL OR cs.last_synced_at < ?)`, before)
+}
+
+// MarkCalendarSourceSynced records the outcome of a sync run (an empty lastError clears it)
+func MarkCalendarSourceSynced(sourceID int64, lastError string) error {
+	_, err := db.DB.Exec(
+		`UPDATE calendar_sources SET last_synced_at = NOW(), last_error = NULLIF(?, '') WHERE id = ?`,
+		lastError, sourceID,
+	)
+	if err != nil {
+		log.Println("Error updating calendar source:", err)
+	}
+	return err
+}
+
+// DeactivateCalendarSource stops syncing a source
+func DeactivateCalendarSource(sourceID int64) error {
+	_, err := db.DB.Exec(`UPDATE calendar_sources SET is_active = 0 WHERE id = ?`, sourceID)
+	if err != nil {
+		log.Println("Error deactivating calendar source:", err)
+	}
+	return err
+}
+
+// ApplyCalendarSync makes the source's future blocks match `events` in one transaction:
+// vanished events are unblocked, moved ones are updated and new ones blocked.
+// Events that would overlap a real booking are counted as conflicts and left out.
+func ApplyCalendarSync(source *CalendarSource, events []utils.CalendarEvent) (*CalendarSyncResult, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting calendar sync transaction:", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockVenueForBooking(tx, source.VenueID); err != nil {
+		return nil, err
+	}
+
+	existing, err := findSyncedBlocksTx(tx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]utils.CalendarEvent, len(events))
+	for _, e := range events {
+		wanted[e.UID] = e
+	}
+
+	result := &CalendarSyncResult{}
+	reason := fmt.Sprintf("removed from external calendar %q", source.Name)
+
+	// 1. Removals first, so moved/new events don't collide with stale blocks
+	for uid, b := range existing {
+		if _, ok := wanted[uid]; ok {
+			continue
+		}
+		if _, err := transitionTx(tx, b.ID, StatusConfirmed, StatusCanceled, SystemActor, reason); err != nil {
+			return nil, err
+		}
+		// Free the UID so the event can come back later as a new block
+		if _, err := tx.Exec(`UPDATE bookings SET external_uid = NULL WHERE id = ?`, b.ID); err != nil {
+			return nil, err
+		}
+		delete(existing, uid)
+		result.Removed++
+	}
+
+	// 2. Moves and new events
+	for _, e := range events {
+		if b, ok := existing[e.UID]; ok {
+			if b.StartTime.Equal(e.Start) && b.EndTime.Equal(e.End) {
+				result.Unchanged++
+				continue
+			}
+			count, err := countOverlappingExcept(tx, source.VenueID, source.CourtID, e.Start, e.End, b.ID)
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				result.Conflicts++ // Keep the old interval
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE bookings SET start_time = ?, end_time = ? WHERE id = ?`, e.Start, e.End, b.ID); err != nil {
+				log.Println("Error moving synced block:", err)
+				return nil, err
+			}
+			result.Updated++
+			continue
+		}
+
+		count, err := countOverlapping(tx, source.VenueID, source.CourtID, e.Start, e.End)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			result.Conflicts++
+			continue
+		}
+		block := &Booking{
+			UserID:           source.CreatedBy,
+			VenueID:          source.VenueID,
+			CourtID:          source.CourtID,
+			StartTime:        e.Start,
+			EndTime:          e.End,
+			TotalPrice:       0,
+			Status:           "confirmed",
+			BookingType:      "block",
+			CalendarSourceID: source.ID,
+			ExternalUID:      e.UID,
+		}
+		if err := insertBooking(tx, block); err != nil {
+			return nil, err
+		}
+		result.Created++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// findSyncedBlocksTx loads the source's live future blocks keyed by event UID
+func findSyncedBlocksTx(tx *sql.Tx, sourceID int64) (map[string]syncedBlock, error) {
+	rows, err := tx.Query(`
+		SELECT id, external_uid, start_time, end_time
+		FROM bookings
+		WHERE calendar_source_id = ? AND status = 'confirmed' AND external_uid IS NOT NULL AND end_time > ?
+	`, sourceID, time.Now())
+	if err != nil {
+		log.Println("Error fetching synced blocks:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := make(map[string]syncedBlock)
+	for rows.Next() {
+		var b syncedBlock
+		if err := rows.Scan(&b.ID, &b.UID, &b.StartTime, &b.EndTime); err != nil {
+			return nil, err
+		}
+		blocks[b.UID] = b
+	}
+	return blocks, rows.Err()
+}
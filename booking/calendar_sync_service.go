@@ -0,0 +1,270 @@
+// booking/calendar_sync_service.go
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/utils"
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// calendarHTTPClient fetches owner-supplied URLs, so it only connects to public addresses.
+// The check runs on the address actually dialed, which covers redirects and DNS answers that change.
+var calendarHTTPClient = &http.Client{
+	Timeout: 20 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: refusePrivateAddress,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 15 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return errors.New("redirect to a non-http url")
+		}
+		return nil
+	},
+}
+
+// errPrivateAddress is returned when a calendar URL points inside our network
+var errPrivateAddress = errors.New("calendar url resolves to a private address")
+
+// refusePrivateAddress stops connections to loopback, private, link-local and other internal addresses
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || sharedAddressSpace.Contains(ip) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// sharedAddressSpace is carrier-grade NAT (RFC 6598), internal like the private ranges
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// AddCalendarSource registers an iCal URL for a venue and syncs it straight away
+func AddCalendarSource(s *CalendarSource) (*CalendarSource, *CalendarSyncResult, error) {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return nil, nil, errors.New("source name is required")
+	}
+	// webcal:// is how most calendar apps share subscription links
+	s.URL = strings.TrimSpace(s.URL)
+	if strings.HasPrefix(s.URL, "webcal://") {
+		s.URL = "https://" + strings.TrimPrefix(s.URL, "webcal://")
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, nil, errors.New("url must be an http(s) or webcal link")
+	}
+	if err := validateSourceCourt(s); err != nil {
+		return nil, nil, err
+	}
+
+	// Fetch before saving so a broken link is reported right away
+	events, err := fetchCalendar(s.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.Kind = CalendarSourceURL
+	if err := CreateCalendarSource(s); err != nil {
+		return nil, nil, errors.New("failed to save calendar source")
+	}
+	result, err := syncEvents(s, events)
+	if err != nil {
+		return nil, nil, err
+	}
+	created, _ := FindCalendarSourceByID(s.ID)
+	return created, result, nil
+}
+
+// ImportCalendarFile syncs an uploaded .ics. With sourceID it replaces that upload's blocks,
+// otherwise a new upload source is created.
+func ImportCalendarFile(s *CalendarSource, sourceID int64, file io.Reader) (*CalendarSource, *CalendarSyncResult, error) {
+	events, err := utils.ParseICS(io.LimitReader(file, MaxCalendarFileSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read calendar file: %v", err)
+	}
+
+	if sourceID != 0 {
+		existing, err := FindCalendarSourceByID(sourceID)
+		if err != nil || existing.VenueID != s.VenueID || existing.Kind != CalendarSourceUpload {
+			return nil, nil, errors.New("calendar source not found")
+		}
+		s = existing
+	} else {
+		s.Name = strings.TrimSpace(s.Name)
+		if s.Name == "" {
+			s.Name = "Uploaded calendar"
+		}
+		if err := validateSourceCourt(s); err != nil {
+			return nil, nil, err
+		}
+		s.Kind = CalendarSourceUpload
+		if err := CreateCalendarSource(s); err != nil {
+			return nil, nil, errors.New("failed to save calendar source")
+		}
+	}
+
+	result, err := syncEvents(s, events)
+	if err != nil {
+		return nil, nil, err
+	}
+	updated, _ := FindCalendarSourceByID(s.ID)
+	return updated, result, nil
+}
+
+func validateSourceCourt(s *CalendarSource) error {
+	if s.CourtID == 0 {
+		return nil
+	}
+	court, err := venue.FindCourtByID(s.CourtID)
+	if err != nil || court.VenueID != s.VenueID {
+		return errors.New("court not found at this venue")
+	}
+	return nil
+}
+
+// fetchCalendar downloads and parses an iCal URL. The details of a failure are only logged:
+// what the other server answered is not passed back to the caller.
+func fetchCalendar(rawURL string) ([]utils.CalendarEvent, error) {
+	resp, err := calendarHTTPClient.Get(rawURL)
+	if err != nil {
+		log.Printf("Error fetching calendar %s: %v\n", rawURL, err)
+		if errors.Is(err, errPrivateAddress) {
+			return nil, errors.New("calendar url must point to a public server")
+		}
+		return nil, errors.New("could not fetch calendar")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error fetching calendar %s: HTTP %d\n", rawURL, resp.StatusCode)
+		return nil, errors.New("could not fetch calendar")
+	}
+	events, err := utils.ParseICS(io.LimitReader(resp.Body, MaxCalendarFileSize))
+	if err != nil {
+		log.Printf("Error parsing calendar %s: %v\n", rawURL, err)
+		return nil, errors.New("could not read calendar: not a valid iCal file")
+	}
+	return events, nil
+}
+
+// syncEvents filters the events to the sync window and applies them, recording the outcome on the source
+func syncEvents(s *CalendarSource, events []utils.CalendarEvent) (*CalendarSyncResult, error) {
+	now := time.Now()
+	horizon := now.Add(CalendarSyncHorizon)
+
+	// Repeating events become one block per occurrence in the window
+	events = utils.ExpandRecurrences(events, now, horizon)
+
+	keep := make([]utils.CalendarEvent, 0, len(events))
+	skipped := 0
+	for _, e := range events {
+		// Cancelled events are dropped, so their block is removed like a deleted event
+		if e.Cancelled {
+			continue
+		}
+		// Still Recurring when the rule is one we don't expand (e.g. monthly)
+		if e.Recurring || !e.End.After(now) || e.Start.After(horizon) {
+			skipped++
+			continue
+		}
+		keep = append(keep, e)
+	}
+
+	result, err := ApplyCalendarSync(s, keep)
+	if err != nil {
+		log.Printf("Calendar sync of source #%d failed: %v\n", s.ID, err)
+		_ = MarkCalendarSourceSynced(s.ID, "sync failed")
+		return nil, errors.New("failed to sync calendar")
+	}
+	result.Skipped = skipped
+
+	lastError := ""
+	if result.Conflicts > 0 {
+		lastError = fmt.Sprintf("%d event(s) overlap existing bookings and were not blocked", result.Conflicts)
+	}
+	_ = MarkCalendarSourceSynced(s.ID, lastError)
+
+	if result.Removed > 0 || result.Updated > 0 {
+		log.Printf("Calendar source #%d: %d block(s) removed, %d moved\n", s.ID, result.Removed, result.Updated)
+	}
+	return result, nil
+}
+
+// SyncCalendarSource re-fetches a URL source now (uploads have nothing to re-fetch)
+func SyncCalendarSource(s *CalendarSource) (*CalendarSyncResult, error) {
+	if s.Kind != CalendarSourceURL {
+		return nil, errors.New("upload the file again to update this calendar")
+	}
+	events, err := fetchCalendar(s.URL)
+	if err != nil {
+		_ = MarkCalendarSourceSynced(s.ID, err.Error())
+		return nil, err
+	}
+	return syncEvents(s, events)
+}
+
+// SyncDueCalendarSources is run by the worker; returns how many sources were synced
+func SyncDueCalendarSources() (int, error) {
+	sources, err := FindDueCalendarSources(time.Now().Add(-CalendarSyncInterval))
+	if err != nil {
+		return 0, err
+	}
+	synced := 0
+	for i := range sources {
+		if _, err := SyncCalendarSource(&sources[i]); err != nil {
+			log.Printf("Calendar source #%d: %v\n", sources[i].ID, err)
+			continue
+		}
+		synced++
+	}
+	return synced, nil
+}
+
+// RemoveCalendarSource stops syncing a source and unblocks its future slots
+func RemoveCalendarSource(s *CalendarSource) error {
+	if _, err := ApplyCalendarSync(s, nil); err != nil {
+		return errors.New("failed to remove synced blocks")
+	}
+	return DeactivateCalendarSource(s.ID)
+}
+
+// calendarSourceForManager fetches a source after checking the caller may manage its venue
+func calendarSourceForManager(sourceID, userID int64, userRole string) (*CalendarSource, error) {
+	s, err := FindCalendarSourceByID(sourceID)
+	if err != nil {
+		return nil, errors.New("calendar source not found")
+	}
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(s.VenueID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
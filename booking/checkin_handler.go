@@ -0,0 +1,55 @@
+// booking/checkin_handler.go
+package booking
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JkD004/playarena-backend/pkg/ticket"
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyTicketHandler handles POST /api/v1/bookings/verify (ticket scan at the venue).
+// The booking is checked in on success, so each ticket works once.
+func VerifyTicketHandler(c *gin.Context) {
+	var req struct {
+		QRCodeString string `json:"qr_code" binding:"required"`
+		VenueID      int64  `json:"venue_id"` // Optional: the venue the scanner is at
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "message": "Invalid Request"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	result, err := CheckInTicket(req.QRCodeString, req.VenueID, userID, userRole)
+	if err != nil {
+		status := http.StatusForbidden
+		switch {
+		case errors.Is(err, ErrInvalidTicket):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrAlreadyCheckedIn):
+			status = http.StatusConflict
+		case errors.Is(err, ErrOutsideCheckInWindow), errors.Is(err, ErrTicketNotValid):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, ErrWrongVenue):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"valid": false, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":   true,
+		"message": "Checked in ✅",
+		"checkin": result,
+	})
+}
+
+// TicketKeysHandler handles GET /api/v1/tickets/keys (public).
+// Scanner apps use these Ed25519 keys to verify tickets offline.
+func TicketKeysHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"keys": ticket.PublicKeys()})
+}
@@ -0,0 +1,81 @@
+// booking/checkin_repository.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// CheckInBooking marks a confirmed booking present and stamps who scanned it, when and on which
+// device (empty for online scans). The row lock in transitionTx makes a second scan of the same
+// ticket fail with ErrAlreadyCheckedIn.
+func CheckInBooking(bookingID int64, actor Actor, at time.Time, device string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting check-in transaction:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	reason := "checked in by ticket scan"
+	if device != "" {
+		reason = "checked in offline on device " + device
+	}
+	from, err := transitionTx(tx, bookingID, StatusConfirmed, StatusPresent, actor, reason)
+	if err != nil {
+		if errors.Is(err, ErrIllegalTransition) && from == StatusPresent {
+			return ErrAlreadyCheckedIn
+		}
+		return err
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE bookings SET checked_in_at = ?, checked_in_by = ?, checked_in_device = NULLIF(?, '') WHERE id = ?`,
+		at, actor.UserID, device, bookingID,
+	); err != nil {
+		log.Println("Error recording check-in:", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindManifestEntries lists the check-in-able bookings of a venue that start in [from, to)
+func FindManifestEntries(venueID int64, from, to time.Time) ([]ManifestEntry, error) {
+	query := `
+		SELECT b.id, TRIM(CONCAT(u.first_name, ' ', u.last_name)), COALESCE(b.court_id, 0), COALESCE(c.name, ''),
+		       b.start_time, b.end_time, b.status, b.checked_in_at, COALESCE(b.checked_in_device, '')
+		FROM bookings b
+		JOIN users u ON b.user_id = u.id
+		LEFT JOIN courts c ON b.court_id = c.id
+		WHERE b.venue_id = ? AND b.start_time >= ? AND b.start_time < ?
+		AND b.status IN ('confirmed', 'present')
+		AND b.booking_type = 'booking' AND b.reschedule_of IS NULL
+		ORDER BY b.start_time, b.id
+	`
+	rows, err := db.DB.Query(query, venueID, from, to)
+	if err != nil {
+		log.Println("Error fetching manifest bookings:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]ManifestEntry, 0)
+	for rows.Next() {
+		var e ManifestEntry
+		var checkedInAt sql.NullTime
+		if err := rows.Scan(&e.BookingID, &e.PlayerName, &e.CourtID, &e.CourtName,
+			&e.StartTime, &e.EndTime, &e.Status, &checkedInAt, &e.CheckedInDevice); err != nil {
+			log.Println("Error scanning manifest booking:", err)
+			continue
+		}
+		if checkedInAt.Valid {
+			e.CheckedInAt = &checkedInAt.Time
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,76 @@
+// booking/checkin_service_test.go
+package booking
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/ticket"
+)
+
+// A player downloads a ticket, then moves the booking two days later. The ticket they hold
+// still carries the old slot's window; check-in follows the booking instead.
+func TestTicketIssuedBeforeReschedule(t *testing.T) {
+	t.Setenv("GIN_MODE", "") // Temporary dev key
+
+	oldStart := time.Now().Add(-48 * time.Hour).Truncate(time.Minute)
+	code, err := ticket.Issue(42, 7, oldStart, oldStart.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	newStart := time.Now().Add(10 * time.Minute)
+	moved := &Booking{ID: 42, VenueID: 7, StartTime: newStart, EndTime: newStart.Add(time.Hour)}
+
+	now := time.Now()
+	if _, err := ticket.Verify(code, now); !errors.Is(err, ticket.ErrExpired) {
+		t.Fatalf("the signed window should have passed, got %v", err)
+	}
+	claims, err := parseTicket(code)
+	if err != nil {
+		t.Fatalf("a ticket for a moved booking should still be accepted: %v", err)
+	}
+	if claims.BookingID != moved.ID {
+		t.Fatalf("got booking %d", claims.BookingID)
+	}
+	if !withinCheckInWindow(moved, now) {
+		t.Fatal("the moved booking is within its check-in window")
+	}
+	if withinCheckInWindow(moved, oldStart) {
+		t.Fatal("the old slot's time should not admit the moved booking")
+	}
+}
+
+func TestCheckInWindow(t *testing.T) {
+	start := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	b := &Booking{StartTime: start, EndTime: start.Add(time.Hour)}
+
+	cases := map[time.Duration]bool{
+		-CheckInOpensBefore - time.Second: false,
+		-CheckInOpensBefore:               true,
+		0:                                 true,
+		time.Hour - time.Second:           true,
+		time.Hour:                         false,
+	}
+	for offset, want := range cases {
+		if got := withinCheckInWindow(b, start.Add(offset)); got != want {
+			t.Errorf("at start%+v: got %v, want %v", offset, got, want)
+		}
+	}
+}
+
+func TestParseTicketRejectsForgeries(t *testing.T) {
+	t.Setenv("GIN_MODE", "")
+	start := time.Now()
+	code, err := ticket.Issue(42, 7, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if _, err := parseTicket(code[:len(code)-4] + "AAAA"); !errors.Is(err, ErrInvalidTicket) {
+		t.Fatalf("changed signature: got %v, want ErrInvalidTicket", err)
+	}
+	if _, err := parseTicket("not a ticket"); !errors.Is(err, ErrInvalidTicket) {
+		t.Fatalf("garbage: got %v, want ErrInvalidTicket", err)
+	}
+}
@@ -0,0 +1,27 @@
+// booking/coupon_handler.go
+package booking
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewCouponHandler handles POST /api/v1/coupons/validate
+// (same body as a booking, with coupon_code set)
+func PreviewCouponHandler(c *gin.Context) {
+	var req CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.CouponCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	preview, err := PreviewCoupon(&req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
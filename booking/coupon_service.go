@@ -0,0 +1,118 @@
+// booking/coupon_service.go
+package booking
+
+import (
+	"errors"
+	"log"
+
+	"github.com/JkD004/playarena-backend/coupon"
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// CouponPreview shows what a code would take off a slot before it is booked
+type CouponPreview struct {
+	Quote    *venue.PriceQuote `json:"quote"`
+	Code     string            `json:"code"`
+	Discount money.Money       `json:"discount"`
+	Total    money.Money       `json:"total"` // What the player will pay
+}
+
+// evaluateCoupon checks a code for this player at this venue
+func evaluateCoupon(code string, userID, venueID int64, amount money.Money) (*coupon.Coupon, money.Money, error) {
+	v, err := venue.GetVenueByID(venueID)
+	if err != nil {
+		return nil, 0, errors.New("venue not found")
+	}
+	return coupon.Evaluate(code, coupon.Target{
+		UserID:        userID,
+		VenueID:       venueID,
+		SportCategory: v.SportCategory,
+		Amount:        amount,
+	})
+}
+
+// PreviewCoupon prices a slot with a code without reserving anything
+func PreviewCoupon(req *CreateBookingRequest, userID int64) (*CouponPreview, error) {
+	quote, err := QuoteBooking(req)
+	if err != nil {
+		return nil, err
+	}
+	cp, discount, err := evaluateCoupon(req.CouponCode, userID, req.VenueID, quote.Total)
+	if err != nil {
+		return nil, err
+	}
+	return &CouponPreview{
+		Quote:    quote,
+		Code:     cp.Code,
+		Discount: discount,
+		Total:    quote.Total - discount,
+	}, nil
+}
+
+// ApplyCouponToBooking adds a code to a booking that is waiting for payment
+func ApplyCouponToBooking(bookingID int64, userID int64, code string) (*Booking, error) {
+	b, err := FindBookingByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if b.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+	if b.Status != StatusPending || b.SeriesID != 0 || b.RescheduleOf != 0 {
+		return nil, errors.New("a coupon can only be applied to an unpaid single booking")
+	}
+	if b.CouponCode != "" {
+		return nil, errors.New("this booking already has a coupon")
+	}
+	if b.WalletAmount > 0 {
+		return nil, errors.New("apply the coupon before paying from the wallet")
+	}
+
+	cp, discount, err := evaluateCoupon(code, userID, b.VenueID, b.TotalPrice)
+	if err != nil {
+		return nil, err
+	}
+	if err := coupon.Reserve(cp, userID, b.ID, discount); err != nil {
+		return nil, err
+	}
+
+	total := b.TotalPrice - discount
+	if err := SetBookingDiscount(b.ID, total, discount, cp.Code); err != nil {
+		coupon.Release(b.ID)
+		return nil, err
+	}
+
+	b.TotalPrice = total
+	b.DiscountAmount = discount
+	b.CouponCode = cp.Code
+	return b, nil
+}
+
+// AfterPaymentConfirmed runs the follow-ups of a confirmed payment (waitlist offer, coupon use)
+func AfterPaymentConfirmed(bookingID int64) {
+	ClaimWaitlistOffer(bookingID)
+	coupon.MarkRedeemed(bookingID)
+}
+
+// ConfirmFreeBooking confirms a booking a coupon made free, since there is nothing to charge
+func ConfirmFreeBooking(bookingID int64, userID int64) error {
+	b, err := FindBookingByID(bookingID)
+	if err != nil {
+		return errors.New("booking not found")
+	}
+	if b.UserID != userID || b.TotalPrice > 0 || b.CouponCode == "" {
+		return errors.New("this booking needs a payment")
+	}
+
+	if err := TransitionBooking(bookingID, StatusConfirmed, SystemActor, "fully covered by coupon "+b.CouponCode); err != nil {
+		log.Println("Error confirming free booking:", err)
+		return err
+	}
+	AfterPaymentConfirmed(bookingID)
+
+	_ = notification.CreateNotification(b.UserID, "Your booking is confirmed. It was fully covered by your coupon.", "success")
+	return nil
+}
@@ -0,0 +1,50 @@
+// booking/grid_handler.go
+package booking
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSlotGridHandler handles GET /api/v1/venues/:id/slots/grid?date=YYYY-MM-DD&granularity=60&court_id=
+func GetSlotGridHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Date query param required (YYYY-MM-DD)"})
+		return
+	}
+
+	var courtID int64
+	if courtStr := c.Query("court_id"); courtStr != "" {
+		courtID, err = strconv.ParseInt(courtStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+			return
+		}
+	}
+
+	granularity := 0 // venue default
+	if g := c.Query("granularity"); g != "" {
+		granularity, err = strconv.Atoi(g)
+		if err != nil || granularity <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid granularity"})
+			return
+		}
+	}
+
+	grid, err := BuildSlotGrid(venueID, courtID, dateStr, granularity)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, grid)
+}
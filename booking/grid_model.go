@@ -0,0 +1,36 @@
+// booking/grid_model.go
+package booking
+
+import (
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Slot statuses in the grid
+const (
+	SlotAvailable = "available"
+	SlotBooked    = "booked"
+	SlotBlocked   = "blocked" // closed by the owner
+	SlotLunch     = "lunch"
+	SlotPast      = "past"
+)
+
+// GridSlot is one cell of the day's booking grid
+type GridSlot struct {
+	StartTime time.Time   `json:"start_time"`
+	EndTime   time.Time   `json:"end_time"`
+	Status    string      `json:"status"`
+	Price     money.Money `json:"price"`
+}
+
+// SlotGrid is the full day for a venue (or one of its courts)
+type SlotGrid struct {
+	VenueID     int64      `json:"venue_id"`
+	CourtID     int64      `json:"court_id,omitempty"`
+	Date        string     `json:"date"`
+	Granularity int        `json:"granularity_minutes"`
+	OpeningTime string     `json:"opening_time"`
+	ClosingTime string     `json:"closing_time"`
+	Slots       []GridSlot `json:"slots"`
+}
@@ -0,0 +1,103 @@
+// booking/grid_service.go
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// BuildSlotGrid lays out the day from opening to closing time in steps of `granularity`
+// minutes (0 = the venue's slot length) and marks every slot with its status and price.
+func BuildSlotGrid(venueID int64, courtID int64, dateStr string, granularity int) (*SlotGrid, error) {
+	v, err := venue.GetVenueByID(venueID)
+	if err != nil {
+		return nil, errors.New("venue not found")
+	}
+	court, err := venue.ResolveCourt(venueID, courtID)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", dateStr, venue.IST)
+	if err != nil {
+		return nil, errors.New("date must be in YYYY-MM-DD format")
+	}
+
+	// The grid can be coarser than the venue's slots, but never finer, so every cell stays bookable
+	base := venue.SlotDuration(v)
+	step := base
+	if granularity > 0 {
+		step = time.Duration(granularity) * time.Minute
+		if step%base != 0 {
+			return nil, fmt.Errorf("granularity must be a multiple of %d minutes", int(base/time.Minute))
+		}
+	}
+
+	schedule, err := venue.ScheduleForDay(v, day)
+	if err != nil {
+		return nil, errors.New("venue opening hours are not configured correctly")
+	}
+
+	taken, err := GetBookedSlotsInRange(venueID, courtID, schedule.Open, schedule.Close)
+	if err != nil {
+		return nil, errors.New("failed to fetch slots")
+	}
+
+	pricer, err := venue.NewPricer(v, court)
+	if err != nil {
+		log.Println("Error loading price rules:", err)
+		return nil, errors.New("failed to calculate prices")
+	}
+
+	grid := &SlotGrid{
+		VenueID:     venueID,
+		CourtID:     courtID,
+		Date:        dateStr,
+		Granularity: int(step / time.Minute),
+		OpeningTime: v.OpeningTime,
+		ClosingTime: v.ClosingTime,
+		Slots:       make([]GridSlot, 0),
+	}
+
+	now := time.Now()
+	for start := schedule.Open; !start.Add(step).After(schedule.Close); start = start.Add(step) {
+		end := start.Add(step)
+		slot := GridSlot{StartTime: start, EndTime: end, Status: SlotAvailable}
+
+		switch {
+		case schedule.HasLunch() && start.Before(schedule.LunchEnd) && end.After(schedule.LunchStart):
+			slot.Status = SlotLunch
+		case start.Before(now):
+			slot.Status = SlotPast
+		default:
+			slot.Status = takenStatus(taken, start, end)
+		}
+
+		if slot.Status != SlotLunch {
+			if quote, err := pricer.Quote(start, end); err == nil {
+				slot.Price = quote.Total
+			}
+		}
+		grid.Slots = append(grid.Slots, slot)
+	}
+
+	return grid, nil
+}
+
+// takenStatus reports how [start, end) is occupied; an owner block wins over a booking
+func takenStatus(taken []BookedSlot, start, end time.Time) string {
+	status := SlotAvailable
+	for _, t := range taken {
+		if t.StartTime.Before(end) && t.EndTime.After(start) {
+			if t.Type == "block" {
+				return SlotBlocked
+			}
+			status = SlotBooked
+		}
+	}
+	return status
+}
@@ -0,0 +1,63 @@
+// booking/invoice_handler.go
+package booking
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JkD004/playarena-backend/invoice"
+	"github.com/gin-gonic/gin"
+)
+
+// IssueInvoiceHandler handles POST /api/v1/bookings/:id/invoice
+// The body (buyer_name, buyer_gstin, buyer_address, buyer_state_code) is optional.
+func IssueInvoiceHandler(c *gin.Context) {
+	bookingID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	var buyer invoice.Buyer
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&buyer); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	inv, err := IssueBookingInvoice(bookingID, userID, userRole, buyer)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, inv)
+	case errors.Is(err, invoice.ErrAlreadyInvoiced):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err.Error() == "unauthorized":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// GetBookingInvoicesHandler handles GET /api/v1/bookings/:id/invoices
+func GetBookingInvoicesHandler(c *gin.Context) {
+	bookingID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	list, err := GetBookingInvoices(bookingID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
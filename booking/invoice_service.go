@@ -0,0 +1,125 @@
+// booking/invoice_service.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/JkD004/playarena-backend/invoice"
+	"github.com/JkD004/playarena-backend/user"
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// invoiceableStatuses are the states in which the player has paid for the slot
+var invoiceableStatuses = map[string]bool{
+	StatusConfirmed:       true,
+	StatusPresent:         true,
+	StatusAbsent:          true,
+	StatusRefundRequested: true,
+	StatusRefundInitiated: true,
+	StatusRefundRejected:  true,
+	StatusRefunded:        true,
+}
+
+// bookingForInvoice loads a booking for its player, the venue's owner/staff or an admin
+func bookingForInvoice(bookingID, userID int64, userRole string) (*Booking, error) {
+	b, err := FindBookingByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if userRole != "admin" && b.UserID != userID {
+		if err := venue.VerifyVenueStaffAccess(b.VenueID, userID); err != nil {
+			return nil, errors.New("unauthorized")
+		}
+	}
+	return b, nil
+}
+
+// issueCreditNoteTx is called when a booking becomes 'refunded'. A failure fails the status
+// change with it, so a refunded booking never keeps an invoice without its credit note; the
+// booking stays where it was and the change is retried (the webhook is redelivered, the owner
+// approves again), which issues the note under the same, unused, number.
+func issueCreditNoteTx(tx *sql.Tx, bookingID int64) error {
+	var refundAmount money.Money
+	err := tx.QueryRow(`SELECT COALESCE(refund_amount, 0) FROM bookings WHERE id = ?`, bookingID).Scan(&refundAmount)
+	if err != nil {
+		log.Println("Error loading refund for credit note:", err)
+		return err
+	}
+	// Only what was actually given back is credited
+	if refundAmount <= 0 {
+		return nil
+	}
+	if _, err := invoice.IssueCreditNoteTx(tx, bookingID, refundAmount); err != nil {
+		log.Println("Error issuing credit note:", err)
+		return err
+	}
+	return nil
+}
+
+// IssueBookingInvoice issues the GST invoice for a paid booking. Buyer details default to
+// the player's name (an unregistered buyer); corporate players pass their GSTIN.
+func IssueBookingInvoice(bookingID, userID int64, userRole string, buyer invoice.Buyer) (*invoice.Invoice, error) {
+	b, err := bookingForInvoice(bookingID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if b.BookingType == "block" || !invoiceableStatuses[b.Status] {
+		return nil, errors.New("only paid bookings can be invoiced")
+	}
+	if b.PackPurchaseID != 0 {
+		return nil, errors.New("bookings paid from a pack are covered by the pack purchase")
+	}
+
+	if buyer.Name == "" {
+		player, err := user.GetUserByID(b.UserID)
+		if err != nil {
+			return nil, errors.New("player not found")
+		}
+		buyer.Name = player.FirstName + " " + player.LastName
+	}
+
+	description := "Sports facility booking"
+	if v, err := venue.GetVenueByID(b.VenueID); err == nil {
+		description += " - " + v.Name
+	}
+	if b.CourtID != 0 {
+		if court, err := venue.FindCourtByID(b.CourtID); err == nil {
+			description += ", " + court.Name
+		}
+	}
+	start, end := b.StartTime.In(venue.IST), b.EndTime.In(venue.IST)
+	description += fmt.Sprintf(" (%s, %s - %s)", start.Format("02 Jan 2006"), start.Format("03:04 PM"), end.Format("03:04 PM"))
+
+	inv, err := invoice.IssueInvoice(invoice.Supply{
+		BookingID:   b.ID,
+		VenueID:     b.VenueID,
+		UserID:      b.UserID,
+		Description: description,
+		Hours:       b.EndTime.Sub(b.StartTime).Hours(),
+		Amount:      b.TotalPrice,
+	}, buyer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Invoiced after the refund already happened: reverse it straight away
+	if b.Status == StatusRefunded && b.RefundAmount > 0 {
+		if _, err := invoice.IssueCreditNote(b.ID, b.RefundAmount); err != nil {
+			log.Println("Error issuing credit note:", err)
+		}
+	}
+	return inv, nil
+}
+
+// GetBookingInvoices lists a booking's invoice and credit notes
+func GetBookingInvoices(bookingID, userID int64, userRole string) ([]invoice.Invoice, error) {
+	if _, err := bookingForInvoice(bookingID, userID, userRole); err != nil {
+		return nil, err
+	}
+	return invoice.FindInvoicesByBookingID(bookingID)
+}
@@ -0,0 +1,28 @@
+// booking/link_token.go
+package booking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"strconv"
+)
+
+// LinkToken signs a booking's download links (ticket, calendar file) so they open from an
+// email without logging in. It is tied to the booking ID and the server's JWT secret.
+func LinkToken(bookingID int64) string {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("booking-link:" + strconv.FormatInt(bookingID, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidLinkToken checks a token from a download link
+func ValidLinkToken(bookingID int64, token string) bool {
+	expected := LinkToken(bookingID)
+	return expected != "" && hmac.Equal([]byte(token), []byte(expected))
+}
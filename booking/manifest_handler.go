@@ -0,0 +1,61 @@
+// booking/manifest_handler.go
+package booking
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCheckInManifestHandler handles GET /api/v1/venues/:id/checkin-manifest?date=YYYY-MM-DD
+func GetCheckInManifestHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	manifest, err := BuildCheckInManifest(venueID, c.Query("date"), userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, manifest)
+}
+
+// SyncCheckInsHandler handles POST /api/v1/venues/:id/checkins/sync
+func SyncCheckInsHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	var req struct {
+		DeviceID string           `json:"device_id" binding:"required"`
+		CheckIns []OfflineCheckIn `json:"checkins" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	results, err := SyncOfflineCheckIns(venueID, req.DeviceID, req.CheckIns, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary := map[string]int{SyncApplied: 0, SyncDuplicate: 0, SyncConflict: 0, SyncRejected: 0}
+	for _, r := range results {
+		summary[r.Result]++
+	}
+	c.JSON(http.StatusOK, gin.H{"summary": summary, "results": results})
+}
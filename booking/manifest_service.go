@@ -0,0 +1,218 @@
+// booking/manifest_service.go
+package booking
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/ticket"
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// MaxOfflineCheckIns caps one sync batch
+const MaxOfflineCheckIns = 500
+
+// ManifestEntry is one ticket a gate device may accept while offline
+type ManifestEntry struct {
+	BookingID       int64      `json:"booking_id"`
+	PlayerName      string     `json:"player_name"`
+	CourtID         int64      `json:"court_id,omitempty"`
+	CourtName       string     `json:"court_name,omitempty"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         time.Time  `json:"end_time"`
+	Status          string     `json:"status"` // 'confirmed', or 'present' if already checked in
+	CheckedInAt     *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInDevice string     `json:"checked_in_device,omitempty"`
+}
+
+// Manifest is the day's ticket list for one venue
+type Manifest struct {
+	VenueID     int64              `json:"venue_id"`
+	Date        string             `json:"date"` // YYYY-MM-DD (IST)
+	GeneratedAt time.Time          `json:"generated_at"`
+	ExpiresAt   time.Time          `json:"expires_at"`
+	Keys        []ticket.PublicKey `json:"keys"` // Every key a ticket may carry in its kid, older ones included; tickets signed by any of them verify offline
+	Entries     []ManifestEntry    `json:"entries"`
+}
+
+// SignedManifest carries the manifest bytes exactly as signed, so devices can verify them
+type SignedManifest struct {
+	Manifest  *Manifest `json:"manifest"`
+	Payload   string    `json:"payload"` // base64url(JSON of manifest)
+	KeyID     string    `json:"key_id"`
+	Signature string    `json:"signature"` // Ed25519 over "SGM1." + payload
+}
+
+// OfflineCheckIn is one scan recorded by a device without connectivity
+type OfflineCheckIn struct {
+	BookingID int64     `json:"booking_id"`
+	QRCode    string    `json:"qr_code"` // Optional; verified when present
+	ScannedAt time.Time `json:"scanned_at" binding:"required"`
+}
+
+// Outcomes of an offline check-in
+const (
+	SyncApplied   = "applied"   // Checked in now
+	SyncDuplicate = "duplicate" // Same device already synced this scan
+	SyncConflict  = "conflict"  // Checked in elsewhere first (another device or online)
+	SyncRejected  = "rejected"  // Not a valid ticket for this venue/time
+)
+
+// OfflineCheckInResult reports what happened to one scan
+type OfflineCheckInResult struct {
+	BookingID       int64      `json:"booking_id"`
+	Result          string     `json:"result"`
+	Message         string     `json:"message,omitempty"`
+	CheckedInAt     *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInDevice string     `json:"checked_in_device,omitempty"`
+}
+
+// BuildCheckInManifest returns the signed list of the day's valid tickets at a venue
+func BuildCheckInManifest(venueID int64, dateStr string, userID int64, userRole string) (*SignedManifest, error) {
+	// 🔒 SECURITY CHECK
+	if userRole != "admin" {
+		if err := venue.VerifyVenueStaffAccess(venueID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	day := time.Now().In(venue.IST)
+	if dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, venue.IST)
+		if err != nil {
+			return nil, errors.New("invalid date format. Use YYYY-MM-DD")
+		}
+		day = parsed
+	}
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, venue.IST)
+	to := from.AddDate(0, 0, 1)
+
+	entries, err := FindManifestEntries(venueID, from, to)
+	if err != nil {
+		return nil, errors.New("could not build manifest")
+	}
+
+	m := &Manifest{
+		VenueID:     venueID,
+		Date:        from.Format("2006-01-02"),
+		GeneratedAt: time.Now(),
+		ExpiresAt:   to,
+		Keys:        ticket.PublicKeys(),
+		Entries:     entries,
+	}
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	keyID, signature := ticket.SignDocument("SGM1", payload)
+	return &SignedManifest{
+		Manifest:  m,
+		Payload:   ticket.EncodePayload(payload),
+		KeyID:     keyID,
+		Signature: signature,
+	}, nil
+}
+
+// SyncOfflineCheckIns applies a device's queued scans in the order they happened.
+// Each booking can be checked in once; later scans of it are reported as conflicts.
+func SyncOfflineCheckIns(venueID int64, device string, scans []OfflineCheckIn, userID int64, userRole string) ([]OfflineCheckInResult, error) {
+	device = strings.TrimSpace(device)
+	if device == "" || len(device) > 100 {
+		return nil, errors.New("device_id is required (max 100 characters)")
+	}
+	if len(scans) > MaxOfflineCheckIns {
+		return nil, fmt.Errorf("at most %d check-ins per sync", MaxOfflineCheckIns)
+	}
+
+	// 🔒 SECURITY CHECK
+	actor := actorFor(userID, userRole)
+	if userRole != "admin" {
+		if err := venue.VerifyVenueStaffAccess(venueID, userID); err != nil {
+			return nil, err
+		}
+		if isOwner, _ := venue.IsVenueOwner(venueID, userID); !isOwner {
+			actor.Role = "staff"
+		}
+	}
+
+	results := make([]OfflineCheckInResult, 0, len(scans))
+	for _, scan := range scans {
+		results = append(results, applyOfflineCheckIn(venueID, device, scan, actor))
+	}
+	return results, nil
+}
+
+func applyOfflineCheckIn(venueID int64, device string, scan OfflineCheckIn, actor Actor) OfflineCheckInResult {
+	res := OfflineCheckInResult{BookingID: scan.BookingID}
+	reject := func(msg string) OfflineCheckInResult {
+		res.Result, res.Message = SyncRejected, msg
+		return res
+	}
+
+	// A device clock ahead of ours can't check someone in "in the future"
+	scannedAt := scan.ScannedAt
+	if now := time.Now(); scannedAt.After(now) {
+		scannedAt = now
+	}
+	// checked_in_at keeps whole seconds; comparing a retried upload needs the same precision
+	scannedAt = scannedAt.Truncate(time.Second)
+
+	if scan.QRCode != "" {
+		// The window comes from the booking below, which may have moved since the ticket was issued
+		claims, err := parseTicket(scan.QRCode)
+		if err != nil {
+			return reject(err.Error())
+		}
+		if scan.BookingID != 0 && claims.BookingID != scan.BookingID {
+			return reject("ticket does not match booking")
+		}
+		res.BookingID = claims.BookingID
+	}
+	if res.BookingID == 0 {
+		return reject("booking_id or qr_code is required")
+	}
+
+	b, err := FindBookingByID(res.BookingID)
+	if err != nil || b.BookingType == "block" || b.RescheduleOf != 0 {
+		return reject(ErrInvalidTicket.Error())
+	}
+	if b.VenueID != venueID {
+		return reject(ErrWrongVenue.Error())
+	}
+
+	if b.Status == StatusPresent {
+		res.CheckedInAt, res.CheckedInDevice = b.CheckedInAt, b.CheckedInDevice
+		if b.CheckedInDevice == device && b.CheckedInAt != nil && b.CheckedInAt.Equal(scannedAt) {
+			res.Result = SyncDuplicate // A retried upload of the same scan
+			return res
+		}
+		res.Result, res.Message = SyncConflict, "ticket was already checked in"
+		return res
+	}
+	if b.Status != StatusConfirmed {
+		return reject(fmt.Sprintf("%s (%s)", ErrTicketNotValid.Error(), b.Status))
+	}
+	if !withinCheckInWindow(b, scannedAt) {
+		return reject(ErrOutsideCheckInWindow.Error())
+	}
+
+	err = CheckInBooking(b.ID, actor, scannedAt, device)
+	if errors.Is(err, ErrAlreadyCheckedIn) {
+		// Another device synced the same ticket a moment ago
+		latest, _ := FindBookingByID(b.ID)
+		res.Result, res.Message = SyncConflict, "ticket was already checked in"
+		if latest != nil {
+			res.CheckedInAt, res.CheckedInDevice = latest.CheckedInAt, latest.CheckedInDevice
+		}
+		return res
+	}
+	if err != nil {
+		return reject("could not apply check-in")
+	}
+
+	res.Result, res.CheckedInAt, res.CheckedInDevice = SyncApplied, &scannedAt, device
+	return res
+}
@@ -0,0 +1,102 @@
+// booking/pack_service.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/pack"
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// bookWithPack books the slot against the player's prepaid pack/pass at this venue.
+// Returns nil (and no error) when no pack covers the slot, so the caller charges normally.
+func bookWithPack(req *CreateBookingRequest, quote *venue.PriceQuote, userID int64) (*Booking, error) {
+	purchase, err := pack.FindApplicable(userID, req.VenueID, req.StartTime, req.EndTime)
+	if err != nil {
+		log.Println("Error looking up packs:", err)
+		return nil, nil // Fall back to paying
+	}
+	if purchase == nil {
+		return nil, nil
+	}
+
+	// Paid already: the booking is confirmed straight away and costs nothing now
+	newBooking := &Booking{
+		UserID:         userID,
+		VenueID:        req.VenueID,
+		CourtID:        req.CourtID,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		TotalPrice:     0,
+		PriceBreakdown: quote.Items,
+		Status:         "confirmed",
+		PackPurchaseID: purchase.ID,
+	}
+	hours := req.EndTime.Sub(req.StartTime).Hours()
+
+	err = reserveSlotWith(newBooking, func(tx *sql.Tx) error {
+		return pack.DrawDownTx(tx, purchase.ID, newBooking.ID, hours)
+	})
+	if errors.Is(err, pack.ErrPackNotUsable) {
+		return nil, nil // Used up by a parallel booking
+	}
+	if err != nil {
+		if errors.Is(err, ErrSlotUnavailable) {
+			return nil, err
+		}
+		log.Println("Service error creating pack booking:", err)
+		return nil, errors.New("failed to create booking")
+	}
+
+	AfterPaymentConfirmed(newBooking.ID)
+
+	msg := fmt.Sprintf("Booking confirmed using your %s.", purchase.PackageName)
+	if purchase.Kind == pack.KindHours {
+		msg = fmt.Sprintf("Booking confirmed using your %s (%.1f hours left).", purchase.PackageName, purchase.HoursRemaining-hours)
+	}
+	_ = notification.CreateNotification(userID, msg, "success")
+
+	return FindBookingByID(newBooking.ID)
+}
+
+// cancelPackBooking cancels a booking paid from a pack. When the cancellation would have been
+// refundable (2h notice, or a refunding tier of the venue's policy) the hours go back to the pack;
+// otherwise they are forfeited.
+func cancelPackBooking(b *Booking, tiers []venue.CancellationTier) error {
+	if b.Status != "confirmed" {
+		return errors.New("cannot cancel this booking")
+	}
+	hoursBefore := time.Until(b.StartTime).Hours()
+	if hoursBefore <= 0 {
+		return errors.New("cannot cancel a booking that has already started")
+	}
+
+	refundable := hoursBefore >= 2
+	if len(tiers) > 0 {
+		refundable = venue.RefundPercentFor(tiers, hoursBefore) > 0
+	} else if !refundable {
+		return errors.New("cannot cancel less than 2 hours before start")
+	}
+
+	actor := Actor{UserID: b.UserID, Role: "player"}
+	notifMsg := "Booking canceled. The hours have been returned to your pack."
+	var err error
+	if refundable {
+		err = TransitionBooking(b.ID, StatusRefunded, actor, fmt.Sprintf("canceled by player %.1fh before start, pack hours returned", hoursBefore))
+	} else {
+		err = TransitionBooking(b.ID, StatusCanceled, actor, fmt.Sprintf("canceled by player %.1fh before start, pack hours forfeited", hoursBefore))
+		notifMsg = "Booking canceled. Under the venue's cancellation policy the hours are not returned to your pack."
+	}
+	if err != nil {
+		return err
+	}
+
+	_ = notification.CreateNotification(b.UserID, notifMsg, "warning")
+	onSlotReleased(b)
+	return nil
+}
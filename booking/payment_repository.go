@@ -0,0 +1,153 @@
+// booking/payment_repository.go
+package booking
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// SetBookingOrder remembers the Razorpay order created for a booking so payment webhooks can find it
+func SetBookingOrder(bookingID int64, orderID string) error {
+	_, err := db.DB.Exec(`UPDATE bookings SET razorpay_order_id = ? WHERE id = ?`, orderID, bookingID)
+	if err != nil {
+		log.Println("Error saving booking order:", err)
+	}
+	return err
+}
+
+// SetSeriesOrder stamps the series' Razorpay order on every occurrence it pays for
+func SetSeriesOrder(seriesID int64, orderID string) error {
+	_, err := db.DB.Exec(`UPDATE bookings SET razorpay_order_id = ? WHERE series_id = ? AND status = 'pending'`, orderID, seriesID)
+	if err != nil {
+		log.Println("Error saving series order:", err)
+	}
+	return err
+}
+
+// FindBookingIDsByOrderID lists the bookings a Razorpay order was created for
+func FindBookingIDsByOrderID(orderID string) ([]int64, error) {
+	rows, err := db.DB.Query(`SELECT id FROM bookings WHERE razorpay_order_id = ? ORDER BY id ASC`, orderID)
+	if err != nil {
+		log.Println("Error fetching bookings by order:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// FindCapturedAmount returns what a Razorpay payment captured, as recorded from the checkout,
+// webhook or reconciliation. sql.ErrNoRows means no captured attempt was recorded.
+func FindCapturedAmount(paymentID string) (money.Money, error) {
+	var amount money.Money
+	err := db.DB.QueryRow(`
+		SELECT amount FROM payment_attempts WHERE payment_id = ? AND status IN ('captured', 'refunded')
+	`, paymentID).Scan(&amount)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println("Error fetching captured amount:", err)
+	}
+	return amount, err
+}
+
+// IsPaymentApplied tells whether a Razorpay payment was already recorded on a booking or a reschedule
+func IsPaymentApplied(paymentID string) (bool, error) {
+	var count int
+	err := db.DB.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM bookings WHERE razorpay_payment_id = ?)
+		     + (SELECT COUNT(*) FROM booking_reschedules WHERE payment_id = ?)
+	`, paymentID, paymentID).Scan(&count)
+	if err != nil {
+		log.Println("Error checking payment:", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SetLatePayment records a payment that arrived after the bookings were canceled (and is being refunded)
+func SetLatePayment(bookingIDs []int64, paymentID string) error {
+	for _, id := range bookingIDs {
+		if _, err := db.DB.Exec(`UPDATE bookings SET razorpay_payment_id = ? WHERE id = ? AND status = 'canceled'`, paymentID, id); err != nil {
+			log.Println("Error recording late payment:", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// FindBookingsAwaitingRefund lists the bookings with a refund on a payment (their checkout or a
+// reschedule top-up) that was sent to Razorpay and hasn't been processed yet
+func FindBookingsAwaitingRefund(paymentID string) ([]int64, error) {
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT b.id FROM bookings b
+		JOIN refunds r ON r.booking_id = b.id
+		WHERE r.payment_id = ? AND b.status = 'refund_initiated'
+	`, paymentID)
+	if err != nil {
+		log.Println("Error fetching bookings awaiting refund:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UpdateRefundStatus records what Razorpay reported for a refund on a booking's payment.
+// Returns the player to tell, or 0 when no refunded booking uses the payment.
+func UpdateRefundStatus(paymentID, refundID, status string) (int64, error) {
+	result, err := db.DB.Exec(`
+		UPDATE bookings SET refund_status = ?, razorpay_refund_id = ?
+		WHERE razorpay_payment_id = ? AND status IN ('refunded', 'refund_initiated', 'refund_requested', 'canceled')
+	`, status, refundID, paymentID)
+	if err != nil {
+		log.Println("Error updating refund status:", err)
+		return 0, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return 0, nil
+	}
+	var userID int64
+	err = db.DB.QueryRow(`SELECT user_id FROM bookings WHERE razorpay_payment_id = ? LIMIT 1`, paymentID).Scan(&userID)
+	return userID, err
+}
+
+// FailLatestRescheduleRefund marks the newest price-difference refund on a payment as failed.
+// Returns the player to tell, or 0 when there is none.
+func FailLatestRescheduleRefund(paymentID string) (int64, error) {
+	var rescheduleID, userID int64
+	err := db.DB.QueryRow(`
+		SELECT r.id, b.user_id
+		FROM booking_reschedules r
+		JOIN bookings b ON r.booking_id = b.id
+		WHERE b.razorpay_payment_id = ? AND r.refund_status = 'refunded'
+		ORDER BY r.id DESC LIMIT 1
+	`, paymentID).Scan(&rescheduleID, &userID)
+	if err == sql.ErrNoRows {
+		return 0, nil // Not a reschedule refund
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := UpdateRescheduleRefundStatus(rescheduleID, "failed"); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
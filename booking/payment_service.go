@@ -0,0 +1,209 @@
+// booking/payment_service.go
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/refund"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// ErrAmountMismatch is returned when a payment doesn't cover exactly what is owed
+var ErrAmountMismatch = errors.New("payment amount does not match what is owed")
+
+// ErrPaymentAlreadyApplied is returned when a payment was already recorded, e.g. by the webhook
+// before the browser's verify call arrived
+var ErrPaymentAlreadyApplied = errors.New("payment already applied")
+
+// ErrHoldLost is returned when a payment arrived after the hold ran out and someone else took the slot.
+// The bookings are canceled by then and the payment has to be refunded.
+var ErrHoldLost = errors.New("slot hold expired and the slot was taken")
+
+// Refund outcomes reported by Razorpay
+const (
+	RefundProcessed = "processed"
+	RefundFailed    = "failed"
+)
+
+// ApplyCapturedPayment confirms whatever bookings a captured Razorpay order was created for:
+// a single booking, every held occurrence of a series, or a reschedule top-up.
+// It returns false when no booking uses the order. Safe to call more than once per payment.
+func ApplyCapturedPayment(orderID, paymentID string, amount money.Money) (bool, error) {
+	ids, err := FindBookingIDsByOrderID(orderID)
+	if err != nil {
+		return false, err
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+	if applied, err := IsPaymentApplied(paymentID); err != nil || applied {
+		return true, err
+	}
+
+	b, err := FindBookingByID(ids[0])
+	if err != nil {
+		return true, err
+	}
+
+	// A mismatched payment stays unapplied; the event is retried and refunded once the hold has expired
+	if b.Status == StatusPending {
+		if err := CheckPaidAmount(b.ID, b.SeriesID, amount); err != nil {
+			return true, err
+		}
+	}
+
+	switch {
+	case b.RescheduleOf != 0:
+		// Refunds the top-up itself when the new slot was lost
+		if err := CompleteReschedule(b.ID, paymentID); err != nil {
+			log.Println("Webhook reschedule could not be completed:", err)
+		}
+		return true, nil
+
+	case b.SeriesID != 0:
+		err := ConfirmSeries(b.SeriesID, paymentID)
+		if err == nil {
+			return true, nil
+		}
+		// The verify call may have confirmed the occurrences in the meantime
+		if applied, checkErr := IsPaymentApplied(paymentID); checkErr != nil || applied {
+			return true, checkErr
+		}
+		log.Println("Webhook series payment could not be applied:", err)
+		return true, refundLatePayment(ids, b.UserID, paymentID, amount)
+
+	case b.Status == StatusPending:
+		err := ConfirmBookingPayment(b.ID, paymentID)
+		if errors.Is(err, ErrPaymentAlreadyApplied) {
+			return true, nil
+		}
+		if errors.Is(err, ErrHoldLost) {
+			return true, refundLatePayment(ids, b.UserID, paymentID, amount)
+		}
+		if err != nil {
+			return true, err
+		}
+		AfterPaymentConfirmed(b.ID)
+		_ = notification.CreateNotification(b.UserID, "Payment received. Your booking is confirmed.", "success")
+		return true, nil
+
+	case b.Status == StatusCanceled:
+		// The hold ran out before the payment was reported
+		return true, refundLatePayment(ids, b.UserID, paymentID, amount)
+
+	default:
+		log.Printf("Payment %s for order %s ignored: booking #%d is %s\n", paymentID, orderID, b.ID, b.Status)
+		return true, nil
+	}
+}
+
+// CheckPaidAmount compares a payment with what is owed now on a booking (or a series when seriesID is set)
+func CheckPaidAmount(bookingID, seriesID int64, amount money.Money) error {
+	var owed money.Money
+	if seriesID != 0 {
+		total, count, err := GetSeriesPayableAmount(seriesID)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrAmountMismatch
+		}
+		owed = total
+	} else {
+		b, err := FindBookingByID(bookingID)
+		if err != nil {
+			return err
+		}
+		owed = b.TotalPrice - b.WalletAmount
+	}
+	if owed != amount {
+		log.Printf("Payment of %s does not match %s owed on booking %d / series %d\n", amount, owed, bookingID, seriesID)
+		return ErrAmountMismatch
+	}
+	return nil
+}
+
+// RefundLostHold refunds a payment whose order's bookings lost their slot before it was confirmed (ErrHoldLost)
+func RefundLostHold(orderID, paymentID string, amount money.Money) error {
+	ids, err := FindBookingIDsByOrderID(orderID)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return errors.New("no booking uses this order")
+	}
+	b, err := FindBookingByID(ids[0])
+	if err != nil {
+		return err
+	}
+	return refundLatePayment(ids, b.UserID, paymentID, amount)
+}
+
+// refundLatePayment gives back a payment whose bookings were canceled before it arrived.
+// The payment is only recorded once the refund has been recorded, so a retried event tries again.
+func refundLatePayment(bookingIDs []int64, userID int64, paymentID string, amount money.Money) error {
+	_, err := refund.Initiate(refund.Request{
+		Reference: "late:" + paymentID,
+		BookingID: bookingIDs[0],
+		UserID:    userID,
+		PaymentID: paymentID,
+		Amount:    amount,
+		Reason:    "payment arrived after the slot hold expired",
+	})
+	if err != nil {
+		return err
+	}
+	if err := SetLatePayment(bookingIDs, paymentID); err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("Your payment of %s arrived after your slot hold expired, so it is being refunded.", amount.Format())
+	_ = notification.CreateNotification(userID, msg, "warning")
+	return nil
+}
+
+// SettleProcessedRefunds marks the bookings on a payment refunded once the refund package has
+// recorded their refunds as processed. Called after each refund event for the payment.
+func SettleProcessedRefunds(paymentID string) error {
+	ids, err := FindBookingsAwaitingRefund(paymentID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := settleProcessedRefund(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyRefundStatus records the outcome Razorpay reports for a refund started before refunds
+// were tracked by the refund package. It returns false when the payment isn't a booking's.
+func ApplyRefundStatus(paymentID, refundID, status string, amount money.Money) (bool, error) {
+	userID, err := UpdateRefundStatus(paymentID, refundID, status)
+	if err != nil {
+		return false, err
+	}
+	if userID == 0 && status == RefundFailed {
+		// Price-difference refunds after a reschedule use the booking's payment too
+		userID, err = FailLatestRescheduleRefund(paymentID)
+		if err != nil {
+			return false, err
+		}
+	}
+	if userID == 0 {
+		return false, nil
+	}
+
+	if status == RefundFailed {
+		msg := fmt.Sprintf("Your refund of %s could not be processed by the bank. Our team will retry it.", amount.Format())
+		_ = notification.CreateNotification(userID, msg, "warning")
+	} else {
+		msg := fmt.Sprintf("Your refund of %s has been processed. It may take 5-7 working days to show in your account.", amount.Format())
+		_ = notification.CreateNotification(userID, msg, "info")
+	}
+	return true, nil
+}
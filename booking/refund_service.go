@@ -0,0 +1,25 @@
+// booking/refund_service.go
+package booking
+
+import (
+	"errors"
+
+	"github.com/JkD004/playarena-backend/refund"
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// GetBookingRefunds lists the gateway refunds made on a booking, for its player, the venue owner or an admin
+func GetBookingRefunds(bookingID int64, userID int64, userRole string) ([]refund.Refund, error) {
+	b, err := FindBookingByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	// 🔒 SECURITY CHECK
+	if userRole != "admin" && b.UserID != userID {
+		isOwner, err := venue.IsVenueOwner(b.VenueID, userID)
+		if err != nil || !isOwner {
+			return nil, errors.New("unauthorized")
+		}
+	}
+	return refund.FindByBookingID(bookingID)
+}
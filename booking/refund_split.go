@@ -0,0 +1,110 @@
+// booking/refund_split.go
+package booking
+
+import (
+	"database/sql"
+
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/JkD004/playarena-backend/refund"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// bookingPayment is one Razorpay payment a booking was paid with: its checkout, or the
+// top-up of a move to a dearer slot
+type bookingPayment struct {
+	PaymentID  string
+	Reference  string      // Refund reference used against this payment
+	Refundable money.Money // Captured, less what other refunds have already taken
+}
+
+// refundPart is the share of a refund sent back against one payment
+type refundPart struct {
+	PaymentID string
+	Reference string
+	Amount    money.Money
+}
+
+// planRefund spreads amount over the payments, newest first, each up to what it can still take.
+// Whatever they can't take is returned as rest.
+func planRefund(payments []bookingPayment, amount money.Money) (parts []refundPart, rest money.Money) {
+	rest = amount
+	for i := len(payments) - 1; i >= 0 && rest > 0; i-- {
+		p := payments[i]
+		share := money.Min(rest, p.Refundable)
+		if share <= 0 {
+			continue
+		}
+		parts = append(parts, refundPart{PaymentID: p.PaymentID, Reference: p.Reference, Amount: share})
+		rest -= share
+	}
+	return parts, rest
+}
+
+// splitRefund divides a refund between the booking's Razorpay payments (up to what Razorpay
+// charged for the booking) and the wallet. Refunds against the first payment use reference;
+// each top-up gets "<reference>:<payment ID>".
+func splitRefund(b *Booking, amount money.Money, method, reference string) ([]refundPart, money.Money, error) {
+	if method == RefundToWallet || b.PaymentID == "" {
+		return nil, amount, nil
+	}
+	payments, err := bookingPayments(b, reference)
+	if err != nil {
+		return nil, 0, err
+	}
+	parts, walletPart := divideRefund(b, amount, payments)
+	return parts, walletPart, nil
+}
+
+// divideRefund is splitRefund once the booking's payments are known
+func divideRefund(b *Booking, amount money.Money, payments []bookingPayment) ([]refundPart, money.Money) {
+	gatewayAmount := money.Max(0, money.Min(amount, b.TotalPrice-b.WalletAmount))
+	parts, rest := planRefund(payments, gatewayAmount)
+	// What no payment can take any more (e.g. refunded by hand at Razorpay) goes to the wallet
+	return parts, amount - gatewayAmount + rest
+}
+
+// bookingPayments lists a booking's Razorpay payments, oldest first
+func bookingPayments(b *Booking, reference string) ([]bookingPayment, error) {
+	ids := []string{b.PaymentID}
+	topUps, err := FindTopUpPaymentIDs(b.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids = append(ids, topUps...)
+
+	payments := make([]bookingPayment, 0, len(ids))
+	for i, id := range ids {
+		ref := reference
+		if i > 0 {
+			ref = reference + ":" + id
+		}
+		captured, err := capturedAmount(id)
+		if err != nil {
+			return nil, err
+		}
+		// A repeated call for the same refund must see the same room, so its own refund is left out
+		taken, err := refund.TotalOnPayment(id, ref)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, bookingPayment{PaymentID: id, Reference: ref, Refundable: money.Max(0, captured-taken)})
+	}
+	return payments, nil
+}
+
+// capturedAmount is what a payment captured, from its recorded attempt or else from Razorpay
+func capturedAmount(paymentID string) (money.Money, error) {
+	amount, err := FindCapturedAmount(paymentID)
+	if err != sql.ErrNoRows {
+		return amount, err
+	}
+	p, err := gateway.FetchPayment(paymentID)
+	if err != nil {
+		return 0, err
+	}
+	if p.Status != gateway.PaymentCaptured && p.Status != gateway.PaymentRefunded {
+		return 0, nil
+	}
+	return p.Amount, nil
+}
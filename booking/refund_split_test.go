@@ -0,0 +1,89 @@
+// booking/refund_split_test.go
+package booking
+
+import (
+	"testing"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+func rupees(r int64) money.Money {
+	return money.FromPaise(r * 100)
+}
+
+func expectParts(t *testing.T, got []refundPart, want ...refundPart) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d parts %+v, want %+v", len(got), got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("part %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// A ₹1000 booking moved to a ₹1300 slot: ₹300 is paid as a top-up on its own payment,
+// and a later cancellation has to go back against both payments
+func TestRefundAfterRescheduleTopUp(t *testing.T) {
+	b := &Booking{ID: 12, PaymentID: "pay_checkout", TotalPrice: rupees(1300)}
+	payments := []bookingPayment{
+		{PaymentID: "pay_checkout", Reference: "cancel:12", Refundable: rupees(1000)},
+		{PaymentID: "pay_topup", Reference: "cancel:12:pay_topup", Refundable: rupees(300)},
+	}
+
+	parts, walletPart := divideRefund(b, b.TotalPrice, payments)
+	expectParts(t, parts,
+		refundPart{PaymentID: "pay_topup", Reference: "cancel:12:pay_topup", Amount: rupees(300)},
+		refundPart{PaymentID: "pay_checkout", Reference: "cancel:12", Amount: rupees(1000)},
+	)
+	if walletPart != 0 {
+		t.Fatalf("nothing should go to the wallet, got %s", walletPart)
+	}
+
+	// A 50% tier takes the top-up first and the rest from the checkout
+	parts, walletPart = divideRefund(b, b.TotalPrice.Percent(50), payments)
+	expectParts(t, parts,
+		refundPart{PaymentID: "pay_topup", Reference: "cancel:12:pay_topup", Amount: rupees(300)},
+		refundPart{PaymentID: "pay_checkout", Reference: "cancel:12", Amount: rupees(350)},
+	)
+	if walletPart != 0 {
+		t.Fatalf("nothing should go to the wallet, got %s", walletPart)
+	}
+}
+
+func TestRefundCappedByEarlierRefunds(t *testing.T) {
+	// Moved to a cheaper slot (₹200 back on the checkout), then to a dearer one (₹500 top-up)
+	b := &Booking{ID: 7, PaymentID: "pay_checkout", TotalPrice: rupees(1300)}
+	payments := []bookingPayment{
+		{PaymentID: "pay_checkout", Reference: "cancel:7", Refundable: rupees(1000 - 200)},
+		{PaymentID: "pay_topup", Reference: "cancel:7:pay_topup", Refundable: rupees(500)},
+	}
+	parts, walletPart := divideRefund(b, b.TotalPrice, payments)
+	expectParts(t, parts,
+		refundPart{PaymentID: "pay_topup", Reference: "cancel:7:pay_topup", Amount: rupees(500)},
+		refundPart{PaymentID: "pay_checkout", Reference: "cancel:7", Amount: rupees(800)},
+	)
+	if walletPart != 0 {
+		t.Fatalf("nothing should go to the wallet, got %s", walletPart)
+	}
+
+	// Part of the checkout was refunded by hand at Razorpay: the rest can't go back there
+	payments[0].Refundable = rupees(600)
+	parts, walletPart = divideRefund(b, b.TotalPrice, payments)
+	if len(parts) != 2 || parts[1].Amount != rupees(600) || walletPart != rupees(200) {
+		t.Fatalf("got %+v and %s to the wallet", parts, walletPart)
+	}
+}
+
+func TestRefundWithWalletShare(t *testing.T) {
+	// ₹400 of a ₹1000 booking came from the wallet; it goes back there
+	b := &Booking{ID: 3, PaymentID: "pay_checkout", TotalPrice: rupees(1000), WalletAmount: rupees(400)}
+	payments := []bookingPayment{{PaymentID: "pay_checkout", Reference: "cancel:3", Refundable: rupees(600)}}
+
+	parts, walletPart := divideRefund(b, b.TotalPrice, payments)
+	expectParts(t, parts, refundPart{PaymentID: "pay_checkout", Reference: "cancel:3", Amount: rupees(600)})
+	if walletPart != rupees(400) {
+		t.Fatalf("wallet share: got %s, want %s", walletPart, rupees(400))
+	}
+}
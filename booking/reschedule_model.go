@@ -0,0 +1,43 @@
+// booking/reschedule_model.go
+package booking
+
+import (
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// RescheduleRequest is the body for moving a booking to another slot
+type RescheduleRequest struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+	CourtID   int64     `json:"court_id"` // 0 = keep the current court
+}
+
+// BookingReschedule is one completed move, kept for the booking's history
+type BookingReschedule struct {
+	ID              int64       `json:"id"`
+	BookingID       int64       `json:"booking_id"`
+	OldStartTime    time.Time   `json:"old_start_time"`
+	OldEndTime      time.Time   `json:"old_end_time"`
+	NewStartTime    time.Time   `json:"new_start_time"`
+	NewEndTime      time.Time   `json:"new_end_time"`
+	OldCourtID      int64       `json:"old_court_id,omitempty"`
+	NewCourtID      int64       `json:"new_court_id,omitempty"`
+	PriceDifference money.Money `json:"price_difference"`     // > 0 charged, < 0 refunded
+	PaymentID       string      `json:"payment_id,omitempty"` // Top-up payment when the new slot costs more
+	RefundStatus    string      `json:"refund_status"`        // 'none', 'refunded' or 'failed'
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// RescheduleResult tells the client whether the move is done or waits for a top-up payment
+type RescheduleResult struct {
+	Booking         *Booking    `json:"booking"`
+	PriceDifference money.Money `json:"price_difference"`
+	PaymentRequired bool        `json:"payment_required"`
+	// When a payment is required the new slot is held under this ID; pay it through
+	// /payment/create-order/:id and the original booking moves once the payment is verified
+	HoldBookingID int64       `json:"hold_booking_id,omitempty"`
+	HoldExpiresAt *time.Time  `json:"hold_expires_at,omitempty"`
+	RefundAmount  money.Money `json:"refund_amount,omitempty"`
+}
@@ -0,0 +1,217 @@
+// booking/reschedule_repository.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/settlement"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// CreateRescheduleHold holds the new slot while the player pays the difference.
+// The booking being moved doesn't count as a conflict, so a booking can shift by half a slot.
+func CreateRescheduleHold(hold *Booking) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting reschedule transaction:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockVenueForBooking(tx, hold.VenueID); err != nil {
+		return err
+	}
+
+	count, err := countOverlappingExcept(tx, hold.VenueID, hold.CourtID, hold.StartTime, hold.EndTime, hold.RescheduleOf)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSlotUnavailable
+	}
+
+	if err := insertBooking(tx, hold); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ApplyReschedule moves the booking to `moved`'s slot and price in one transaction.
+// The slot is checked again under the venue lock; holdID (if any) is closed in the same step.
+func ApplyReschedule(original *Booking, moved *Booking, holdID int64, record *BookingReschedule) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting reschedule transaction:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockVenueForBooking(tx, original.VenueID); err != nil {
+		return err
+	}
+
+	// 1. The new slot must still be free (ignoring this booking and its own hold)
+	count, err := countOverlappingExcept(tx, original.VenueID, moved.CourtID, moved.StartTime, moved.EndTime, original.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSlotUnavailable
+	}
+
+	// 2. Move the booking (same ID, so the ticket stays valid)
+	breakdown, err := encodeBreakdown(moved.PriceBreakdown)
+	if err != nil {
+		return err
+	}
+	var courtID sql.NullInt64
+	if moved.CourtID != 0 {
+		courtID = sql.NullInt64{Int64: moved.CourtID, Valid: true}
+	}
+	result, err := tx.Exec(`
+		UPDATE bookings
+		SET start_time = ?, end_time = ?, court_id = ?, total_price = ?, price_breakdown = ?,
+		    reschedule_count = reschedule_count + 1
+		WHERE id = ? AND status IN ('pending', 'confirmed')
+	`, moved.StartTime, moved.EndTime, courtID, moved.TotalPrice, breakdown, original.ID)
+	if err != nil {
+		log.Println("Error moving booking:", err)
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.New("booking can no longer be rescheduled")
+	}
+
+	// 3. Close the temporary hold
+	if holdID != 0 {
+		reason := fmt.Sprintf("booking #%d moved to this slot", original.ID)
+		if _, err := transitionTx(tx, holdID, StatusPending, StatusCanceled, SystemActor, reason); err != nil {
+			log.Println("Error closing reschedule hold:", err)
+			return err
+		}
+	}
+
+	// 4. History
+	if err := insertReschedule(tx, record); err != nil {
+		return err
+	}
+
+	// 5. A paid booking that changed price moves the owner's ledger by the difference
+	if original.Status == StatusConfirmed && moved.TotalPrice != original.TotalPrice {
+		delta := moved.TotalPrice - original.TotalPrice
+		err := settlement.RecordSaleTx(tx, settlement.Sale{
+			Type:           settlement.EntryAdjustment,
+			Reference:      fmt.Sprintf("reschedule:%d", record.ID),
+			VenueID:        original.VenueID,
+			BookingID:      original.ID,
+			Gross:          delta,
+			GatewayCharged: money.Max(delta, 0), // Top-ups are paid through Razorpay
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertReschedule(tx *sql.Tx, r *BookingReschedule) error {
+	var oldCourt, newCourt sql.NullInt64
+	if r.OldCourtID != 0 {
+		oldCourt = sql.NullInt64{Int64: r.OldCourtID, Valid: true}
+	}
+	if r.NewCourtID != 0 {
+		newCourt = sql.NullInt64{Int64: r.NewCourtID, Valid: true}
+	}
+	if r.RefundStatus == "" {
+		r.RefundStatus = "none"
+	}
+
+	query := `
+		INSERT INTO booking_reschedules (booking_id, old_start_time, old_end_time, new_start_time, new_end_time,
+		                                 old_court_id, new_court_id, price_difference, payment_id, refund_status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?)
+	`
+	result, err := tx.Exec(query,
+		r.BookingID, r.OldStartTime, r.OldEndTime, r.NewStartTime, r.NewEndTime,
+		oldCourt, newCourt, r.PriceDifference, r.PaymentID, r.RefundStatus,
+	)
+	if err != nil {
+		log.Println("Error recording reschedule:", err)
+		return err
+	}
+	r.ID, _ = result.LastInsertId()
+	r.CreatedAt = time.Now()
+	return nil
+}
+
+// UpdateRescheduleRefundStatus records the outcome of the refund for a cheaper slot
+func UpdateRescheduleRefundStatus(rescheduleID int64, status string) error {
+	_, err := db.DB.Exec(`UPDATE booking_reschedules SET refund_status = ? WHERE id = ?`, status, rescheduleID)
+	if err != nil {
+		log.Println("Error updating reschedule refund status:", err)
+	}
+	return err
+}
+
+// FindTopUpPaymentIDs lists the payments for moves of a booking to dearer slots, oldest first
+func FindTopUpPaymentIDs(bookingID int64) ([]string, error) {
+	rows, err := db.DB.Query(`
+		SELECT payment_id FROM booking_reschedules
+		WHERE booking_id = ? AND payment_id IS NOT NULL AND payment_id <> ''
+		ORDER BY id ASC
+	`, bookingID)
+	if err != nil {
+		log.Println("Error querying reschedule payments:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// FindReschedulesByBookingID lists the moves of a booking, oldest first
+func FindReschedulesByBookingID(bookingID int64) ([]BookingReschedule, error) {
+	query := `
+		SELECT id, booking_id, old_start_time, old_end_time, new_start_time, new_end_time,
+		       COALESCE(old_court_id, 0), COALESCE(new_court_id, 0), price_difference,
+		       COALESCE(payment_id, ''), refund_status, created_at
+		FROM booking_reschedules
+		WHERE booking_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+	rows, err := db.DB.Query(query, bookingID)
+	if err != nil {
+		log.Println("Error querying reschedules:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]BookingReschedule, 0)
+	for rows.Next() {
+		var r BookingReschedule
+		if err := rows.Scan(
+			&r.ID, &r.BookingID, &r.OldStartTime, &r.OldEndTime, &r.NewStartTime, &r.NewEndTime,
+			&r.OldCourtID, &r.NewCourtID, &r.PriceDifference, &r.PaymentID, &r.RefundStatus, &r.CreatedAt,
+		); err != nil {
+			log.Println("Error scanning reschedule:", err)
+			continue
+		}
+		list = append(list, r)
+	}
+	return list, nil
+}
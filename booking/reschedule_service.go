@@ -0,0 +1,238 @@
+// booking/reschedule_service.go
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/refund"
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// RescheduleBooking moves a booking to a new slot, keeping its ID and ticket.
+// Only the price difference changes hands: a cheaper slot is refunded straight away,
+// a dearer one is held until the player pays the top-up (see CompleteReschedule).
+func RescheduleBooking(bookingID int64, userID int64, req *RescheduleRequest) (*RescheduleResult, error) {
+	// 1. Fetch & authorize
+	b, err := FindBookingByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if b.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+	if b.RescheduleOf != 0 || b.BookingType == "block" || b.PackPurchaseID != 0 {
+		return nil, errors.New("this booking cannot be rescheduled")
+	}
+	if b.Status != "confirmed" && b.Status != "pending" {
+		return nil, errors.New("only upcoming bookings can be rescheduled")
+	}
+
+	// 2. Venue policy: how many moves, and how late
+	v, err := venue.GetVenueByID(b.VenueID)
+	if err != nil {
+		return nil, errors.New("venue not found")
+	}
+	if b.RescheduleCount >= v.MaxReschedules {
+		return nil, fmt.Errorf("this venue allows at most %d reschedule(s) per booking", v.MaxReschedules)
+	}
+	cutoff := time.Duration(v.RescheduleCutoffHours) * time.Hour
+	if time.Now().After(b.StartTime.Add(-cutoff)) {
+		return nil, fmt.Errorf("bookings can only be rescheduled up to %d hours before start", v.RescheduleCutoffHours)
+	}
+
+	// 3. Price the new slot (also validates court, opening hours and grid)
+	courtID := req.CourtID
+	if courtID == 0 {
+		courtID = b.CourtID
+	}
+	if req.StartTime.Equal(b.StartTime) && req.EndTime.Equal(b.EndTime) && courtID == b.CourtID {
+		return nil, errors.New("the new slot is the same as the current one")
+	}
+	if req.StartTime.Before(time.Now()) {
+		return nil, errors.New("cannot move a booking into the past")
+	}
+	quote, err := QuoteBooking(&CreateBookingRequest{
+		VenueID:   b.VenueID,
+		CourtID:   courtID,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A coupon discount stays with the booking when it moves
+	newPrice := money.Max(0, quote.Total-b.DiscountAmount)
+	moved := &Booking{
+		VenueID:        b.VenueID,
+		CourtID:        courtID,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		TotalPrice:     newPrice,
+		PriceBreakdown: quote.Items,
+	}
+	delta := newPrice - b.TotalPrice
+	result := &RescheduleResult{PriceDifference: delta}
+
+	// 4a. Dearer paid slot: hold it and wait for the top-up
+	if b.Status == "confirmed" && delta > 0 {
+		holdExpiresAt := time.Now().Add(HoldDuration)
+		hold := &Booking{
+			UserID:         b.UserID,
+			VenueID:        b.VenueID,
+			CourtID:        courtID,
+			StartTime:      req.StartTime,
+			EndTime:        req.EndTime,
+			TotalPrice:     delta, // the order for this hold charges only the difference
+			PriceBreakdown: quote.Items,
+			Status:         "pending",
+			HoldExpiresAt:  &holdExpiresAt,
+			RescheduleOf:   b.ID,
+		}
+		if err := CreateRescheduleHold(hold); err != nil {
+			if errors.Is(err, ErrSlotUnavailable) {
+				return nil, err
+			}
+			log.Println("Service error holding reschedule slot:", err)
+			return nil, errors.New("failed to reschedule booking")
+		}
+		result.Booking = b
+		result.PaymentRequired = true
+		result.HoldBookingID = hold.ID
+		result.HoldExpiresAt = hold.HoldExpiresAt
+		return result, nil
+	}
+
+	// 4b. Unpaid booking, or same/cheaper slot: move now
+	record := &BookingReschedule{
+		BookingID:       b.ID,
+		OldStartTime:    b.StartTime,
+		OldEndTime:      b.EndTime,
+		NewStartTime:    moved.StartTime,
+		NewEndTime:      moved.EndTime,
+		OldCourtID:      b.CourtID,
+		NewCourtID:      moved.CourtID,
+		PriceDifference: delta,
+	}
+	if err := ApplyReschedule(b, moved, 0, record); err != nil {
+		if errors.Is(err, ErrSlotUnavailable) {
+			return nil, err
+		}
+		log.Println("Service error applying reschedule:", err)
+		return nil, errors.New("failed to reschedule booking")
+	}
+
+	// 5. Refund the difference of a paid booking
+	// (whatever mix of gateway and wallet it was paid with)
+	if b.Status == "confirmed" && delta < 0 && (b.PaymentID != "" || b.WalletAmount > 0) {
+		// Gateway failures are retried by the refund package; only a refund that couldn't be recorded fails here
+		status := "refunded"
+		if err := refundRescheduleDifference(b, record.ID, -delta); err != nil {
+			log.Println("Reschedule refund failed:", err)
+			status = "failed"
+		} else {
+			result.RefundAmount = -delta
+		}
+		_ = UpdateRescheduleRefundStatus(record.ID, status)
+	}
+
+	// The old slot is free again
+	OfferReleasedSlot(b.VenueID, b.CourtID, b.StartTime, b.EndTime)
+
+	msg := fmt.Sprintf("Your booking was moved to %s.", moved.StartTime.In(venue.IST).Format("02 Jan 2006, 03:04 PM"))
+	if result.RefundAmount > 0 {
+		msg += fmt.Sprintf(" %s is being refunded to you.", result.RefundAmount.Format())
+	}
+	_ = notification.CreateNotification(b.UserID, msg, "success")
+
+	result.Booking, _ = FindBookingByID(b.ID)
+	return result, nil
+}
+
+// CompleteReschedule moves the original booking once the top-up for its hold has been paid.
+// If the slot was lost in the meantime (the hold expired) the top-up is refunded.
+func CompleteReschedule(holdID int64, paymentID string) error {
+	// The browser and the payment webhook both report the top-up; only the first one moves the booking
+	if applied, err := IsPaymentApplied(paymentID); err != nil || applied {
+		return err
+	}
+
+	hold, err := FindBookingByID(holdID)
+	if err != nil || hold.RescheduleOf == 0 {
+		return errors.New("reschedule hold not found")
+	}
+	original, err := FindBookingByID(hold.RescheduleOf)
+	if err != nil {
+		return errors.New("booking not found")
+	}
+
+	moved := &Booking{
+		VenueID:        original.VenueID,
+		CourtID:        hold.CourtID,
+		StartTime:      hold.StartTime,
+		EndTime:        hold.EndTime,
+		TotalPrice:     original.TotalPrice + hold.TotalPrice,
+		PriceBreakdown: hold.PriceBreakdown,
+	}
+	record := &BookingReschedule{
+		BookingID:       original.ID,
+		OldStartTime:    original.StartTime,
+		OldEndTime:      original.EndTime,
+		NewStartTime:    hold.StartTime,
+		NewEndTime:      hold.EndTime,
+		OldCourtID:      original.CourtID,
+		NewCourtID:      hold.CourtID,
+		PriceDifference: hold.TotalPrice,
+		PaymentID:       paymentID,
+	}
+
+	err = ApplyReschedule(original, moved, hold.ID, record)
+	if err != nil {
+		if applied, _ := IsPaymentApplied(paymentID); applied {
+			return nil // Moved by the other report of the same payment
+		}
+		// Paid but the move can't happen: give the top-up back
+		log.Println("Reschedule could not be completed:", err)
+		_, refundErr := refund.Initiate(refund.Request{
+			Reference: fmt.Sprintf("reschedule-hold:%d", hold.ID),
+			BookingID: original.ID,
+			UserID:    original.UserID,
+			PaymentID: paymentID,
+			Amount:    hold.TotalPrice,
+			Reason:    "reschedule slot no longer available",
+		})
+		if refundErr != nil {
+			log.Println("Reschedule top-up refund failed:", refundErr)
+		}
+		_ = TransitionBooking(hold.ID, StatusCanceled, SystemActor, "reschedule slot no longer available")
+		_ = notification.CreateNotification(original.UserID, "We couldn't move your booking because the new slot is no longer available. The extra payment is being refunded.", "warning")
+		return err
+	}
+
+	OfferReleasedSlot(original.VenueID, original.CourtID, original.StartTime, original.EndTime)
+
+	msg := fmt.Sprintf("Payment received. Your booking was moved to %s.", hold.StartTime.In(venue.IST).Format("02 Jan 2006, 03:04 PM"))
+	_ = notification.CreateNotification(original.UserID, msg, "success")
+	return nil
+}
+
+// GetBookingReschedules returns the move history of a booking to its player, the venue owner or an admin
+func GetBookingReschedules(bookingID int64, userID int64, userRole string) ([]BookingReschedule, error) {
+	b, err := FindBookingByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if userRole != "admin" && b.UserID != userID {
+		isOwner, err := venue.IsVenueOwner(b.VenueID, userID)
+		if err != nil || !isOwner {
+			return nil, errors.New("unauthorized")
+		}
+	}
+	return FindReschedulesByBookingID(bookingID)
+}
@@ -0,0 +1,114 @@
+// booking/series_handler.go
+package booking
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewSeriesHandler handles POST /api/v1/bookings/series/preview
+func PreviewSeriesHandler(c *gin.Context) {
+	var req CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	preview, err := PreviewSeries(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// CreateSeriesHandler handles POST /api/v1/bookings/series
+func CreateSeriesHandler(c *gin.Context) {
+	var req CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	series, conflicts, err := CreateSeries(&req, userID)
+	if err != nil {
+		if errors.Is(err, ErrSeriesConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": conflicts})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"series": series, "skipped": conflicts})
+}
+
+// GetSeriesHandler handles GET /api/v1/bookings/series/:id
+func GetSeriesHandler(c *gin.Context) {
+	seriesID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	series, err := GetSeriesDetails(seriesID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// CancelSeriesHandler handles PATCH /api/v1/bookings/series/:id/cancel
+func CancelSeriesHandler(c *gin.Context) {
+	seriesID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	canceled, err := CancelSeries(seriesID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Series canceled", "canceled_occurrences": canceled})
+}
+
+// SkipOccurrenceHandler handles PATCH /api/v1/bookings/series/:id/occurrences/:bookingId/skip
+func SkipOccurrenceHandler(c *gin.Context) {
+	seriesID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+	bookingID, err := strconv.ParseInt(c.Param("bookingId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if err := SkipSeriesOccurrence(seriesID, bookingID, userID, userRole); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Occurrence skipped"})
+}
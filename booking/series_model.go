@@ -0,0 +1,65 @@
+// booking/series_model.go
+package booking
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// MaxSeriesOccurrences caps how far a single series can expand
+const MaxSeriesOccurrences = 52
+
+// ErrSeriesConflict is returned when some occurrences of a series cannot be booked
+var ErrSeriesConflict = errors.New("some occurrences of this series are not available")
+
+// BookingSeries is a recurring booking (e.g. every Tuesday 7-8 PM) that expands into Booking rows
+type BookingSeries struct {
+	ID              int64      `json:"id"`
+	UserID          int64      `json:"user_id"`
+	VenueID         int64      `json:"venue_id"`
+	CourtID         int64      `json:"court_id,omitempty"`
+	Frequency       string     `json:"frequency"`  // 'daily' or 'weekly'
+	Interval        int        `json:"interval"`   // every N days/weeks
+	StartTime       time.Time  `json:"start_time"` // first occurrence
+	EndTime         time.Time  `json:"end_time"`
+	UntilDate       *time.Time `json:"until_date,omitempty"`
+	OccurrenceCount int        `json:"occurrence_count,omitempty"`
+	Status          string     `json:"status"` // 'active' or 'canceled'
+	CreatedAt       time.Time  `json:"created_at"`
+	Occurrences     []Booking  `json:"occurrences,omitempty"`
+}
+
+// CreateSeriesRequest is the body for previewing/creating a series.
+// Either Until (YYYY-MM-DD, inclusive) or Count must be given.
+type CreateSeriesRequest struct {
+	VenueID       int64     `json:"venue_id" binding:"required"`
+	CourtID       int64     `json:"court_id"`
+	StartTime     time.Time `json:"start_time" binding:"required"`
+	EndTime       time.Time `json:"end_time" binding:"required"`
+	Frequency     string    `json:"frequency" binding:"required"`
+	Interval      int       `json:"interval"`
+	Until         string    `json:"until"`
+	Count         int       `json:"count"`
+	SkipConflicts bool      `json:"skip_conflicts"` // Book the free occurrences and leave out the rest
+}
+
+// SeriesOccurrence is one expanded date of a series
+type SeriesOccurrence struct {
+	StartTime time.Time   `json:"start_time"`
+	EndTime   time.Time   `json:"end_time"`
+	Price     money.Money `json:"price"`
+	Available bool        `json:"available"`
+	// Breakdown is the per-rate split of Price (weekday and weekend dates can differ)
+	Breakdown []venue.PriceLineItem `json:"price_breakdown,omitempty"`
+}
+
+// SeriesPreview is returned before committing so the client can show conflicts
+type SeriesPreview struct {
+	Occurrences []SeriesOccurrence `json:"occurrences"`
+	Conflicts   []SeriesOccurrence `json:"conflicts"`
+	TotalPrice  money.Money        `json:"total_price"` // Sum of the available occurrences
+}
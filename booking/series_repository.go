@@ -0,0 +1,281 @@
+// booking/series_repository.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// CreateSeriesWithOccurrences saves the series and its pending occurrences in one transaction.
+// Every occurrence is checked under the venue lock; conflicting ones are returned and, unless
+// skipConflicts is set, nothing is written.
+func CreateSeriesWithOccurrences(series *BookingSeries, occurrences []SeriesOccurrence, skipConflicts bool, holdExpiresAt time.Time) ([]SeriesOccurrence, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting series transaction:", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockVenueForBooking(tx, series.VenueID); err != nil {
+		return nil, err
+	}
+
+	// 1. Check every occurrence
+	conflicts := make([]SeriesOccurrence, 0)
+	free := make([]SeriesOccurrence, 0, len(occurrences))
+	for _, occ := range occurrences {
+		count, err := countOverlapping(tx, series.VenueID, series.CourtID, occ.StartTime, occ.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			occ.Available = false
+			conflicts = append(conflicts, occ)
+			continue
+		}
+		free = append(free, occ)
+	}
+
+	if len(conflicts) > 0 && !skipConflicts {
+		return conflicts, ErrSeriesConflict
+	}
+	if len(free) == 0 {
+		return conflicts, ErrSeriesConflict
+	}
+
+	// 2. Insert the series row
+	var until sql.NullTime
+	if series.UntilDate != nil {
+		until = sql.NullTime{Time: *series.UntilDate, Valid: true}
+	}
+	var count, courtID sql.NullInt64
+	if series.OccurrenceCount > 0 {
+		count = sql.NullInt64{Int64: int64(series.OccurrenceCount), Valid: true}
+	}
+	if series.CourtID != 0 {
+		courtID = sql.NullInt64{Int64: series.CourtID, Valid: true}
+	}
+
+	query := `
+		INSERT INTO booking_series (user_id, venue_id, court_id, frequency, interval_count, start_time, end_time, until_date, occurrence_count, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'active')
+	`
+	result, err := tx.Exec(query,
+		series.UserID, series.VenueID, courtID, series.Frequency, series.Interval,
+		series.StartTime, series.EndTime, until, count,
+	)
+	if err != nil {
+		log.Println("Error inserting booking series:", err)
+		return nil, err
+	}
+	series.ID, _ = result.LastInsertId()
+	series.Status = "active"
+
+	// 3. Insert one pending booking per free occurrence
+	series.Occurrences = make([]Booking, 0, len(free))
+	for _, occ := range free {
+		b := Booking{
+			UserID:         series.UserID,
+			VenueID:        series.VenueID,
+			CourtID:        series.CourtID,
+			StartTime:      occ.StartTime,
+			EndTime:        occ.EndTime,
+			TotalPrice:     occ.Price,
+			PriceBreakdown: occ.Breakdown,
+			Status:         "pending",
+			HoldExpiresAt:  &holdExpiresAt,
+			SeriesID:       series.ID,
+		}
+		if err := insertBooking(tx, &b); err != nil {
+			return nil, err
+		}
+		series.Occurrences = append(series.Occurrences, b)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+// FindSeriesByID fetches a series header (without occurrences)
+func FindSeriesByID(seriesID int64) (*BookingSeries, error) {
+	query := `
+		SELECT id, user_id, venue_id, COALESCE(court_id, 0), frequency, interval_count, start_time, end_time,
+		       until_date, COALESCE(occurrence_count, 0), status, created_at
+		FROM booking_series
+		WHERE id = ?
+	`
+	var s BookingSeries
+	var until sql.NullTime
+	err := db.DB.QueryRow(query, seriesID).Scan(
+		&s.ID, &s.UserID, &s.VenueID, &s.CourtID, &s.Frequency, &s.Interval, &s.StartTime, &s.EndTime,
+		&until, &s.OccurrenceCount, &s.Status, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if until.Valid {
+		s.UntilDate = &until.Time
+	}
+	return &s, nil
+}
+
+// FindBookingsBySeriesID fetches every occurrence of a series in date order
+func FindBookingsBySeriesID(seriesID int64) ([]Booking, error) {
+	query := `
+		SELECT id, user_id, venue_id, COALESCE(court_id, 0), start_time, end_time, total_price, status, created_at,
+		       COALESCE(razorpay_payment_id, ''), hold_expires_at
+		FROM bookings
+		WHERE series_id = ?
+		ORDER BY start_time ASC
+	`
+	rows, err := db.DB.Query(query, seriesID)
+	if err != nil {
+		log.Println("Error querying series occurrences:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := make([]Booking, 0)
+	for rows.Next() {
+		var b Booking
+		var holdExpiresAt sql.NullTime
+		if err := rows.Scan(
+			&b.ID, &b.UserID, &b.VenueID, &b.CourtID, &b.StartTime, &b.EndTime, &b.TotalPrice,
+			&b.Status, &b.CreatedAt, &b.PaymentID, &holdExpiresAt,
+		); err != nil {
+			log.Println("Error scanning series occurrence:", err)
+			continue
+		}
+		if holdExpiresAt.Valid {
+			b.HoldExpiresAt = &holdExpiresAt.Time
+		}
+		b.SeriesID = seriesID
+		bookings = append(bookings, b)
+	}
+	return bookings, nil
+}
+
+// UpdateSeriesStatus marks a whole series as 'active' or 'canceled'
+func UpdateSeriesStatus(seriesID int64, status string) error {
+	_, err := db.DB.Exec(`UPDATE booking_series SET status = ? WHERE id = ?`, status, seriesID)
+	if err != nil {
+		log.Println("Error updating series status:", err)
+	}
+	return err
+}
+
+// GetSeriesPayableAmount sums the occurrences that still hold their slot and are waiting for payment
+func GetSeriesPayableAmount(seriesID int64) (money.Money, int, error) {
+	query := `
+		SELECT COALESCE(SUM(total_price), 0), COUNT(*)
+		FROM bookings
+		WHERE series_id = ? AND status = 'pending' AND hold_expires_at > ?
+	`
+	var amount money.Money
+	var count int
+	err := db.DB.QueryRow(query, seriesID, time.Now()).Scan(&amount, &count)
+	if err != nil {
+		log.Println("Error calculating series amount:", err)
+		return 0, 0, err
+	}
+	return amount, count, nil
+}
+
+// ConfirmSeriesPayment confirms every held occurrence of a series with one payment.
+// If a hold ran out and its slot was taken meanwhile, the payment no longer buys the whole series:
+// every held occurrence is canceled and ErrHoldLost returned so the payment gets refunded.
+func ConfirmSeriesPayment(seriesID int64, paymentID string) (int64, error) {
+	var venueID int64
+	if err := db.DB.QueryRow(`SELECT venue_id FROM booking_series WHERE id = ?`, seriesID).Scan(&venueID); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Same lock as a new reservation, so nobody books a slot while it is checked
+	if err := lockVenueForBooking(tx, venueID); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(court_id, 0), start_time, end_time, hold_expires_at
+		FROM bookings WHERE series_id = ? AND status = 'pending' FOR UPDATE
+	`, seriesID)
+	if err != nil {
+		log.Println("Error confirming series payment:", err)
+		return 0, err
+	}
+	type heldSlot struct {
+		id, courtID   int64
+		start, end    time.Time
+		holdExpiresAt sql.NullTime
+	}
+	held := make([]heldSlot, 0)
+	for rows.Next() {
+		var h heldSlot
+		if err := rows.Scan(&h.id, &h.courtID, &h.start, &h.end, &h.holdExpiresAt); err == nil {
+			held = append(held, h)
+		}
+	}
+	rows.Close()
+
+	if len(held) == 0 {
+		return 0, errors.New("no pending occurrences to confirm")
+	}
+
+	now := time.Now()
+	ids := make([]int64, 0, len(held))
+	lost := false
+	for _, h := range held {
+		ids = append(ids, h.id)
+		if lost || !h.holdExpiresAt.Valid || h.holdExpiresAt.Time.After(now) {
+			continue
+		}
+		count, err := countOverlappingExcept(tx, venueID, h.courtID, h.start, h.end, h.id)
+		if err != nil {
+			return 0, err
+		}
+		lost = count > 0
+	}
+	if lost {
+		reason := "hold expired and a slot was taken before series payment " + paymentID + " arrived"
+		for _, id := range ids {
+			if _, err := transitionTx(tx, id, StatusPending, StatusCanceled, SystemActor, reason); err != nil {
+				return 0, err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, err
+		}
+		return 0, ErrHoldLost
+	}
+
+	reason := "series payment " + paymentID + " verified"
+	for _, id := range ids {
+		if _, err := transitionTx(tx, id, StatusPending, StatusConfirmed, SystemActor, reason); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(`UPDATE bookings SET razorpay_payment_id = ? WHERE id = ?`, paymentID, id); err != nil {
+			log.Println("Error confirming series payment:", err)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
@@ -0,0 +1,321 @@
+// booking/series_service.go
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// ExpandSeries turns a recurrence rule into the list of concrete occurrences (not yet checked)
+func ExpandSeries(req *CreateSeriesRequest) ([]SeriesOccurrence, error) {
+	venueToBook, err := venue.GetVenueByID(req.VenueID)
+	if err != nil {
+		return nil, errors.New("venue not found or not available for booking")
+	}
+
+	court, err := venue.ResolveCourt(req.VenueID, req.CourtID)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := req.EndTime.Sub(req.StartTime)
+	if duration <= 0 {
+		return nil, errors.New("end time must be after start time")
+	}
+	if req.StartTime.Before(time.Now().Add(-2 * time.Minute)) {
+		return nil, errors.New("cannot book a time slot in the past")
+	}
+	// Every occurrence has the same clock times, so checking the first one is enough
+	if err := venue.CheckBookable(venueToBook, req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+
+	if req.Interval <= 0 {
+		req.Interval = 1
+	}
+
+	var stepDays int
+	switch req.Frequency {
+	case "daily":
+		stepDays = req.Interval
+	case "weekly":
+		stepDays = 7 * req.Interval
+	default:
+		return nil, errors.New("frequency must be 'daily' or 'weekly'")
+	}
+
+	// Until is a calendar date (inclusive) in the same zone as the first occurrence
+	var until time.Time
+	if req.Until != "" {
+		until, err = time.ParseInLocation("2006-01-02", req.Until, req.StartTime.Location())
+		if err != nil {
+			return nil, errors.New("until must be a date in YYYY-MM-DD format")
+		}
+		until = until.AddDate(0, 0, 1)
+	}
+	if req.Until == "" && req.Count <= 0 {
+		return nil, errors.New("either 'until' or 'count' is required")
+	}
+
+	occurrences := make([]SeriesOccurrence, 0)
+	for i := 0; ; i++ {
+		start := req.StartTime.AddDate(0, 0, i*stepDays)
+		if req.Count > 0 && i >= req.Count {
+			break
+		}
+		if !until.IsZero() && !start.Before(until) {
+			break
+		}
+		if len(occurrences) >= MaxSeriesOccurrences {
+			return nil, fmt.Errorf("a series can have at most %d occurrences", MaxSeriesOccurrences)
+		}
+		// Each date is priced on its own (weekend/peak rules may differ)
+		quote, err := venue.QuotePrice(venueToBook, court, start, start.Add(duration))
+		if err != nil {
+			return nil, errors.New("failed to calculate price")
+		}
+		occurrences = append(occurrences, SeriesOccurrence{
+			StartTime: start,
+			EndTime:   start.Add(duration),
+			Price:     quote.Total,
+			Breakdown: quote.Items,
+		})
+	}
+
+	if len(occurrences) == 0 {
+		return nil, errors.New("the recurrence rule produces no occurrences")
+	}
+	return occurrences, nil
+}
+
+// PreviewSeries expands the rule and reports which occurrences are free, without booking anything
+func PreviewSeries(req *CreateSeriesRequest) (*SeriesPreview, error) {
+	occurrences, err := ExpandSeries(req)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &SeriesPreview{
+		Occurrences: make([]SeriesOccurrence, 0, len(occurrences)),
+		Conflicts:   make([]SeriesOccurrence, 0),
+	}
+	for _, occ := range occurrences {
+		available, err := IsSlotAvailable(req.VenueID, req.CourtID, occ.StartTime, occ.EndTime)
+		if err != nil {
+			return nil, errors.New("error checking slot availability")
+		}
+		occ.Available = available
+		preview.Occurrences = append(preview.Occurrences, occ)
+		if available {
+			preview.TotalPrice += occ.Price
+		} else {
+			preview.Conflicts = append(preview.Conflicts, occ)
+		}
+	}
+	return preview, nil
+}
+
+// CreateSeries books every occurrence as a pending hold, to be paid with one order.
+// On conflict it returns ErrSeriesConflict plus the conflicting occurrences.
+func CreateSeries(req *CreateSeriesRequest, userID int64) (*BookingSeries, []SeriesOccurrence, error) {
+	occurrences, err := ExpandSeries(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	series := &BookingSeries{
+		UserID:          userID,
+		VenueID:         req.VenueID,
+		CourtID:         req.CourtID,
+		Frequency:       req.Frequency,
+		Interval:        req.Interval,
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		OccurrenceCount: req.Count,
+	}
+	if req.Until != "" {
+		until, _ := time.ParseInLocation("2006-01-02", req.Until, req.StartTime.Location())
+		series.UntilDate = &until
+	}
+
+	conflicts, err := CreateSeriesWithOccurrences(series, occurrences, req.SkipConflicts, time.Now().Add(HoldDuration))
+	if err != nil {
+		if errors.Is(err, ErrSeriesConflict) {
+			return nil, conflicts, err
+		}
+		log.Println("Service error creating series:", err)
+		return nil, nil, errors.New("failed to create booking series")
+	}
+
+	return series, conflicts, nil
+}
+
+// seriesAccess tells whether the user may manage the series, and whether they act for the venue
+func seriesAccess(series *BookingSeries, userID int64, userRole string) (bool, bool) {
+	if userRole == "admin" {
+		return true, true
+	}
+	if series.UserID == userID {
+		return true, false
+	}
+	isOwner, err := venue.IsVenueOwner(series.VenueID, userID)
+	if err == nil && isOwner {
+		return true, true
+	}
+	return false, false
+}
+
+// GetSeriesDetails returns a series with all its occurrences
+func GetSeriesDetails(seriesID int64, userID int64, userRole string) (*BookingSeries, error) {
+	series, err := FindSeriesByID(seriesID)
+	if err != nil {
+		return nil, errors.New("series not found")
+	}
+	if allowed, _ := seriesAccess(series, userID, userRole); !allowed {
+		return nil, errors.New("unauthorized")
+	}
+
+	series.Occurrences, err = FindBookingsBySeriesID(seriesID)
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// cancelOccurrence applies the normal cancellation rules to one occurrence and returns the message
+// for the player. Players get the venue's cancellation policy, like a single booking; the venue
+// side cancels and refunds directly.
+func cancelOccurrence(b *Booking, byVenue bool, actor Actor) (string, error) {
+	if b.StartTime.Before(time.Now()) {
+		return "", errors.New("this occurrence has already started")
+	}
+	date := b.StartTime.In(venue.IST).Format("02 Jan 2006")
+
+	switch b.Status {
+	case "pending":
+		if err := TransitionBooking(b.ID, StatusCanceled, actor, "series occurrence canceled"); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Your %s session was canceled.", date), nil
+
+	case "confirmed":
+		if byVenue {
+			status, err := refundByVenue(b, actor, "series occurrence canceled by venue")
+			if err != nil {
+				return "", err
+			}
+			if status == StatusRefundRequested {
+				return fmt.Sprintf("Your %s session was canceled by the venue. Your refund of %s will be processed by the venue.", date, b.TotalPrice.Format()), nil
+			}
+			return fmt.Sprintf("Your %s session was canceled by the venue. %s is being refunded to you.", date, b.TotalPrice.Format()), nil
+		}
+
+		tiers, err := venue.FindCancellationTiersByVenueID(b.VenueID)
+		if err != nil {
+			return "", errors.New("could not load cancellation policy")
+		}
+		if len(tiers) > 0 {
+			msg, err := applyCancellationPolicy(b, tiers, RefundToSource)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Your %s session: %s", date, msg), nil
+		}
+
+		// No policy: the same 2-hour rule and owner approval as a single booking
+		if time.Now().After(b.StartTime.Add(-2 * time.Hour)) {
+			return "", errors.New("cannot cancel less than 2 hours before start")
+		}
+		if err := SetBookingRefund(b.ID, StatusRefundRequested, b.TotalPrice, RefundToSource, actor, "series occurrence canceled by player"); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Cancellation requested for your %s session. Waiting for venue owner approval for refund.", date), nil
+
+	default:
+		return "", errors.New("this occurrence cannot be canceled")
+	}
+}
+
+// SkipSeriesOccurrence cancels a single date of the series
+func SkipSeriesOccurrence(seriesID int64, bookingID int64, userID int64, userRole string) error {
+	series, err := FindSeriesByID(seriesID)
+	if err != nil {
+		return errors.New("series not found")
+	}
+	allowed, byVenue := seriesAccess(series, userID, userRole)
+	if !allowed {
+		return errors.New("unauthorized")
+	}
+
+	b, err := FindBookingByID(bookingID)
+	if err != nil || b.SeriesID != seriesID {
+		return errors.New("occurrence not found in this series")
+	}
+
+	msg, err := cancelOccurrence(b, byVenue, actorFor(userID, userRole))
+	if err != nil {
+		return err
+	}
+	onSlotReleased(b)
+
+	_ = notification.CreateNotification(series.UserID, msg, "warning")
+	return nil
+}
+
+// CancelSeries cancels every upcoming occurrence and closes the series.
+// Occurrences that cannot be canceled (already played, inside the cutoff) are left as they are.
+func CancelSeries(seriesID int64, userID int64, userRole string) (int, error) {
+	series, err := FindSeriesByID(seriesID)
+	if err != nil {
+		return 0, errors.New("series not found")
+	}
+	allowed, byVenue := seriesAccess(series, userID, userRole)
+	if !allowed {
+		return 0, errors.New("unauthorized")
+	}
+	if series.Status == "canceled" {
+		return 0, errors.New("series is already canceled")
+	}
+
+	occurrences, err := FindBookingsBySeriesID(seriesID)
+	if err != nil {
+		return 0, err
+	}
+
+	canceled := 0
+	for i := range occurrences {
+		if _, err := cancelOccurrence(&occurrences[i], byVenue, actorFor(userID, userRole)); err == nil {
+			onSlotReleased(&occurrences[i])
+			canceled++
+		}
+	}
+
+	if err := UpdateSeriesStatus(seriesID, "canceled"); err != nil {
+		return canceled, err
+	}
+
+	_ = notification.CreateNotification(series.UserID, fmt.Sprintf("Your recurring booking was canceled (%d upcoming sessions).", canceled), "warning")
+	return canceled, nil
+}
+
+// ConfirmSeries confirms all held occurrences after the single series payment
+func ConfirmSeries(seriesID int64, paymentID string) error {
+	series, err := FindSeriesByID(seriesID)
+	if err != nil {
+		return errors.New("series not found")
+	}
+
+	confirmed, err := ConfirmSeriesPayment(seriesID, paymentID)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Payment successful! %d sessions of your recurring booking are confirmed.", confirmed)
+	_ = notification.CreateNotification(series.UserID, msg, "success")
+	return nil
+}
@@ -0,0 +1,47 @@
+// booking/settlement_service.go
+package booking
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/JkD004/playarena-backend/settlement"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// recordSettlementTx keeps the owner's ledger in step with a status change: a paid booking
+// is credited when it is confirmed and debited the recorded refund when it is refunded
+func recordSettlementTx(tx *sql.Tx, bookingID int64, from, to string) error {
+	if !(from == StatusPending && to == StatusConfirmed) && to != StatusRefunded {
+		return nil
+	}
+
+	var venueID int64
+	var totalPrice, walletAmount, refundAmount money.Money
+	var bookingType string
+	err := tx.QueryRow(`
+		SELECT venue_id, total_price, wallet_amount, COALESCE(refund_amount, 0), booking_type
+		FROM bookings WHERE id = ?
+	`, bookingID).Scan(&venueID, &totalPrice, &walletAmount, &refundAmount, &bookingType)
+	if err != nil {
+		log.Println("Error loading booking for settlement:", err)
+		return err
+	}
+	if bookingType == "block" || totalPrice <= 0 {
+		return nil
+	}
+
+	if to == StatusRefunded {
+		// Only what was actually given back is debited
+		return settlement.RecordRefundTx(tx, bookingID, refundAmount)
+	}
+	return settlement.RecordSaleTx(tx, settlement.Sale{
+		Type:           settlement.EntryBooking,
+		Reference:      settlement.BookingReference(bookingID),
+		VenueID:        venueID,
+		BookingID:      bookingID,
+		Gross:          totalPrice,
+		GatewayCharged: totalPrice - walletAmount,
+	})
+}
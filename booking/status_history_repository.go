@@ -0,0 +1,53 @@
+// booking/status_history_repository.go
+package booking
+
+import (
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// insertStatusChange appends one entry to the booking's timeline (from = "" for the creation entry)
+func insertStatusChange(ex sqlExecer, bookingID int64, from, to string, actor Actor, reason string) error {
+	query := `
+		INSERT INTO booking_status_history (booking_id, from_status, to_status, actor_user_id, actor_role, reason)
+		VALUES (?, NULLIF(?, ''), ?, NULLIF(?, 0), ?, NULLIF(?, ''))
+	`
+	role := actor.Role
+	if role == "" {
+		role = SystemActor.Role
+	}
+	_, err := ex.Exec(query, bookingID, from, to, actor.UserID, role, reason)
+	if err != nil {
+		log.Println("Error recording booking status change:", err)
+	}
+	return err
+}
+
+// FindStatusHistoryByBookingID returns a booking's timeline, oldest first
+func FindStatusHistoryByBookingID(bookingID int64) ([]StatusChange, error) {
+	query := `
+		SELECT id, booking_id, COALESCE(from_status, ''), to_status, COALESCE(actor_user_id, 0), actor_role,
+		       COALESCE(reason, ''), created_at
+		FROM booking_status_history
+		WHERE booking_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+	rows, err := db.DB.Query(query, bookingID)
+	if err != nil {
+		log.Println("Error querying booking timeline:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]StatusChange, 0)
+	for rows.Next() {
+		var h StatusChange
+		if err := rows.Scan(&h.ID, &h.BookingID, &h.FromStatus, &h.ToStatus, &h.ActorUserID, &h.ActorRole, &h.Reason, &h.CreatedAt); err != nil {
+			log.Println("Error scanning booking timeline:", err)
+			continue
+		}
+		history = append(history, h)
+	}
+	return history, nil
+}
@@ -0,0 +1,23 @@
+// booking/status_history_service.go
+package booking
+
+import (
+	"errors"
+
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// GetBookingTimeline returns the status history of a booking to its player, the venue owner or an admin
+func GetBookingTimeline(bookingID int64, userID int64, userRole string) ([]StatusChange, error) {
+	b, err := FindBookingByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if userRole != "admin" && b.UserID != userID {
+		isOwner, err := venue.IsVenueOwner(b.VenueID, userID)
+		if err != nil || !isOwner {
+			return nil, errors.New("unauthorized")
+		}
+	}
+	return FindStatusHistoryByBookingID(bookingID)
+}
@@ -0,0 +1,62 @@
+// booking/waitlist_handler.go
+package booking
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JoinWaitlistHandler handles POST /api/v1/waitlist
+func JoinWaitlistHandler(c *gin.Context) {
+	var req CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	entry, err := JoinWaitlist(&req, userID)
+	if err != nil {
+		if errors.Is(err, ErrSlotStillAvailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetMyWaitlistHandler handles GET /api/v1/waitlist/mine
+func GetMyWaitlistHandler(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	entries, err := GetUserWaitlist(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch waitlist"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// LeaveWaitlistHandler handles DELETE /api/v1/waitlist/:id
+func LeaveWaitlistHandler(c *gin.Context) {
+	entryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waitlist ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	if err := LeaveWaitlist(entryID, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from waitlist"})
+}
@@ -0,0 +1,24 @@
+// booking/waitlist_model.go
+package booking
+
+import "time"
+
+// WaitlistOfferDuration is how long a waitlisted player has to pay for a released slot
+const WaitlistOfferDuration = 15 * time.Minute
+
+// WaitlistEntry is a player waiting for a taken slot.
+// Status: 'waiting' -> 'offered' (slot held for them) -> 'claimed' (paid) or 'expired';
+// 'canceled' when the player leaves the list.
+type WaitlistEntry struct {
+	ID             int64      `json:"id"`
+	UserID         int64      `json:"user_id"`
+	VenueID        int64      `json:"venue_id"`
+	VenueName      string     `json:"venue_name,omitempty"` // Added for joins
+	CourtID        int64      `json:"court_id,omitempty"`
+	StartTime      time.Time  `json:"start_time"`
+	EndTime        time.Time  `json:"end_time"`
+	Status         string     `json:"status"`
+	OfferBookingID int64      `json:"offer_booking_id,omitempty"` // The pending booking holding the slot for them
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,162 @@
+// booking/waitlist_repository.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// CreateWaitlistEntry adds a player to the end of the waitlist
+func CreateWaitlistEntry(entry *WaitlistEntry) error {
+	var courtID sql.NullInt64
+	if entry.CourtID != 0 {
+		courtID = sql.NullInt64{Int64: entry.CourtID, Valid: true}
+	}
+
+	query := `
+		INSERT INTO waitlist_entries (user_id, venue_id, court_id, start_time, end_time, status)
+		VALUES (?, ?, ?, ?, ?, 'waiting')
+	`
+	result, err := db.DB.Exec(query, entry.UserID, entry.VenueID, courtID, entry.StartTime, entry.EndTime)
+	if err != nil {
+		log.Println("Error inserting waitlist entry:", err)
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	entry.ID = id
+	entry.Status = "waiting"
+	return nil
+}
+
+// HasOpenWaitlistEntry tells whether the user is already waiting (or holding an offer) for this exact slot
+func HasOpenWaitlistEntry(userID, venueID, courtID int64, startTime, endTime time.Time) (bool, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM waitlist_entries
+		WHERE user_id = ? AND venue_id = ? AND COALESCE(court_id, 0) = ?
+		AND start_time = ? AND end_time = ? AND status IN ('waiting', 'offered')
+	`
+	err := db.DB.QueryRow(query, userID, venueID, courtID, startTime, endTime).Scan(&count)
+	if err != nil {
+		log.Println("Error checking waitlist:", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindWaitingEntriesForRelease returns the waiting entries that overlap a released range, first come first served.
+// A released whole-venue booking (court 0) frees every court.
+func FindWaitingEntriesForRelease(venueID, courtID int64, startTime, endTime time.Time) ([]WaitlistEntry, error) {
+	query := `
+		SELECT id, user_id, venue_id, COALESCE(court_id, 0), start_time, end_time, status, created_at
+		FROM waitlist_entries
+		WHERE venue_id = ? AND status = 'waiting'
+		AND start_time < ? AND end_time > ?
+		AND start_time > ?
+		AND (? = 0 OR court_id = ?)
+		ORDER BY created_at ASC, id ASC
+	`
+	rows, err := db.DB.Query(query, venueID, endTime, startTime, time.Now(), courtID, courtID)
+	if err != nil {
+		log.Println("Error querying waitlist:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]WaitlistEntry, 0)
+	for rows.Next() {
+		var e WaitlistEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.VenueID, &e.CourtID, &e.StartTime, &e.EndTime, &e.Status, &e.CreatedAt); err != nil {
+			log.Println("Error scanning waitlist entry:", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// FindWaitlistByUserID lists a player's entries, newest first
+func FindWaitlistByUserID(userID int64) ([]WaitlistEntry, error) {
+	query := `
+		SELECT w.id, w.user_id, w.venue_id, v.name, COALESCE(w.court_id, 0), w.start_time, w.end_time, w.status,
+		       COALESCE(w.offer_booking_id, 0), w.offer_expires_at, w.created_at
+		FROM waitlist_entries w
+		JOIN venues v ON w.venue_id = v.id
+		WHERE w.user_id = ?
+		ORDER BY w.created_at DESC
+	`
+	rows, err := db.DB.Query(query, userID)
+	if err != nil {
+		log.Println("Error querying user waitlist:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]WaitlistEntry, 0)
+	for rows.Next() {
+		var e WaitlistEntry
+		var offerExpiresAt sql.NullTime
+		if err := rows.Scan(
+			&e.ID, &e.UserID, &e.VenueID, &e.VenueName, &e.CourtID, &e.StartTime, &e.EndTime, &e.Status,
+			&e.OfferBookingID, &offerExpiresAt, &e.CreatedAt,
+		); err != nil {
+			log.Println("Error scanning waitlist entry:", err)
+			continue
+		}
+		if offerExpiresAt.Valid {
+			e.OfferExpiresAt = &offerExpiresAt.Time
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// MarkWaitlistOffered records the hold created for this entry
+func MarkWaitlistOffered(entryID, bookingID int64, expiresAt time.Time) error {
+	query := `
+		UPDATE waitlist_entries
+		SET status = 'offered', offer_booking_id = ?, offer_expires_at = ?
+		WHERE id = ? AND status = 'waiting'
+	`
+	_, err := db.DB.Exec(query, bookingID, expiresAt, entryID)
+	if err != nil {
+		log.Println("Error marking waitlist offer:", err)
+	}
+	return err
+}
+
+// UpdateWaitlistOfferStatus moves the entry holding this booking from 'offered' to 'claimed' or 'expired'
+func UpdateWaitlistOfferStatus(bookingID int64, newStatus string) (int64, error) {
+	result, err := db.DB.Exec(
+		`UPDATE waitlist_entries SET status = ? WHERE offer_booking_id = ? AND status = 'offered'`,
+		newStatus, bookingID,
+	)
+	if err != nil {
+		log.Println("Error updating waitlist offer:", err)
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
+
+// CancelWaitlistEntry removes a player's entry if it is still waiting
+func CancelWaitlistEntry(entryID, userID int64) error {
+	result, err := db.DB.Exec(
+		`UPDATE waitlist_entries SET status = 'canceled' WHERE id = ? AND user_id = ? AND status = 'waiting'`,
+		entryID, userID,
+	)
+	if err != nil {
+		log.Println("Error canceling waitlist entry:", err)
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("waitlist entry not found or already offered")
+	}
+	return nil
+}
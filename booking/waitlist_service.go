@@ -0,0 +1,160 @@
+// booking/waitlist_service.go
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/coupon"
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/venue"
+)
+
+// ErrSlotStillAvailable is returned when someone tries to waitlist a slot they could just book
+var ErrSlotStillAvailable = errors.New("this slot is available, book it directly")
+
+// JoinWaitlist queues the player for a slot that is currently taken
+func JoinWaitlist(req *CreateBookingRequest, userID int64) (*WaitlistEntry, error) {
+	// Same checks as a booking: venue, court, opening hours and slot grid
+	if _, err := QuoteBooking(req); err != nil {
+		return nil, err
+	}
+	if req.StartTime.Before(time.Now()) {
+		return nil, errors.New("cannot waitlist a time slot in the past")
+	}
+
+	available, err := IsSlotAvailable(req.VenueID, req.CourtID, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, errors.New("error checking slot availability")
+	}
+	if available {
+		return nil, ErrSlotStillAvailable
+	}
+
+	exists, err := HasOpenWaitlistEntry(userID, req.VenueID, req.CourtID, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, errors.New("failed to join waitlist")
+	}
+	if exists {
+		return nil, errors.New("you are already on the waitlist for this slot")
+	}
+
+	entry := &WaitlistEntry{
+		UserID:    userID,
+		VenueID:   req.VenueID,
+		CourtID:   req.CourtID,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+	if err := CreateWaitlistEntry(entry); err != nil {
+		return nil, errors.New("failed to join waitlist")
+	}
+	return entry, nil
+}
+
+// GetUserWaitlist lists the player's waitlist entries
+func GetUserWaitlist(userID int64) ([]WaitlistEntry, error) {
+	return FindWaitlistByUserID(userID)
+}
+
+// LeaveWaitlist removes a waiting entry
+func LeaveWaitlist(entryID int64, userID int64) error {
+	return CancelWaitlistEntry(entryID, userID)
+}
+
+// ClaimWaitlistOffer marks the offer as used once its booking is paid
+func ClaimWaitlistOffer(bookingID int64) {
+	if _, err := UpdateWaitlistOfferStatus(bookingID, "claimed"); err != nil {
+		log.Println("Error claiming waitlist offer:", err)
+	}
+}
+
+// onSlotReleased runs after a booking stops occupying its slot (canceled, refunded, hold expired).
+// If the booking was an unclaimed waitlist offer, that offer has lapsed; either way the slot goes
+// to the next players in line.
+func onSlotReleased(b *Booking) {
+	coupon.Release(b.ID) // An unpaid coupon use no longer counts against the caps
+
+	expired, err := UpdateWaitlistOfferStatus(b.ID, "expired")
+	if err != nil {
+		log.Println("Error expiring waitlist offer:", err)
+	} else if expired > 0 {
+		_ = notification.CreateNotification(b.UserID, "Your waitlist offer has expired and the slot was passed on.", "warning")
+	}
+	OfferReleasedSlot(b.VenueID, b.CourtID, b.StartTime, b.EndTime)
+}
+
+// OfferReleasedSlot walks the waitlist for the freed range in order and holds the slot for
+// every entry that now fits. Entries that still overlap another booking stay 'waiting'.
+func OfferReleasedSlot(venueID, courtID int64, startTime, endTime time.Time) {
+	entries, err := FindWaitingEntriesForRelease(venueID, courtID, startTime, endTime)
+	if err != nil || len(entries) == 0 {
+		return
+	}
+
+	for i := range entries {
+		if err := offerToEntry(&entries[i]); err != nil && !errors.Is(err, ErrSlotUnavailable) {
+			log.Printf("Error offering slot to waitlist entry %d: %v\n", entries[i].ID, err)
+		}
+	}
+}
+
+func offerToEntry(entry *WaitlistEntry) error {
+	req := &CreateBookingRequest{
+		VenueID:   entry.VenueID,
+		CourtID:   entry.CourtID,
+		StartTime: entry.StartTime,
+		EndTime:   entry.EndTime,
+	}
+	quote, err := QuoteBooking(req)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(WaitlistOfferDuration)
+	offer := &Booking{
+		UserID:         entry.UserID,
+		VenueID:        entry.VenueID,
+		CourtID:        entry.CourtID,
+		StartTime:      entry.StartTime,
+		EndTime:        entry.EndTime,
+		TotalPrice:     quote.Total,
+		PriceBreakdown: quote.Items,
+		Status:         "pending",
+		HoldExpiresAt:  &expiresAt,
+	}
+	if err := ReserveSlot(offer); err != nil {
+		return err
+	}
+
+	if err := MarkWaitlistOffered(entry.ID, offer.ID, expiresAt); err != nil {
+		return err
+	}
+
+	venueName := "the venue"
+	if v, err := venue.GetVenueByID(entry.VenueID); err == nil {
+		venueName = v.Name
+	}
+	start := entry.StartTime.In(venue.IST)
+	msg := fmt.Sprintf(
+		"Good news! The %s slot at %s on %s is now held for you. Pay within %d minutes to claim it.",
+		start.Format("03:04 PM"), venueName, start.Format("02 Jan 2006"), int(WaitlistOfferDuration/time.Minute),
+	)
+	_ = notification.CreateNotification(entry.UserID, msg, "success")
+	return nil
+}
+
+// ReleaseExpiredHolds cancels the pending bookings whose hold ran out and offers
+// each freed slot to the waitlist. Returns how many holds were released.
+func ReleaseExpiredHolds(minutes int) (int, error) {
+	released, err := AutoCancelPendingBookings(minutes)
+	if err != nil {
+		return 0, err
+	}
+	for i := range released {
+		onSlotReleased(&released[i])
+	}
+	return len(released), nil
+}
@@ -0,0 +1,210 @@
+// booking/wallet_service.go
+package booking
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/refund"
+	"github.com/JkD004/playarena-backend/wallet"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// settleWalletHoldTx captures (confirmed) or gives back (canceled) the wallet part of a pending booking
+func settleWalletHoldTx(tx *sql.Tx, bookingID int64, to string) error {
+	var userID int64
+	var walletAmount money.Money
+	err := tx.QueryRow(`SELECT user_id, wallet_amount FROM bookings WHERE id = ?`, bookingID).Scan(&userID, &walletAmount)
+	if err != nil || walletAmount <= 0 {
+		return err
+	}
+	if to == StatusConfirmed {
+		return wallet.CaptureBookingTx(tx, bookingID, walletAmount)
+	}
+	return wallet.ReleaseBookingTx(tx, userID, bookingID, walletAmount)
+}
+
+// PayFromWallet puts the player's wallet balance towards an unpaid booking.
+// If the wallet covers everything the booking is confirmed; otherwise the rest is left for Razorpay.
+func PayFromWallet(bookingID int64, userID int64) (*Booking, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var ownerID int64
+	var status string
+	var totalPrice, walletAmount money.Money
+	var holdExpiresAt sql.NullTime
+	var rescheduleOf int64
+	err = tx.QueryRow(`
+		SELECT user_id, status, total_price, wallet_amount, hold_expires_at, COALESCE(reschedule_of, 0)
+		FROM bookings WHERE id = ? FOR UPDATE
+	`, bookingID).Scan(&ownerID, &status, &totalPrice, &walletAmount, &holdExpiresAt, &rescheduleOf)
+	if err != nil || ownerID != userID {
+		return nil, errors.New("booking not found")
+	}
+	// A reschedule top-up only completes through CompleteReschedule, which moves the original booking
+	if rescheduleOf != 0 {
+		return nil, errors.New("a reschedule top-up can't be paid from the wallet")
+	}
+	if status != StatusPending || (holdExpiresAt.Valid && time.Now().After(holdExpiresAt.Time)) {
+		return nil, errors.New("only an unpaid booking with an active hold can be paid")
+	}
+	if walletAmount > 0 {
+		return nil, errors.New("wallet balance was already applied to this booking")
+	}
+
+	taken, err := wallet.HoldForBookingTx(tx, userID, bookingID, totalPrice)
+	if err != nil {
+		if errors.Is(err, wallet.ErrInsufficientFunds) {
+			return nil, errors.New("your wallet balance is empty")
+		}
+		log.Println("Error paying from wallet:", err)
+		return nil, errors.New("failed to pay from wallet")
+	}
+	if _, err := tx.Exec(`UPDATE bookings SET wallet_amount = ? WHERE id = ?`, taken, bookingID); err != nil {
+		log.Println("Error saving wallet amount:", err)
+		return nil, err
+	}
+
+	fullyPaid := totalPrice-taken <= 0
+	if fullyPaid {
+		if _, err := transitionTx(tx, bookingID, StatusPending, StatusConfirmed, Actor{UserID: userID, Role: "player"}, "paid from wallet"); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if fullyPaid {
+		AfterPaymentConfirmed(bookingID)
+		_ = notification.CreateNotification(userID, "Payment successful! Your booking has been confirmed.", "success")
+	}
+	return FindBookingByID(bookingID)
+}
+
+// refundBooking pays a cancellation refund. To the wallet it is instant; to source the
+// Razorpay part goes back through Razorpay, split over the booking's checkout and any top-ups
+// (see splitRefund), and the part paid from the wallet returns to the wallet.
+// The Razorpay parts are tracked (and retried if they fail) by the refund package, which tells
+// the player once the bank has processed them. pending reports that such a part was sent, so
+// the booking waits in 'refund_initiated' until then (see finishRefund).
+func refundBooking(b *Booking, amount money.Money, method, reason string) (pending bool, err error) {
+	parts, walletPart, err := splitRefund(b, amount, method, cancelRefundReference(b.ID))
+	if err != nil {
+		return false, err
+	}
+
+	if err := initiateRefundParts(b, parts, reason); err != nil {
+		return false, err
+	}
+	if walletPart > 0 {
+		err := wallet.CreditRefund(b.UserID, b.ID, walletPart)
+		if err != nil && !errors.Is(err, wallet.ErrDuplicateTransaction) {
+			log.Println("Error crediting refund to wallet:", err)
+			return false, err
+		}
+	}
+	return len(parts) > 0, nil
+}
+
+// initiateRefundParts sends each part of a split refund to the refund package
+func initiateRefundParts(b *Booking, parts []refundPart, reason string) error {
+	for _, part := range parts {
+		_, err := refund.Initiate(refund.Request{
+			Reference: part.Reference,
+			BookingID: b.ID,
+			UserID:    b.UserID,
+			PaymentID: part.PaymentID,
+			Amount:    part.Amount,
+			Reason:    reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelRefundReference is the refund package reference of a booking's cancellation refund
+func cancelRefundReference(bookingID int64) string {
+	return fmt.Sprintf("cancel:%d", bookingID)
+}
+
+// finishRefund moves a booking whose refund was paid out by refundBooking on: to 'refunded' when
+// it all went to the wallet, otherwise to 'refund_initiated' until the bank processes the gateway
+// part (SettleProcessedRefunds). Returns the booking's new status.
+func finishRefund(bookingID int64, pending bool, actor Actor, reason string) (string, error) {
+	if !pending {
+		if err := TransitionBooking(bookingID, StatusRefunded, actor, reason); err != nil {
+			return "", err
+		}
+		return StatusRefunded, nil
+	}
+	if err := TransitionBooking(bookingID, StatusRefundInitiated, actor, reason); err != nil {
+		return "", err
+	}
+	// The processed event may have come in before the booking was waiting for it
+	if settled, err := settleProcessedRefund(bookingID); err != nil || settled {
+		return StatusRefunded, err
+	}
+	return StatusRefundInitiated, nil
+}
+
+// settleProcessedRefund marks a 'refund_initiated' booking refunded once every part of its
+// gateway refund is processed
+func settleProcessedRefund(bookingID int64) (bool, error) {
+	list, err := refund.FindByBookingID(bookingID)
+	if err != nil {
+		return false, err
+	}
+	reference := cancelRefundReference(bookingID)
+	var total money.Money
+	for _, r := range list {
+		if r.Reference != reference && !strings.HasPrefix(r.Reference, reference+":") {
+			continue // A reschedule difference or a late payment
+		}
+		if r.Status != refund.StatusProcessed {
+			return false, nil
+		}
+		total += r.Amount
+	}
+	if total == 0 {
+		return false, nil
+	}
+	err = TransitionBooking(bookingID, StatusRefunded, SystemActor, fmt.Sprintf("refund of %s processed", total))
+	if errors.Is(err, ErrIllegalTransition) {
+		return true, nil // Settled by the webhook meanwhile
+	}
+	return err == nil, err
+}
+
+// refundRescheduleDifference gives back what a move to a cheaper slot saved, split like refundBooking
+func refundRescheduleDifference(b *Booking, rescheduleID int64, amount money.Money) error {
+	parts, walletPart, err := splitRefund(b, amount, "", fmt.Sprintf("reschedule:%d", rescheduleID))
+	if err != nil {
+		return err
+	}
+
+	if err := initiateRefundParts(b, parts, "price difference after reschedule"); err != nil {
+		return err
+	}
+	if walletPart > 0 {
+		err := wallet.CreditRescheduleRefund(b.UserID, rescheduleID, walletPart)
+		if err != nil && !errors.Is(err, wallet.ErrDuplicateTransaction) {
+			log.Println("Error crediting reschedule refund to wallet:", err)
+			return err
+		}
+	}
+	return nil
+}
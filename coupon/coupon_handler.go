@@ -0,0 +1,77 @@
+// coupon/coupon_handler.go
+package coupon
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateCouponHandler handles POST /api/v1/coupons
+func CreateCouponHandler(c *gin.Context) {
+	var cp Coupon
+	if err := c.ShouldBindJSON(&cp); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if err := CreateNewCoupon(&cp, userID, userRole); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, cp)
+}
+
+// GetCouponsHandler handles GET /api/v1/coupons
+func GetCouponsHandler(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	coupons, err := GetCoupons(userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch coupons"})
+		return
+	}
+	c.JSON(http.StatusOK, coupons)
+}
+
+// DeleteCouponHandler handles DELETE /api/v1/coupons/:id (deactivates the code)
+func DeleteCouponHandler(c *gin.Context) {
+	couponID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if err := RemoveCoupon(couponID, userID, userRole); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Coupon deactivated"})
+}
+
+// GetCouponReportHandler handles GET /api/v1/coupons/:id/redemptions
+func GetCouponReportHandler(c *gin.Context) {
+	couponID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	report, err := GetCouponReport(couponID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,65 @@
+// coupon/coupon_model.go
+package coupon
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// ErrCouponInvalid covers every reason a code can't be used (unknown, expired, capped, restricted)
+var ErrCouponInvalid = errors.New("coupon cannot be applied")
+
+// Coupon is a promo code. Restrictions left at their zero value don't apply.
+type Coupon struct {
+	ID               int64       `json:"id"`
+	Code             string      `json:"code"` // Stored upper-case
+	Description      string      `json:"description,omitempty"`
+	DiscountType     string      `json:"discount_type"`              // 'percent' or 'flat'
+	DiscountValue    money.Money `json:"discount_value"`             // Rupees off, or for percent coupons the percentage itself (12.50 = 12.5%)
+	MaxDiscount      money.Money `json:"max_discount,omitempty"`     // Cap for percent coupons; 0 = no cap
+	MinOrderAmount   money.Money `json:"min_order_amount,omitempty"` // Booking total needed to use the code
+	ValidFrom        *time.Time  `json:"valid_from,omitempty"`
+	ValidUntil       *time.Time  `json:"valid_until,omitempty"`
+	MaxUses          int         `json:"max_uses,omitempty"`          // Across all players; 0 = unlimited
+	MaxUsesPerUser   int         `json:"max_uses_per_user,omitempty"` // 0 = unlimited
+	VenueID          int64       `json:"venue_id,omitempty"`          // 0 = every venue
+	SportCategory    string      `json:"sport_category,omitempty"`    // Empty = every sport
+	FirstBookingOnly bool        `json:"first_booking_only"`
+	CreatedBy        int64       `json:"created_by"`
+	IsActive         bool        `json:"is_active"`
+	UsedCount        int         `json:"used_count"` // Reserved + redeemed, filled in listings
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+// Redemption is one use of a coupon on a booking.
+// It is 'reserved' while the booking waits for payment, 'redeemed' once paid and
+// 'released' if the hold ran out, so unpaid holds don't eat the usage caps.
+type Redemption struct {
+	ID             int64       `json:"id"`
+	CouponID       int64       `json:"coupon_id"`
+	Code           string      `json:"code"`
+	UserID         int64       `json:"user_id"`
+	BookingID      int64       `json:"booking_id"`
+	DiscountAmount money.Money `json:"discount_amount"`
+	Status         string      `json:"status"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// Target is what a code is being applied to
+type Target struct {
+	UserID        int64
+	VenueID       int64
+	SportCategory string
+	Amount        money.Money // Price before the discount
+}
+
+// CouponReport sums up the redemptions of a coupon
+type CouponReport struct {
+	Coupon        *Coupon      `json:"coupon"`
+	Redeemed      int          `json:"redeemed"`
+	Reserved      int          `json:"reserved"`
+	TotalDiscount money.Money  `json:"total_discount"` // Redeemed only
+	Redemptions   []Redemption `json:"redemptions"`
+}
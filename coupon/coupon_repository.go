@@ -0,0 +1,224 @@
+// coupon/coupon_repository.go
+package coupon
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// couponColumns is shared by every SELECT so scanCoupon stays in sync
+const couponColumns = `
+	c.id, c.code, COALESCE(c.description, ''), c.discount_type, c.discount_value, c.max_discount, c.min_order_amount,
+	c.valid_from, c.valid_until, c.max_uses, c.max_uses_per_user, COALESCE(c.venue_id, 0), COALESCE(c.sport_category, ''),
+	c.first_booking_only, c.created_by, c.is_active, c.created_at,
+	(SELECT COUNT(*) FROM coupon_redemptions r WHERE r.coupon_id = c.id AND r.status IN ('reserved', 'redeemed'))
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCoupon(row rowScanner) (*Coupon, error) {
+	var c Coupon
+	var validFrom, validUntil sql.NullTime
+	err := row.Scan(
+		&c.ID, &c.Code, &c.Description, &c.DiscountType, &c.DiscountValue, &c.MaxDiscount, &c.MinOrderAmount,
+		&validFrom, &validUntil, &c.MaxUses, &c.MaxUsesPerUser, &c.VenueID, &c.SportCategory,
+		&c.FirstBookingOnly, &c.CreatedBy, &c.IsActive, &c.CreatedAt,
+		&c.UsedCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if validFrom.Valid {
+		c.ValidFrom = &validFrom.Time
+	}
+	if validUntil.Valid {
+		c.ValidUntil = &validUntil.Time
+	}
+	return &c, nil
+}
+
+// CreateCoupon inserts a new code
+func CreateCoupon(c *Coupon) error {
+	var venueID sql.NullInt64
+	if c.VenueID != 0 {
+		venueID = sql.NullInt64{Int64: c.VenueID, Valid: true}
+	}
+
+	query := `
+		INSERT INTO coupons (code, description, discount_type, discount_value, max_discount, min_order_amount,
+		                     valid_from, valid_until, max_uses, max_uses_per_user, venue_id, sport_category,
+		                     first_booking_only, created_by)
+		VALUES (?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?)
+	`
+	result, err := db.DB.Exec(query,
+		c.Code, c.Description, c.DiscountType, c.DiscountValue, c.MaxDiscount, c.MinOrderAmount,
+		c.ValidFrom, c.ValidUntil, c.MaxUses, c.MaxUsesPerUser, venueID, c.SportCategory,
+		c.FirstBookingOnly, c.CreatedBy,
+	)
+	if err != nil {
+		log.Println("Error inserting coupon:", err)
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	c.ID = id
+	c.IsActive = true
+	return nil
+}
+
+// FindCouponByCode looks a code up (codes are stored upper-case)
+func FindCouponByCode(code string) (*Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE c.code = ?`
+	return scanCoupon(db.DB.QueryRow(query, code))
+}
+
+// FindCouponByID fetches a single coupon
+func FindCouponByID(couponID int64) (*Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE c.id = ?`
+	return scanCoupon(db.DB.QueryRow(query, couponID))
+}
+
+// FindCoupons lists coupons, newest first. createdBy = 0 lists every coupon (admin).
+func FindCoupons(createdBy int64) ([]Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE (? = 0 OR c.created_by = ?) ORDER BY c.created_at DESC`
+	rows, err := db.DB.Query(query, createdBy, createdBy)
+	if err != nil {
+		log.Println("Error fetching coupons:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	coupons := make([]Coupon, 0)
+	for rows.Next() {
+		c, err := scanCoupon(rows)
+		if err != nil {
+			log.Println("Error scanning coupon:", err)
+			continue
+		}
+		coupons = append(coupons, *c)
+	}
+	return coupons, nil
+}
+
+// DeactivateCoupon stops a code from being used; past redemptions stay for reporting
+func DeactivateCoupon(couponID int64) error {
+	_, err := db.DB.Exec(`UPDATE coupons SET is_active = 0 WHERE id = ?`, couponID)
+	if err != nil {
+		log.Println("Error deactivating coupon:", err)
+	}
+	return err
+}
+
+// CountPaidBookingsByUser counts the bookings a player has ever paid for (for first-booking codes)
+func CountPaidBookingsByUser(userID int64) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM bookings
+		WHERE user_id = ? AND booking_type = 'booking'
+		AND status IN ('confirmed', 'present', 'absent', 'refund_requested', 'refund_initiated', 'refunded', 'refund_rejected')
+	`
+	if err := db.DB.QueryRow(query, userID).Scan(&count); err != nil {
+		log.Println("Error counting paid bookings:", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// countUses returns the live uses of a coupon overall and by one player
+func countUses(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, couponID, userID int64) (int, int, error) {
+	var total, perUser int
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN user_id = ? THEN 1 ELSE 0 END), 0)
+		FROM coupon_redemptions
+		WHERE coupon_id = ? AND status IN ('reserved', 'redeemed')
+	`
+	err := q.QueryRow(query, userID, couponID).Scan(&total, &perUser)
+	return total, perUser, err
+}
+
+// ReserveRedemption records a use of the coupon for a booking, re-checking the caps
+// under a row lock so two players can't take the last use at once.
+func ReserveRedemption(c *Coupon, userID, bookingID int64, discount money.Money) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err := tx.QueryRow(`SELECT id FROM coupons WHERE id = ? FOR UPDATE`, c.ID).Scan(&id); err != nil {
+		log.Println("Error locking coupon:", err)
+		return err
+	}
+
+	total, perUser, err := countUses(tx, c.ID, userID)
+	if err != nil {
+		log.Println("Error counting coupon uses:", err)
+		return err
+	}
+	if c.MaxUses > 0 && total >= c.MaxUses {
+		return errors.New("this coupon has been fully used")
+	}
+	if c.MaxUsesPerUser > 0 && perUser >= c.MaxUsesPerUser {
+		return errors.New("you have already used this coupon")
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO coupon_redemptions (coupon_id, user_id, booking_id, discount_amount, status)
+		VALUES (?, ?, ?, ?, 'reserved')
+	`, c.ID, userID, bookingID, discount)
+	if err != nil {
+		log.Println("Error recording coupon redemption:", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateRedemptionStatus moves the booking's redemption from one status to another
+func UpdateRedemptionStatus(bookingID int64, from, to string) error {
+	_, err := db.DB.Exec(
+		`UPDATE coupon_redemptions SET status = ? WHERE booking_id = ? AND status = ?`,
+		to, bookingID, from,
+	)
+	if err != nil {
+		log.Println("Error updating coupon redemption:", err)
+	}
+	return err
+}
+
+// FindRedemptionsByCouponID lists the uses of a coupon, newest first
+func FindRedemptionsByCouponID(couponID int64) ([]Redemption, error) {
+	query := `
+		SELECT r.id, r.coupon_id, c.code, r.user_id, r.booking_id, r.discount_amount, r.status, r.created_at
+		FROM coupon_redemptions r
+		JOIN coupons c ON r.coupon_id = c.id
+		WHERE r.coupon_id = ?
+		ORDER BY r.created_at DESC
+	`
+	rows, err := db.DB.Query(query, couponID)
+	if err != nil {
+		log.Println("Error fetching coupon redemptions:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Redemption, 0)
+	for rows.Next() {
+		var r Redemption
+		if err := rows.Scan(&r.ID, &r.CouponID, &r.Code, &r.UserID, &r.BookingID, &r.DiscountAmount, &r.Status, &r.CreatedAt); err != nil {
+			log.Println("Error scanning coupon redemption:", err)
+			continue
+		}
+		list = append(list, r)
+	}
+	return list, nil
+}
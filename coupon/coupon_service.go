@@ -0,0 +1,202 @@
+// coupon/coupon_service.go
+package coupon
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// NormalizeCode trims and upper-cases a code so "summer10 " matches "SUMMER10"
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validateCoupon(c *Coupon) error {
+	c.Code = NormalizeCode(c.Code)
+	if len(c.Code) < 3 || len(c.Code) > 50 {
+		return errors.New("code must be 3 to 50 characters")
+	}
+	switch c.DiscountType {
+	case "percent":
+		if c.DiscountValue <= 0 || c.DiscountValue > money.FromRupees(100) {
+			return errors.New("percent discount must be between 0 and 100")
+		}
+	case "flat":
+		if c.DiscountValue <= 0 {
+			return errors.New("flat discount must be positive")
+		}
+	default:
+		return errors.New("discount_type must be 'percent' or 'flat'")
+	}
+	if c.MaxDiscount < 0 || c.MinOrderAmount < 0 || c.MaxUses < 0 || c.MaxUsesPerUser < 0 {
+		return errors.New("limits cannot be negative")
+	}
+	if c.ValidFrom != nil && c.ValidUntil != nil && !c.ValidUntil.After(*c.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	return nil
+}
+
+// canManage: admins manage every code, owners the codes they created
+func canManage(c *Coupon, userID int64, userRole string) bool {
+	return userRole == "admin" || c.CreatedBy == userID
+}
+
+// CreateNewCoupon validates and saves a code. Owners can only create codes for their own venues.
+func CreateNewCoupon(c *Coupon, userID int64, userRole string) error {
+	if err := validateCoupon(c); err != nil {
+		return err
+	}
+
+	// 🔒 SECURITY CHECK
+	if userRole != "admin" {
+		if c.VenueID == 0 {
+			return errors.New("owners must restrict a coupon to one of their venues")
+		}
+		if err := venue.VerifyVenueOwnership(c.VenueID, userID); err != nil {
+			return err
+		}
+	}
+
+	if existing, err := FindCouponByCode(c.Code); err == nil && existing != nil {
+		return errors.New("a coupon with this code already exists")
+	}
+
+	c.CreatedBy = userID
+	return CreateCoupon(c)
+}
+
+// GetCoupons lists every code for admins and the owner's own codes otherwise
+func GetCoupons(userID int64, userRole string) ([]Coupon, error) {
+	if userRole == "admin" {
+		return FindCoupons(0)
+	}
+	return FindCoupons(userID)
+}
+
+// RemoveCoupon deactivates a code
+func RemoveCoupon(couponID int64, userID int64, userRole string) error {
+	c, err := FindCouponByID(couponID)
+	if err != nil {
+		return errors.New("coupon not found")
+	}
+	if !canManage(c, userID, userRole) {
+		return errors.New("unauthorized")
+	}
+	return DeactivateCoupon(couponID)
+}
+
+// GetCouponReport returns the redemptions of a code with totals
+func GetCouponReport(couponID int64, userID int64, userRole string) (*CouponReport, error) {
+	c, err := FindCouponByID(couponID)
+	if err != nil {
+		return nil, errors.New("coupon not found")
+	}
+	if !canManage(c, userID, userRole) {
+		return nil, errors.New("unauthorized")
+	}
+
+	redemptions, err := FindRedemptionsByCouponID(couponID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CouponReport{Coupon: c, Redemptions: redemptions}
+	for _, r := range redemptions {
+		switch r.Status {
+		case "redeemed":
+			report.Redeemed++
+			report.TotalDiscount += r.DiscountAmount
+		case "reserved":
+			report.Reserved++
+		}
+	}
+	return report, nil
+}
+
+// Evaluate checks a code against a booking and returns the discount it gives
+func Evaluate(code string, t Target) (*Coupon, money.Money, error) {
+	c, err := FindCouponByCode(NormalizeCode(code))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: unknown code", ErrCouponInvalid)
+	}
+	if !c.IsActive {
+		return nil, 0, fmt.Errorf("%w: this code is no longer active", ErrCouponInvalid)
+	}
+
+	now := time.Now()
+	if c.ValidFrom != nil && now.Before(*c.ValidFrom) {
+		return nil, 0, fmt.Errorf("%w: this code is not valid yet", ErrCouponInvalid)
+	}
+	if c.ValidUntil != nil && now.After(*c.ValidUntil) {
+		return nil, 0, fmt.Errorf("%w: this code has expired", ErrCouponInvalid)
+	}
+	if c.VenueID != 0 && c.VenueID != t.VenueID {
+		return nil, 0, fmt.Errorf("%w: this code is not valid at this venue", ErrCouponInvalid)
+	}
+	if c.SportCategory != "" && !strings.EqualFold(c.SportCategory, t.SportCategory) {
+		return nil, 0, fmt.Errorf("%w: this code is only valid for %s", ErrCouponInvalid, c.SportCategory)
+	}
+	if t.Amount < c.MinOrderAmount {
+		return nil, 0, fmt.Errorf("%w: minimum booking amount is %s", ErrCouponInvalid, c.MinOrderAmount.Format())
+	}
+
+	if c.FirstBookingOnly {
+		paid, err := CountPaidBookingsByUser(t.UserID)
+		if err != nil {
+			return nil, 0, errors.New("could not check coupon eligibility")
+		}
+		if paid > 0 {
+			return nil, 0, fmt.Errorf("%w: this code is only valid on your first booking", ErrCouponInvalid)
+		}
+	}
+
+	// Caps are checked here for a friendly message and again when the use is reserved
+	if c.MaxUses > 0 && c.UsedCount >= c.MaxUses {
+		return nil, 0, fmt.Errorf("%w: this code has been fully used", ErrCouponInvalid)
+	}
+
+	return c, computeDiscount(c, t.Amount), nil
+}
+
+func computeDiscount(c *Coupon, amount money.Money) money.Money {
+	var discount money.Money
+	if c.DiscountType == "percent" {
+		discount = amount.Percent(c.DiscountValue.Rupees())
+		if c.MaxDiscount > 0 && discount > c.MaxDiscount {
+			discount = c.MaxDiscount
+		}
+	} else {
+		discount = c.DiscountValue
+	}
+	return money.Min(discount, amount)
+}
+
+// Reserve records the use of a code on a booking that is waiting for payment
+func Reserve(c *Coupon, userID, bookingID int64, discount money.Money) error {
+	if err := ReserveRedemption(c, userID, bookingID, discount); err != nil {
+		return fmt.Errorf("%w: %v", ErrCouponInvalid, err)
+	}
+	return nil
+}
+
+// MarkRedeemed turns the booking's reserved use into a real one after payment
+func MarkRedeemed(bookingID int64) {
+	if err := UpdateRedemptionStatus(bookingID, "reserved", "redeemed"); err != nil {
+		log.Println("Error redeeming coupon:", err)
+	}
+}
+
+// Release gives an unpaid booking's use back to the caps
+func Release(bookingID int64) {
+	if err := UpdateRedemptionStatus(bookingID, "reserved", "released"); err != nil {
+		log.Println("Error releasing coupon:", err)
+	}
+}
@@ -0,0 +1,219 @@
+// gateway/fake.go
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Checkout outcomes the fake provider can simulate
+const (
+	FakeSuccess = "success" // Captured straight away; the checkout returns a signature
+	FakeFailure = "failure" // Declined; only a payment.failed event follows
+	FakeDelayed = "delayed" // Authorized now, captured after CaptureDelay (like a slow UPI approval)
+)
+
+// fakeSecret signs fake checkouts and webhooks. It is not a secret: the fake never touches money.
+const fakeSecret = "fake_gateway_secret"
+
+type fakeOrder struct {
+	ID      string
+	Receipt string
+	Amount  money.Money
+	Paid    bool
+}
+
+// Fake is an in-process payment provider for local development and tests.
+// Nothing leaves the process; webhook events are handed to OnEvent instead of posted.
+type Fake struct {
+	mu       sync.Mutex
+	seq      int
+	orders   map[string]*fakeOrder
+	payments map[string]*Payment
+
+	// CaptureDelay is how long a FakeDelayed payment stays authorized
+	CaptureDelay time.Duration
+	// OnEvent receives the webhook events the provider would have sent. Calls run in their own goroutine.
+	OnEvent func(*Event)
+}
+
+// FakeCheckout is what the checkout hands back to the frontend after a simulated payment
+type FakeCheckout struct {
+	OrderID   string `json:"razorpay_order_id"`
+	PaymentID string `json:"razorpay_payment_id"`
+	Signature string `json:"razorpay_signature,omitempty"` // Only for payments captured right away
+	Status    string `json:"status"`
+}
+
+// NewFake returns an empty fake provider
+func NewFake() *Fake {
+	return &Fake{
+		orders:       make(map[string]*fakeOrder),
+		payments:     make(map[string]*Payment),
+		CaptureDelay: 10 * time.Second,
+	}
+}
+
+func (f *Fake) Name() string  { return "fake" }
+func (f *Fake) KeyID() string { return "fake_key" }
+
+// nextID must be called with f.mu held
+func (f *Fake) nextID(prefix string) string {
+	f.seq++
+	return fmt.Sprintf("%s_fake%d_%d", prefix, time.Now().UnixNano()%1e6, f.seq)
+}
+
+func (f *Fake) emit(e Event) {
+	e.ID = "evt_" + e.Type + "_" + e.PaymentID + e.RefundID
+	if f.OnEvent != nil {
+		go f.OnEvent(&e)
+	}
+}
+
+// CreateOrder records an order to be paid through Pay
+func (f *Fake) CreateOrder(receipt string, amount money.Money) (string, error) {
+	if amount <= 0 {
+		return "", errors.New("order amount must be positive")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.nextID("order")
+	f.orders[id] = &fakeOrder{ID: id, Receipt: receipt, Amount: amount}
+	return id, nil
+}
+
+// VerifyPayment checks a signature returned by Pay
+func (f *Fake) VerifyPayment(orderID, paymentID, signature string) bool {
+	f.mu.Lock()
+	p, ok := f.payments[paymentID]
+	f.mu.Unlock()
+	if !ok || p.OrderID != orderID || p.Status != PaymentCaptured {
+		return false
+	}
+	return signature == sign(fakeSecret, []byte(orderID+"|"+paymentID))
+}
+
+// Pay simulates the player completing the checkout for an order with the given outcome
+func (f *Fake) Pay(orderID, outcome string) (*FakeCheckout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o, ok := f.orders[orderID]
+	if !ok {
+		return nil, errors.New("order not found")
+	}
+	if o.Paid {
+		return nil, errors.New("order is already paid")
+	}
+
+	p := &Payment{ID: f.nextID("pay"), OrderID: orderID, Amount: o.Amount}
+	f.payments[p.ID] = p
+	out := &FakeCheckout{OrderID: orderID, PaymentID: p.ID}
+
+	switch outcome {
+	case FakeSuccess:
+		p.Status = PaymentCaptured
+		o.Paid = true
+		out.Signature = sign(fakeSecret, []byte(orderID+"|"+p.ID))
+		f.emit(Event{Type: EventPaymentCaptured, OrderID: orderID, PaymentID: p.ID, Amount: p.Amount})
+	case FakeFailure:
+		p.Status = PaymentFailed
+		f.emit(Event{Type: EventPaymentFailed, OrderID: orderID, PaymentID: p.ID, Amount: p.Amount,
+			Error: "BAD_REQUEST_ERROR: Payment declined by the fake gateway"})
+	case FakeDelayed:
+		p.Status = PaymentAuthorized
+		o.Paid = true
+		time.AfterFunc(f.CaptureDelay, func() { f.capture(p.ID) })
+	default:
+		delete(f.payments, p.ID)
+		return nil, fmt.Errorf("unknown outcome %q", outcome)
+	}
+	out.Status = p.Status
+	return out, nil
+}
+
+// capture completes a delayed payment
+func (f *Fake) capture(paymentID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[paymentID]
+	if !ok || p.Status != PaymentAuthorized {
+		return
+	}
+	p.Status = PaymentCaptured
+	f.emit(Event{Type: EventPaymentCaptured, OrderID: p.OrderID, PaymentID: p.ID, Amount: p.Amount})
+}
+
+// FetchPayment returns a copy of a payment made through Pay
+func (f *Fake) FetchPayment(paymentID string) (*Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[paymentID]
+	if !ok {
+		return nil, errors.New("payment not found")
+	}
+	snapshot := *p
+	return &snapshot, nil
+}
+
+// FetchOrderPayments lists the payments made through Pay on an order
+func (f *Fake) FetchOrderPayments(orderID string) ([]Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.orders[orderID]; !ok {
+		return nil, errors.New("order not found")
+	}
+	list := make([]Payment, 0)
+	for _, p := range f.payments {
+		if p.OrderID == orderID {
+			list = append(list, *p)
+		}
+	}
+	return list, nil
+}
+
+// Refund refunds part or all of a captured payment; refund.processed follows
+func (f *Fake) Refund(paymentID string, amount money.Money) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[paymentID]
+	if !ok {
+		return nil, errors.New("failed to process refund: payment not found")
+	}
+	if p.Status != PaymentCaptured && p.Status != PaymentRefunded {
+		return nil, errors.New("failed to process refund: payment is not captured")
+	}
+	if amount <= 0 || p.Refunded+amount > p.Amount {
+		return nil, errors.New("failed to process refund: amount exceeds what is left to refund")
+	}
+
+	p.Refunded += amount
+	if p.Refunded >= p.Amount {
+		p.Status = PaymentRefunded
+	}
+	r := &Refund{ID: f.nextID("rfnd"), PaymentID: paymentID, Amount: amount, Status: "pending"}
+	f.emit(Event{Type: EventRefundProcessed, PaymentID: paymentID, RefundID: r.ID, Amount: amount})
+	return r, nil
+}
+
+// ParseWebhook accepts an Event posted as JSON with X-Fake-Signature, for driving the webhook by hand
+func (f *Fake) ParseWebhook(body []byte, header func(string) string) (*Event, error) {
+	if header("X-Fake-Signature") != sign(fakeSecret, body) {
+		return nil, ErrInvalidWebhookSignature
+	}
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil || e.ID == "" || e.Type == "" {
+		return nil, errors.New("invalid webhook body")
+	}
+	return &e, nil
+}
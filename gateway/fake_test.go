@@ -0,0 +1,149 @@
+// gateway/fake_test.go
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// useFake makes a fresh fake the provider for the test and collects the events it sends
+func useFake(t *testing.T) (*Fake, <-chan *Event) {
+	t.Helper()
+	f := NewFake()
+	f.CaptureDelay = 10 * time.Millisecond
+	events := make(chan *Event, 16)
+	f.OnEvent = func(e *Event) { events <- e }
+
+	previous := Provider
+	Provider = f
+	t.Cleanup(func() { Provider = previous })
+	return f, events
+}
+
+func nextEvent(t *testing.T, events <-chan *Event, wantType string) *Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		if e.Type != wantType {
+			t.Fatalf("got event %s, want %s", e.Type, wantType)
+		}
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no %s event", wantType)
+	}
+	return nil
+}
+
+func TestFakeOrderToRefund(t *testing.T) {
+	f, events := useFake(t)
+	amount := money.FromPaise(150050)
+
+	// Create the order and pay it at the checkout
+	orderID, err := CreateOrder("booking_42", amount)
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	checkout, err := f.Pay(orderID, FakeSuccess)
+	if err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	if checkout.Status != PaymentCaptured || checkout.Signature == "" {
+		t.Fatalf("unexpected checkout %+v", checkout)
+	}
+	if _, err := f.Pay(orderID, FakeSuccess); err == nil {
+		t.Fatal("a paid order should not take a second payment")
+	}
+
+	// Confirm: the browser's signature and the webhook both report the capture
+	if !VerifySignature(orderID, checkout.PaymentID, checkout.Signature) {
+		t.Fatal("checkout signature should verify")
+	}
+	if VerifySignature(orderID, checkout.PaymentID, checkout.Signature+"0") {
+		t.Fatal("a changed signature should not verify")
+	}
+	captured := nextEvent(t, events, EventPaymentCaptured)
+	if captured.OrderID != orderID || captured.PaymentID != checkout.PaymentID || captured.Amount != amount {
+		t.Fatalf("unexpected capture event %+v", captured)
+	}
+	p, err := FetchPayment(checkout.PaymentID)
+	if err != nil || p.Status != PaymentCaptured || p.Amount != amount {
+		t.Fatalf("FetchPayment = %+v, %v", p, err)
+	}
+
+	// Refund part, then the rest
+	part := amount.Percent(50)
+	r, err := InitiateRefund(checkout.PaymentID, part)
+	if err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	processed := nextEvent(t, events, EventRefundProcessed)
+	if processed.RefundID != r.ID || processed.Amount != part {
+		t.Fatalf("unexpected refund event %+v", processed)
+	}
+	if p, _ := FetchPayment(checkout.PaymentID); p.Status != PaymentCaptured || p.Refunded != part {
+		t.Fatalf("after a partial refund: %+v", p)
+	}
+
+	if _, err := InitiateRefund(checkout.PaymentID, amount); err == nil {
+		t.Fatal("refunding more than is left should fail")
+	}
+	if _, err := InitiateRefund(checkout.PaymentID, amount-part); err != nil {
+		t.Fatalf("refund of the rest: %v", err)
+	}
+	nextEvent(t, events, EventRefundProcessed)
+	if p, _ := FetchPayment(checkout.PaymentID); p.Status != PaymentRefunded || p.Refunded != amount {
+		t.Fatalf("after the full refund: %+v", p)
+	}
+}
+
+func TestFakeFailedAndDelayedPayments(t *testing.T) {
+	f, events := useFake(t)
+	orderID, _ := CreateOrder("booking_7", money.FromPaise(50000))
+
+	failed, err := f.Pay(orderID, FakeFailure)
+	if err != nil || failed.Status != PaymentFailed || failed.Signature != "" {
+		t.Fatalf("failed payment: %+v, %v", failed, err)
+	}
+	if e := nextEvent(t, events, EventPaymentFailed); e.Error == "" {
+		t.Fatal("a failed payment should say why")
+	}
+	if _, err := InitiateRefund(failed.PaymentID, money.FromPaise(100)); err == nil {
+		t.Fatal("a failed payment cannot be refunded")
+	}
+
+	// The player retries on the same order; a slow approval is captured later
+	delayed, err := f.Pay(orderID, FakeDelayed)
+	if err != nil || delayed.Status != PaymentAuthorized || delayed.Signature != "" {
+		t.Fatalf("delayed payment: %+v, %v", delayed, err)
+	}
+	nextEvent(t, events, EventPaymentCaptured)
+	if p, _ := FetchPayment(delayed.PaymentID); p.Status != PaymentCaptured {
+		t.Fatalf("delayed payment not captured: %+v", p)
+	}
+
+	payments, err := FetchOrderPayments(orderID)
+	if err != nil || len(payments) != 2 {
+		t.Fatalf("FetchOrderPayments = %v, %v", payments, err)
+	}
+}
+
+func TestFakeWebhookSignature(t *testing.T) {
+	useFake(t)
+	body, _ := json.Marshal(Event{ID: "evt_1", Type: EventPaymentCaptured, OrderID: "order_1", PaymentID: "pay_1", Amount: money.FromPaise(100)})
+
+	header := http.Header{}
+	header.Set("X-Fake-Signature", sign(fakeSecret, body))
+	e, err := ParseWebhook(body, header.Get)
+	if err != nil || e.PaymentID != "pay_1" || e.Amount != money.FromPaise(100) {
+		t.Fatalf("ParseWebhook = %+v, %v", e, err)
+	}
+
+	header.Set("X-Fake-Signature", sign("another secret", body))
+	if _, err := ParseWebhook(body, header.Get); err != ErrInvalidWebhookSignature {
+		t.Fatalf("wrong signature: got %v", err)
+	}
+}
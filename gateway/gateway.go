@@ -0,0 +1,141 @@
+// gateway/gateway.go
+package gateway
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Payment statuses, as Razorpay names them
+const (
+	PaymentCreated    = "created"
+	PaymentAuthorized = "authorized" // Approved by the bank but not captured yet
+	PaymentCaptured   = "captured"
+	PaymentFailed     = "failed"
+	PaymentRefunded   = "refunded"
+)
+
+// Webhook event types. Providers translate their own events into these.
+const (
+	EventPaymentCaptured = "payment.captured"
+	EventPaymentFailed   = "payment.failed"
+	EventOrderPaid       = "order.paid"
+	EventRefundProcessed = "refund.processed"
+	EventRefundFailed    = "refund.failed"
+)
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// Payment is a payment as the provider reports it.
+type Payment struct {
+	ID       string      `json:"id"`
+	OrderID  string      `json:"order_id"`
+	Amount   money.Money `json:"amount"`
+	Refunded money.Money `json:"amount_refunded"`
+	Status   string      `json:"status"`
+	Raw      string      `json:"-"` // The provider's response, kept on the payment attempt
+}
+
+// Refund is a refund the provider accepted. It completes later (see EventRefundProcessed).
+type Refund struct {
+	ID        string      `json:"id"`
+	PaymentID string      `json:"payment_id"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+}
+
+// Event is a verified webhook event in provider-neutral form
+type Event struct {
+	ID        string      `json:"id"` // Same on every redelivery of the event
+	Type      string      `json:"type"`
+	OrderID   string      `json:"order_id,omitempty"`
+	PaymentID string      `json:"payment_id,omitempty"`
+	RefundID  string      `json:"refund_id,omitempty"`
+	Amount    money.Money `json:"amount"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// PaymentGateway is what the app needs from a payment provider
+type PaymentGateway interface {
+	// Name identifies the provider in logs and API responses
+	Name() string
+	// KeyID is the public key the frontend checkout opens with
+	KeyID() string
+	// CreateOrder starts a checkout for amount; receipt identifies what is being paid for
+	CreateOrder(receipt string, amount money.Money) (string, error)
+	// VerifyPayment checks the signature the checkout returned for a payment on an order
+	VerifyPayment(orderID, paymentID, signature string) bool
+	// FetchPayment asks the provider for the current state of a payment
+	FetchPayment(paymentID string) (*Payment, error)
+	// FetchOrderPayments lists every payment tried against an order
+	FetchOrderPayments(orderID string) ([]Payment, error)
+	// Refund returns amount of a captured payment to the player
+	Refund(paymentID string, amount money.Money) (*Refund, error)
+	// ParseWebhook verifies and decodes a webhook request body
+	ParseWebhook(body []byte, header func(string) string) (*Event, error)
+}
+
+// Provider is the gateway in use, set up by Init
+var Provider PaymentGateway
+
+// Init picks the provider from PAYMENT_GATEWAY: "razorpay" (default) or "fake" for local development.
+// The fake provider is refused when GIN_MODE=release.
+func Init() {
+	switch os.Getenv("PAYMENT_GATEWAY") {
+	case "fake":
+		if os.Getenv("GIN_MODE") == "release" {
+			log.Fatal("❌ PAYMENT_GATEWAY=fake cannot be used in release mode")
+		}
+		Provider = NewFake()
+		log.Println("⚠️  Using the fake payment gateway: no real money moves")
+	default:
+		Provider = NewRazorpay(os.Getenv("RAZORPAY_KEY_ID"), os.Getenv("RAZORPAY_KEY_SECRET"), os.Getenv("RAZORPAY_WEBHOOK_SECRET"))
+	}
+}
+
+// current falls back to Razorpay if Init wasn't called
+func current() PaymentGateway {
+	if Provider == nil {
+		Init()
+	}
+	return Provider
+}
+
+// KeyID is the public checkout key of the current provider
+func KeyID() string {
+	return current().KeyID()
+}
+
+// CreateOrder creates an order for the checkout; receipt identifies what is being paid for
+func CreateOrder(receipt string, amount money.Money) (string, error) {
+	return current().CreateOrder(receipt, amount)
+}
+
+// VerifySignature checks if the payment is legitimate
+func VerifySignature(orderID, paymentID, signature string) bool {
+	return current().VerifyPayment(orderID, paymentID, signature)
+}
+
+// FetchPayment returns the provider's view of a payment
+func FetchPayment(paymentID string) (*Payment, error) {
+	return current().FetchPayment(paymentID)
+}
+
+// FetchOrderPayments returns the provider's view of the payments on an order
+func FetchOrderPayments(orderID string) ([]Payment, error) {
+	return current().FetchOrderPayments(orderID)
+}
+
+// InitiateRefund refunds amount of a payment. Callers go through the refund package,
+// which tracks the refund until the provider reports it processed.
+func InitiateRefund(paymentID string, amount money.Money) (*Refund, error) {
+	return current().Refund(paymentID, amount)
+}
+
+// ParseWebhook verifies and decodes a webhook for the current provider
+func ParseWebhook(body []byte, header func(string) string) (*Event, error) {
+	return current().ParseWebhook(body, header)
+}
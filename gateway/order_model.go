@@ -0,0 +1,70 @@
+// gateway/order_model.go
+package gateway
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Order purposes: what a payment order was created to pay for
+const (
+	PurposeBooking = "booking" // A booking or a reschedule top-up hold
+	PurposeSeries  = "series"  // Every held occurrence of a recurring series
+	PurposeTopup   = "topup"
+	PurposePack    = "pack"
+)
+
+// Order statuses
+const (
+	OrderCreated   = "created"
+	OrderAttempted = "attempted" // A payment was tried but nothing has been applied yet
+	OrderPaid      = "paid"      // The captured payment was applied to what the order was for
+	OrderExpired   = "expired"   // Nothing was captured before the checkout was abandoned
+	OrderRefunded  = "refunded"  // Captured after the order was replaced, and given back
+)
+
+// Where an attempt was learned about
+const (
+	SourceCheckout  = "checkout"  // The browser's verify call
+	SourceWebhook   = "webhook"   // A provider event
+	SourceReconcile = "reconcile" // The reconciliation job asking the provider
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid payment signature")
+	ErrOrderMismatch    = errors.New("payment order does not belong to this checkout")
+)
+
+// Order is a checkout created with the provider, kept so payments can be matched and reconciled
+type Order struct {
+	ID            int64       `json:"id"`
+	OrderID       string      `json:"order_id"`
+	Provider      string      `json:"provider"`
+	Purpose       string      `json:"purpose"`
+	ReferenceID   int64       `json:"reference_id"` // Booking, series, top-up or pack purchase ID
+	UserID        int64       `json:"user_id"`
+	Amount        money.Money `json:"amount"`
+	Currency      string      `json:"currency"`
+	Receipt       string      `json:"receipt"`
+	Status        string      `json:"status"`
+	LastCheckedAt *time.Time  `json:"last_checked_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	Attempts      []Attempt   `json:"attempts,omitempty"`
+}
+
+// Attempt is one payment made against an order
+type Attempt struct {
+	ID        int64       `json:"id"`
+	OrderID   string      `json:"order_id"`
+	PaymentID string      `json:"payment_id"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"` // Payment status, e.g. 'captured' or 'failed'
+	Source    string      `json:"source"`
+	Error     string      `json:"error,omitempty"`
+	Response  string      `json:"gateway_response,omitempty"` // Raw provider response, when fetched
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
@@ -0,0 +1,156 @@
+// gateway/order_repository.go
+package gateway
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+func insertOrder(o *Order) error {
+	query := `
+		INSERT INTO payment_orders (order_id, provider, purpose, reference_id, user_id, amount, currency, receipt, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'created')
+	`
+	result, err := db.DB.Exec(query, o.OrderID, o.Provider, o.Purpose, o.ReferenceID, o.UserID, o.Amount, o.Currency, o.Receipt)
+	if err != nil {
+		log.Println("Error saving payment order:", err)
+		return err
+	}
+	o.ID, _ = result.LastInsertId()
+	o.Status = OrderCreated
+	return nil
+}
+
+const orderColumns = `
+	id, order_id, provider, purpose, reference_id, user_id, amount, currency, receipt, status,
+	last_checked_at, created_at, updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrder(row rowScanner) (*Order, error) {
+	var o Order
+	var checked sql.NullTime
+	err := row.Scan(&o.ID, &o.OrderID, &o.Provider, &o.Purpose, &o.ReferenceID, &o.UserID, &o.Amount,
+		&o.Currency, &o.Receipt, &o.Status, &checked, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if checked.Valid {
+		o.LastCheckedAt = &checked.Time
+	}
+	return &o, nil
+}
+
+// FindOrder returns a payment order by the provider's order ID
+func FindOrder(orderID string) (*Order, error) {
+	return scanOrder(db.DB.QueryRow(`SELECT `+orderColumns+` FROM payment_orders WHERE order_id = ?`, orderID))
+}
+
+func queryOrders(query string, args ...interface{}) ([]Order, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching payment orders:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Order, 0)
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			log.Println("Error scanning payment order:", err)
+			continue
+		}
+		list = append(list, *o)
+	}
+	return list, nil
+}
+
+// FindUnsettledOrders lists orders created between since and before that nothing has been applied to yet,
+// least recently checked first
+func FindUnsettledOrders(since, before time.Time, limit int) ([]Order, error) {
+	return queryOrders(`
+		SELECT `+orderColumns+` FROM payment_orders
+		WHERE status IN ('created', 'attempted') AND created_at >= ? AND created_at < ?
+		ORDER BY last_checked_at IS NOT NULL, last_checked_at ASC, id ASC
+		LIMIT ?
+	`, since, before, limit)
+}
+
+// FindOrders lists orders for the admin, newest first, optionally filtered by status
+func FindOrders(status string, limit int) ([]Order, error) {
+	if status == "" {
+		return queryOrders(`SELECT `+orderColumns+` FROM payment_orders ORDER BY id DESC LIMIT ?`, limit)
+	}
+	return queryOrders(`SELECT `+orderColumns+` FROM payment_orders WHERE status = ? ORDER BY id DESC LIMIT ?`, status, limit)
+}
+
+// upsertAttempt records a payment on an order. A payment that was captured is never moved back
+// to an earlier status by an event that arrives late.
+func upsertAttempt(a *Attempt) error {
+	query := `
+		INSERT INTO payment_attempts (order_id, payment_id, amount, status, source, error, gateway_response)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+		ON DUPLICATE KEY UPDATE
+			status = IF(status IN ('captured', 'refunded') AND VALUES(status) NOT IN ('captured', 'refunded'), status, VALUES(status)),
+			amount = VALUES(amount),
+			error = COALESCE(VALUES(error), error),
+			gateway_response = COALESCE(VALUES(gateway_response), gateway_response)
+	`
+	_, err := db.DB.Exec(query, a.OrderID, a.PaymentID, a.Amount, a.Status, a.Source, a.Error, a.Response)
+	if err != nil {
+		log.Println("Error saving payment attempt:", err)
+	}
+	return err
+}
+
+// FindAttempts lists the payments made against an order
+func FindAttempts(orderID string) ([]Attempt, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, order_id, payment_id, amount, status, source, COALESCE(error, ''), COALESCE(gateway_response, ''), created_at, updated_at
+		FROM payment_attempts WHERE order_id = ? ORDER BY id ASC
+	`, orderID)
+	if err != nil {
+		log.Println("Error fetching payment attempts:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Attempt, 0)
+	for rows.Next() {
+		var a Attempt
+		if err := rows.Scan(&a.ID, &a.OrderID, &a.PaymentID, &a.Amount, &a.Status, &a.Source, &a.Error, &a.Response, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			log.Println("Error scanning payment attempt:", err)
+			continue
+		}
+		list = append(list, a)
+	}
+	return list, nil
+}
+
+// updateOrderStatus moves an order to status if it is currently in one of from
+func updateOrderStatus(orderID, status string, from ...string) error {
+	query := `UPDATE payment_orders SET status = ? WHERE order_id = ? AND status IN (?` + strings.Repeat(", ?", len(from)-1) + `)`
+	args := []interface{}{status, orderID}
+	for _, s := range from {
+		args = append(args, s)
+	}
+	_, err := db.DB.Exec(query, args...)
+	if err != nil {
+		log.Println("Error updating payment order:", err)
+	}
+	return err
+}
+
+// TouchOrder notes that the provider was just asked about the order
+func TouchOrder(orderID string) error {
+	_, err := db.DB.Exec(`UPDATE payment_orders SET last_checked_at = NOW() WHERE order_id = ?`, orderID)
+	return err
+}
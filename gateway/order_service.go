@@ -0,0 +1,102 @@
+// gateway/order_service.go
+package gateway
+
+import (
+	"fmt"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// CreateOrderFor starts a checkout for what is being paid for and records the order,
+// so verification and reconciliation can tie its payments back
+func CreateOrderFor(purpose string, referenceID, userID int64, amount money.Money) (*Order, error) {
+	provider := current()
+	receipt := fmt.Sprintf("receipt_%s_%d", purpose, referenceID)
+	orderID, err := provider.CreateOrder(receipt, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Order{
+		OrderID:     orderID,
+		Provider:    provider.Name(),
+		Purpose:     purpose,
+		ReferenceID: referenceID,
+		UserID:      userID,
+		Amount:      amount,
+		Currency:    money.Currency,
+		Receipt:     receipt,
+	}
+	if err := insertOrder(o); err != nil {
+		return nil, fmt.Errorf("failed to save payment order: %w", err)
+	}
+	return o, nil
+}
+
+// VerifyCheckout checks the checkout signature and that the order was created for this
+// purpose and reference, then records the payment. Callers still compare o.Amount with what is owed.
+func VerifyCheckout(purpose string, referenceID int64, orderID, paymentID, signature string) (*Order, error) {
+	if !VerifySignature(orderID, paymentID, signature) {
+		return nil, ErrInvalidSignature
+	}
+	o, err := FindOrder(orderID)
+	if err != nil || o.Purpose != purpose || o.ReferenceID != referenceID {
+		return nil, ErrOrderMismatch
+	}
+	if err := RecordAttempt(orderID, paymentID, o.Amount, PaymentCaptured, SourceCheckout, "", ""); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// RecordAttempt stores what is known about a payment on an order. Payments on orders
+// that were never recorded (created before orders were kept) are skipped.
+func RecordAttempt(orderID, paymentID string, amount money.Money, status, source, errMsg, response string) error {
+	if orderID == "" || paymentID == "" {
+		return nil
+	}
+	if _, err := FindOrder(orderID); err != nil {
+		return nil
+	}
+	err := upsertAttempt(&Attempt{
+		OrderID:   orderID,
+		PaymentID: paymentID,
+		Amount:    amount,
+		Status:    status,
+		Source:    source,
+		Error:     errMsg,
+		Response:  response,
+	})
+	if err != nil {
+		return err
+	}
+	return updateOrderStatus(orderID, OrderAttempted, OrderCreated)
+}
+
+// MarkOrderPaid records that the order's payment was applied to what it was for
+func MarkOrderPaid(orderID string) error {
+	return updateOrderStatus(orderID, OrderPaid, OrderCreated, OrderAttempted, OrderExpired)
+}
+
+// MarkOrderExpired closes an order nothing was captured on
+func MarkOrderExpired(orderID string) error {
+	return updateOrderStatus(orderID, OrderExpired, OrderCreated, OrderAttempted)
+}
+
+// MarkOrderRefunded records that a payment on a replaced order was given back
+func MarkOrderRefunded(orderID string) error {
+	return updateOrderStatus(orderID, OrderRefunded, OrderCreated, OrderAttempted, OrderExpired)
+}
+
+// GetOrder returns an order with its attempts
+func GetOrder(orderID string) (*Order, error) {
+	o, err := FindOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	o.Attempts, err = FindAttempts(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
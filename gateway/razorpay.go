@@ -1,51 +1,204 @@
+// gateway/razorpay.go
 package gateway
 
 import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
-	"os"
+	"math"
 
 	"github.com/razorpay/razorpay-go"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
 )
 
-// InitiateRefund processes a refund via Razorpay
-func InitiateRefund(paymentID string, amount float64) error {
-	client := razorpay.NewClient(os.Getenv("RAZORPAY_KEY_ID"), os.Getenv("RAZORPAY_KEY_SECRET"))
+// Razorpay is the live payment provider
+type Razorpay struct {
+	keyID         string
+	keySecret     string
+	webhookSecret string // Set in the Razorpay dashboard, separate from the API secret
+	client        *razorpay.Client
+}
+
+// NewRazorpay builds the provider from API and webhook credentials
+func NewRazorpay(keyID, keySecret, webhookSecret string) *Razorpay {
+	return &Razorpay{
+		keyID:         keyID,
+		keySecret:     keySecret,
+		webhookSecret: webhookSecret,
+		client:        razorpay.NewClient(keyID, keySecret),
+	}
+}
+
+func (r *Razorpay) Name() string  { return "razorpay" }
+func (r *Razorpay) KeyID() string { return r.keyID }
 
-	// Razorpay expects amount in paise (int)
-	refundAmount := int(amount * 100)
+// Razorpay amounts are in paise
+func toPaise(amount money.Money) int {
+	return int(amount.Paise())
+}
+
+func fromPaise(v interface{}) money.Money {
+	f, _ := v.(float64) // JSON numbers decode as float64
+	return money.FromPaise(int64(math.Round(f)))
+}
 
+// CreateOrder creates a Razorpay order that is captured automatically once paid
+func (r *Razorpay) CreateOrder(receipt string, amount money.Money) (string, error) {
+	data := map[string]interface{}{
+		"amount":          toPaise(amount),
+		"currency":        money.Currency,
+		"receipt":         receipt,
+		"payment_capture": 1,
+	}
+
+	body, err := r.client.Order.Create(data, nil)
+	if err != nil {
+		return "", errors.New("failed to create razorpay order: " + err.Error())
+	}
+
+	orderID, ok := body["id"].(string)
+	if !ok {
+		return "", errors.New("invalid response from razorpay")
+	}
+
+	return orderID, nil
+}
+
+func sign(secret string, data []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyPayment checks the checkout signature: HMAC-SHA256 of "order_id|payment_id" with the key secret
+func (r *Razorpay) VerifyPayment(orderID, paymentID, signature string) bool {
+	expected := sign(r.keySecret, []byte(orderID+"|"+paymentID))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// FetchPayment returns a payment's status and amounts from Razorpay
+func (r *Razorpay) FetchPayment(paymentID string) (*Payment, error) {
+	body, err := r.client.Payment.Fetch(paymentID, nil, nil)
+	if err != nil {
+		return nil, errors.New("failed to fetch payment: " + err.Error())
+	}
+	return paymentFromMap(body), nil
+}
+
+// FetchOrderPayments lists the payments Razorpay has on an order
+func (r *Razorpay) FetchOrderPayments(orderID string) ([]Payment, error) {
+	body, err := r.client.Order.Payments(orderID, nil, nil)
+	if err != nil {
+		return nil, errors.New("failed to fetch order payments: " + err.Error())
+	}
+	items, _ := body["items"].([]interface{})
+	list := make([]Payment, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			list = append(list, *paymentFromMap(m))
+		}
+	}
+	return list, nil
+}
+
+func paymentFromMap(body map[string]interface{}) *Payment {
+	p := &Payment{
+		Amount:   fromPaise(body["amount"]),
+		Refunded: fromPaise(body["amount_refunded"]),
+	}
+	p.ID, _ = body["id"].(string)
+	p.OrderID, _ = body["order_id"].(string)
+	p.Status, _ = body["status"].(string)
+	if raw, err := json.Marshal(body); err == nil {
+		p.Raw = string(raw)
+	}
+	return p
+}
+
+// Refund starts a normal-speed refund
+func (r *Razorpay) Refund(paymentID string, amount money.Money) (*Refund, error) {
+	refundAmount := toPaise(amount)
 	data := map[string]interface{}{
 		"amount": refundAmount,
 		"speed":  "normal",
 	}
 
-	// FIX: Provide all 4 arguments required by the SDK: 
-	// 1. Payment ID (string)
-	// 2. Amount (int)
-	// 3. Data (map)
-	// 4. Headers (map[string]string - nil is fine)
-	_, err := client.Payment.Refund(paymentID, refundAmount, data, nil)
+	body, err := r.client.Payment.Refund(paymentID, refundAmount, data, nil)
 	if err != nil {
-		return errors.New("failed to process refund: " + err.Error())
+		return nil, errors.New("failed to process refund: " + err.Error())
 	}
 
-	return nil
+	refund := &Refund{PaymentID: paymentID, Amount: amount}
+	refund.ID, _ = body["id"].(string)
+	refund.Status, _ = body["status"].(string)
+	return refund, nil
 }
 
-// VerifySignature checks if the Razorpay payment is legitimate
-func VerifySignature(orderID, paymentID, signature string) bool {
-	secret := os.Getenv("RAZORPAY_KEY_SECRET")
-	
-	// Create the message to sign
-	data := orderID + "|" + paymentID
+// razorpayEvent is the part of a Razorpay webhook body the app uses. Amounts are in paise.
+type razorpayEvent struct {
+	Event   string `json:"event"`
+	Payload struct {
+		Payment *struct {
+			Entity struct {
+				ID               string `json:"id"`
+				OrderID          string `json:"order_id"`
+				Amount           int64  `json:"amount"`
+				ErrorCode        string `json:"error_code"`
+				ErrorDescription string `json:"error_description"`
+			} `json:"entity"`
+		} `json:"payment"`
+		Order *struct {
+			Entity struct {
+				ID string `json:"id"`
+			} `json:"entity"`
+		} `json:"order"`
+		Refund *struct {
+			Entity struct {
+				ID        string `json:"id"`
+				PaymentID string `json:"payment_id"`
+				Amount    int64  `json:"amount"`
+			} `json:"entity"`
+		} `json:"refund"`
+	} `json:"payload"`
+}
 
-	// Generate HMAC SHA256 signature
-	h := hmac.New(sha256.New, []byte(secret))
-	h.Write([]byte(data))
-	generatedSignature := hex.EncodeToString(h.Sum(nil))
+// ParseWebhook checks X-Razorpay-Signature and flattens the event.
+// Redeliveries carry the same X-Razorpay-Event-Id; without one the body hash stands in.
+func (r *Razorpay) ParseWebhook(body []byte, header func(string) string) (*Event, error) {
+	signature := header("X-Razorpay-Signature")
+	if r.webhookSecret == "" || signature == "" ||
+		!hmac.Equal([]byte(sign(r.webhookSecret, body)), []byte(signature)) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var raw razorpayEvent
+	if err := json.Unmarshal(body, &raw); err != nil || raw.Event == "" {
+		return nil, errors.New("invalid webhook body")
+	}
 
-	return generatedSignature == signature
-}
\ No newline at end of file
+	e := &Event{ID: header("X-Razorpay-Event-Id"), Type: raw.Event}
+	if e.ID == "" {
+		sum := sha256.Sum256(body)
+		e.ID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	if p := raw.Payload.Payment; p != nil {
+		e.PaymentID = p.Entity.ID
+		e.OrderID = p.Entity.OrderID
+		e.Amount = money.FromPaise(p.Entity.Amount)
+		if p.Entity.ErrorCode != "" {
+			e.Error = p.Entity.ErrorCode + ": " + p.Entity.ErrorDescription
+		}
+	}
+	if o := raw.Payload.Order; o != nil {
+		e.OrderID = o.Entity.ID
+	}
+	if rf := raw.Payload.Refund; rf != nil {
+		e.RefundID = rf.Entity.ID
+		e.PaymentID = rf.Entity.PaymentID
+		e.Amount = money.FromPaise(rf.Entity.Amount)
+	}
+	return e, nil
+}
@@ -0,0 +1,150 @@
+// invoice/invoice_handler.go
+package invoice
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/venue"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTaxProfileHandler handles GET /api/v1/venues/:id/tax-profile
+func GetTaxProfileHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	profile, err := FindTaxProfile(venueID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNoTaxProfile.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch tax profile"})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// SaveTaxProfileHandler handles PUT /api/v1/venues/:id/tax-profile
+func SaveTaxProfileHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var p TaxProfile
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	p.VenueID = venueID
+
+	saved, err := SaveTaxProfile(&p)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// GetVenueInvoicesHandler handles GET /api/v1/venues/:id/invoices?fy=2026-27
+// It lists the venue's invoices and credit notes for a financial year (the current one by default).
+func GetVenueInvoicesHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	fy := strings.TrimSpace(c.Query("fy"))
+	if fy == "" {
+		fy, _ = FinancialYear(time.Now())
+	}
+
+	list, err := FindInvoicesByVenueID(venueID, fy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch invoices"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"financial_year": fy, "invoices": list})
+}
+
+// DownloadInvoiceHandler handles GET /api/v1/invoices/:id/pdf
+func DownloadInvoiceHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+
+	inv, err := FindInvoiceByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if !CanAccessInvoice(inv, userID, userRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this invoice"})
+		return
+	}
+
+	pdf, err := GeneratePDF(inv)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate invoice PDF"})
+		return
+	}
+
+	fileName := strings.ReplaceAll(inv.Number, "/", "-") + ".pdf"
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	c.Header("Content-Type", "application/pdf")
+
+	if err := pdf.Output(c.Writer); err != nil {
+		fmt.Println("Error outputting PDF:", err)
+	}
+}
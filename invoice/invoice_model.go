@@ -0,0 +1,124 @@
+// invoice/invoice_model.go
+package invoice
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Document types. Both share the per-venue numbering rules but keep separate series.
+const (
+	DocInvoice    = "invoice"
+	DocCreditNote = "credit_note"
+)
+
+// Defaults for a venue that doesn't override them
+const (
+	DefaultSACCode = "999652" // Sports and recreational sports facility operation services
+	DefaultGSTRate = 18.0
+	DefaultPrefix  = "INV"
+)
+
+var (
+	ErrNoTaxProfile     = errors.New("this venue has not set up GST invoicing")
+	ErrAlreadyInvoiced  = errors.New("an invoice has already been issued for this booking")
+	ErrInvalidGSTIN     = errors.New("invalid GSTIN")
+	ErrNothingToInvoice = errors.New("this booking has no taxable amount to invoice")
+)
+
+// TaxProfile is the venue's GST registration, printed as the supplier on every invoice
+type TaxProfile struct {
+	VenueID   int64   `json:"venue_id"`
+	LegalName string  `json:"legal_name"`
+	GSTIN     string  `json:"gstin"`
+	Address   string  `json:"address"`
+	StateCode string  `json:"state_code"` // Where the venue is, i.e. the place of supply
+	GSTRate   float64 `json:"gst_rate"`   // Total rate in percent; prices are GST-inclusive
+	SACCode   string  `json:"sac_code"`
+	// InvoicePrefix starts every invoice number ("INV/2627/00001"); credit notes add a "C".
+	// Venues sharing one GSTIN need different prefixes.
+	InvoicePrefix string    `json:"invoice_prefix"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Buyer is who the invoice is made out to. Corporate players add their GSTIN for input credit.
+type Buyer struct {
+	Name      string `json:"buyer_name"`
+	GSTIN     string `json:"buyer_gstin"`
+	Address   string `json:"buyer_address"`
+	StateCode string `json:"buyer_state_code"` // Taken from the GSTIN when one is given
+}
+
+// Supply is the booking being invoiced, as handed over by the booking package
+type Supply struct {
+	BookingID   int64
+	VenueID     int64
+	UserID      int64
+	Description string
+	Hours       float64
+	Amount      money.Money // What the player paid, GST included
+}
+
+// Invoice is an issued tax invoice or credit note. Supplier and buyer details are copied
+// in at issue time so later profile edits never change a document already handed out.
+type Invoice struct {
+	ID                int64      `json:"id"`
+	VenueID           int64      `json:"venue_id"`
+	BookingID         int64      `json:"booking_id"`
+	UserID            int64      `json:"user_id"`
+	DocType           string     `json:"doc_type"` // 'invoice' or 'credit_note'
+	Number            string     `json:"number"`
+	FinancialYear     string     `json:"financial_year"`                // e.g. "2026-27"
+	OriginalInvoiceID int64      `json:"original_invoice_id,omitempty"` // Credit notes only
+	OriginalNumber    string     `json:"original_number,omitempty"`
+	OriginalIssuedAt  *time.Time `json:"original_issued_at,omitempty"`
+	IssuedAt          time.Time  `json:"issued_at"`
+
+	SupplierName    string `json:"supplier_name"`
+	SupplierGSTIN   string `json:"supplier_gstin"`
+	SupplierAddress string `json:"supplier_address"`
+	SupplierState   string `json:"supplier_state_code"`
+
+	BuyerName    string `json:"buyer_name"`
+	BuyerGSTIN   string `json:"buyer_gstin,omitempty"`
+	BuyerAddress string `json:"buyer_address,omitempty"`
+	BuyerState   string `json:"buyer_state_code,omitempty"`
+
+	PlaceOfSupply string  `json:"place_of_supply"` // State code
+	SACCode       string  `json:"sac_code"`
+	Description   string  `json:"description"`
+	Hours         float64 `json:"hours"`
+
+	TaxableValue money.Money `json:"taxable_value"`
+	CGSTRate     float64     `json:"cgst_rate"`
+	CGSTAmount   money.Money `json:"cgst_amount"`
+	SGSTRate     float64     `json:"sgst_rate"`
+	SGSTAmount   money.Money `json:"sgst_amount"`
+	IGSTRate     float64     `json:"igst_rate"`
+	IGSTAmount   money.Money `json:"igst_amount"`
+	Total        money.Money `json:"total"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// stateNames are the GST state codes (first two digits of a GSTIN)
+var stateNames = map[string]string{
+	"01": "Jammu and Kashmir", "02": "Himachal Pradesh", "03": "Punjab", "04": "Chandigarh",
+	"05": "Uttarakhand", "06": "Haryana", "07": "Delhi", "08": "Rajasthan", "09": "Uttar Pradesh",
+	"10": "Bihar", "11": "Sikkim", "12": "Arunachal Pradesh", "13": "Nagaland", "14": "Manipur",
+	"15": "Mizoram", "16": "Tripura", "17": "Meghalaya", "18": "Assam", "19": "West Bengal",
+	"20": "Jharkhand", "21": "Odisha", "22": "Chhattisgarh", "23": "Madhya Pradesh", "24": "Gujarat",
+	"26": "Dadra and Nagar Haveli and Daman and Diu", "27": "Maharashtra", "29": "Karnataka",
+	"30": "Goa", "31": "Lakshadweep", "32": "Kerala", "33": "Tamil Nadu", "34": "Puducherry",
+	"35": "Andaman and Nicobar Islands", "36": "Telangana", "37": "Andhra Pradesh", "38": "Ladakh",
+	"97": "Other Territory",
+}
+
+// StateName returns "Karnataka (29)" for a state code
+func StateName(code string) string {
+	if name, ok := stateNames[code]; ok {
+		return name + " (" + code + ")"
+	}
+	return code
+}
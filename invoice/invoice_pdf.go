@@ -0,0 +1,224 @@
+// invoice/invoice_pdf.go
+package invoice
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/JkD004/playarena-backend/venue"
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+var (
+	onesWords = []string{"", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten",
+		"Eleven", "Twelve", "Thirteen", "Fourteen", "Fifteen", "Sixteen", "Seventeen", "Eighteen", "Nineteen"}
+	tensWords = []string{"", "", "Twenty", "Thirty", "Forty", "Fifty", "Sixty", "Seventy", "Eighty", "Ninety"}
+)
+
+// belowThousand spells 0-999
+func belowThousand(n int64) string {
+	parts := make([]string, 0, 3)
+	if n >= 100 {
+		parts = append(parts, onesWords[n/100]+" Hundred")
+		n %= 100
+	}
+	if n >= 20 {
+		word := tensWords[n/10]
+		if n%10 > 0 {
+			word += " " + onesWords[n%10]
+		}
+		parts = append(parts, word)
+	} else if n > 0 {
+		parts = append(parts, onesWords[n])
+	}
+	return strings.Join(parts, " ")
+}
+
+// spellIndian spells a whole number with the Indian grouping (crore, lakh, thousand)
+func spellIndian(n int64) string {
+	if n == 0 {
+		return "Zero"
+	}
+	parts := make([]string, 0, 4)
+	if n >= 10000000 {
+		parts = append(parts, spellIndian(n/10000000)+" Crore")
+		n %= 10000000
+	}
+	if n >= 100000 {
+		parts = append(parts, belowThousand(n/100000)+" Lakh")
+		n %= 100000
+	}
+	if n >= 1000 {
+		parts = append(parts, belowThousand(n/1000)+" Thousand")
+		n %= 1000
+	}
+	if n > 0 {
+		parts = append(parts, belowThousand(n))
+	}
+	return strings.Join(parts, " ")
+}
+
+// AmountInWords renders 1180.50 as "Rupees One Thousand One Hundred Eighty and Fifty Paise Only"
+func AmountInWords(amount money.Money) string {
+	paiseTotal := amount.Paise()
+	words := "Rupees " + spellIndian(paiseTotal/100)
+	if paise := paiseTotal % 100; paise > 0 {
+		words += " and " + belowThousand(paise) + " Paise"
+	}
+	return words + " Only"
+}
+
+func rate(v float64) string {
+	return strings.TrimSuffix(strings.TrimSuffix(fmt.Sprintf("%.2f", v), "0"), ".0") + "%"
+}
+
+// GeneratePDF renders a tax invoice or credit note
+func GeneratePDF(inv *Invoice) (*gofpdf.Fpdf, error) {
+	dark := []int{17, 24, 39}
+	gray := []int{107, 114, 128}
+	lightGray := []int{243, 244, 246}
+	border := []int{209, 213, 219}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+	pdf.SetDrawColor(border[0], border[1], border[2])
+
+	title := "TAX INVOICE"
+	if inv.DocType == DocCreditNote {
+		title = "CREDIT NOTE"
+	}
+
+	// HEADER
+	pdf.SetTextColor(dark[0], dark[1], dark[2])
+	pdf.SetFont("Arial", "B", 18)
+	pdf.CellFormat(180, 10, title, "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 8)
+	pdf.SetTextColor(gray[0], gray[1], gray[2])
+	pdf.CellFormat(180, 5, "Original for Recipient", "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	// SUPPLIER (left) + DOCUMENT DETAILS (right)
+	y := pdf.GetY()
+	pdf.SetTextColor(dark[0], dark[1], dark[2])
+	pdf.SetFont("Arial", "B", 11)
+	pdf.SetXY(15, y)
+	pdf.MultiCell(100, 5, inv.SupplierName, "", "L", false)
+	pdf.SetFont("Arial", "", 9)
+	pdf.SetX(15)
+	pdf.MultiCell(100, 4.5, inv.SupplierAddress, "", "L", false)
+	pdf.SetX(15)
+	pdf.MultiCell(100, 4.5, "GSTIN: "+inv.SupplierGSTIN, "", "L", false)
+	pdf.SetX(15)
+	pdf.MultiCell(100, 4.5, "State: "+StateName(inv.SupplierState), "", "L", false)
+	leftEnd := pdf.GetY()
+
+	issued := inv.IssuedAt.In(venue.IST)
+	details := [][2]string{
+		{"Number", inv.Number},
+		{"Date", issued.Format("02 Jan 2006")},
+		{"Place of supply", StateName(inv.PlaceOfSupply)},
+		{"Booking", fmt.Sprintf("#%d", inv.BookingID)},
+	}
+	if inv.DocType == DocCreditNote {
+		against := inv.OriginalNumber
+		if inv.OriginalIssuedAt != nil {
+			against += " dated " + inv.OriginalIssuedAt.In(venue.IST).Format("02 Jan 2006")
+		}
+		details = append(details, [2]string{"Against invoice", against})
+	}
+	pdf.SetY(y)
+	for _, d := range details {
+		pdf.SetX(120)
+		pdf.SetFont("Arial", "B", 8)
+		pdf.SetTextColor(gray[0], gray[1], gray[2])
+		pdf.CellFormat(28, 5, strings.ToUpper(d[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		pdf.SetTextColor(dark[0], dark[1], dark[2])
+		pdf.MultiCell(47, 5, d[1], "", "L", false)
+	}
+	pdf.SetY(math.Max(leftEnd, pdf.GetY()) + 4)
+
+	// BUYER
+	pdf.Line(15, pdf.GetY(), 195, pdf.GetY())
+	pdf.Ln(3)
+	pdf.SetFont("Arial", "B", 8)
+	pdf.SetTextColor(gray[0], gray[1], gray[2])
+	pdf.CellFormat(180, 5, "BILL TO", "", 1, "L", false, 0, "")
+	pdf.SetTextColor(dark[0], dark[1], dark[2])
+	pdf.SetFont("Arial", "B", 10)
+	pdf.MultiCell(180, 5, inv.BuyerName, "", "L", false)
+	pdf.SetFont("Arial", "", 9)
+	if inv.BuyerAddress != "" {
+		pdf.MultiCell(180, 4.5, inv.BuyerAddress, "", "L", false)
+	}
+	if inv.BuyerGSTIN != "" {
+		pdf.MultiCell(180, 4.5, "GSTIN: "+inv.BuyerGSTIN, "", "L", false)
+	} else {
+		pdf.MultiCell(180, 4.5, "Unregistered buyer", "", "L", false)
+	}
+	if inv.BuyerState != "" {
+		pdf.MultiCell(180, 4.5, "State: "+StateName(inv.BuyerState), "", "L", false)
+	}
+	pdf.Ln(5)
+
+	// LINE ITEM
+	widths := []float64{10, 95, 20, 20, 35}
+	header := []string{"#", "Description", "SAC", "Hours", "Taxable Value"}
+	pdf.SetFillColor(lightGray[0], lightGray[1], lightGray[2])
+	pdf.SetFont("Arial", "B", 9)
+	for i, h := range header {
+		align := "L"
+		if i >= 3 {
+			align = "R"
+		}
+		pdf.CellFormat(widths[i], 8, h, "1", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(widths[0], 8, "1", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[1], 8, inv.Description, "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[2], 8, inv.SACCode, "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[3], 8, fmt.Sprintf("%.2f", inv.Hours), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], 8, inv.TaxableValue.String(), "1", 1, "R", false, 0, "")
+
+	// TAX SUMMARY
+	rows := [][2]string{{"Taxable value", inv.TaxableValue.String()}}
+	if inv.IGSTRate > 0 {
+		rows = append(rows, [2]string{"IGST @ " + rate(inv.IGSTRate), inv.IGSTAmount.String()})
+	} else {
+		rows = append(rows,
+			[2]string{"CGST @ " + rate(inv.CGSTRate), inv.CGSTAmount.String()},
+			[2]string{"SGST @ " + rate(inv.SGSTRate), inv.SGSTAmount.String()},
+		)
+	}
+	for _, r := range rows {
+		pdf.SetX(120)
+		pdf.CellFormat(40, 7, r[0], "1", 0, "L", false, 0, "")
+		pdf.CellFormat(35, 7, r[1], "1", 1, "R", false, 0, "")
+	}
+	pdf.SetX(120)
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(40, 8, "Total (INR)", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(35, 8, inv.Total.String(), "1", 1, "R", true, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Arial", "", 9)
+	pdf.MultiCell(180, 5, "Amount in words: "+AmountInWords(inv.Total), "", "L", false)
+	pdf.MultiCell(180, 5, "Tax payable on reverse charge: No", "", "L", false)
+	pdf.Ln(8)
+
+	// FOOTER
+	pdf.SetFont("Arial", "", 8)
+	pdf.SetTextColor(gray[0], gray[1], gray[2])
+	pdf.MultiCell(180, 4, "This is a computer generated document and does not require a signature. Issued via SportGrid on behalf of "+inv.SupplierName+".", "", "C", false)
+
+	if pdf.Err() {
+		return nil, pdf.Error()
+	}
+	return pdf, nil
+}
@@ -0,0 +1,180 @@
+// invoice/invoice_repository.go
+package invoice
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// FindTaxProfile returns the venue's GST details, or sql.ErrNoRows when it has none
+func FindTaxProfile(venueID int64) (*TaxProfile, error) {
+	var p TaxProfile
+	query := `
+		SELECT venue_id, legal_name, gstin, address, state_code, gst_rate, sac_code, invoice_prefix, updated_at
+		FROM venue_tax_profiles WHERE venue_id = ?
+	`
+	err := db.DB.QueryRow(query, venueID).Scan(
+		&p.VenueID, &p.LegalName, &p.GSTIN, &p.Address, &p.StateCode, &p.GSTRate, &p.SACCode, &p.InvoicePrefix, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpsertTaxProfile creates or replaces the venue's GST details
+func UpsertTaxProfile(p *TaxProfile) error {
+	query := `
+		INSERT INTO venue_tax_profiles (venue_id, legal_name, gstin, address, state_code, gst_rate, sac_code, invoice_prefix)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			legal_name = VALUES(legal_name), gstin = VALUES(gstin), address = VALUES(address),
+			state_code = VALUES(state_code), gst_rate = VALUES(gst_rate), sac_code = VALUES(sac_code),
+			invoice_prefix = VALUES(invoice_prefix)
+	`
+	_, err := db.DB.Exec(query, p.VenueID, p.LegalName, p.GSTIN, p.Address, p.StateCode, p.GSTRate, p.SACCode, p.InvoicePrefix)
+	if err != nil {
+		log.Println("Error saving tax profile:", err)
+	}
+	return err
+}
+
+// PrefixTaken reports whether another venue registered under the same GSTIN already uses the prefix
+func PrefixTaken(venueID int64, gstin, prefix string) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM venue_tax_profiles WHERE gstin = ? AND invoice_prefix = ? AND venue_id <> ?`
+	err := db.DB.QueryRow(query, gstin, prefix, venueID).Scan(&count)
+	return count > 0, err
+}
+
+// nextNumberTx hands out the next number in a venue's series for a financial year.
+// The counter row stays locked until the transaction ends, so numbers never skip or repeat.
+func nextNumberTx(tx *sql.Tx, venueID int64, fy, docType string) (int64, error) {
+	query := `
+		INSERT INTO invoice_sequences (venue_id, financial_year, doc_type, last_number)
+		VALUES (?, ?, ?, 1)
+		ON DUPLICATE KEY UPDATE last_number = last_number + 1
+	`
+	if _, err := tx.Exec(query, venueID, fy, docType); err != nil {
+		log.Println("Error advancing invoice sequence:", err)
+		return 0, err
+	}
+	var n int64
+	err := tx.QueryRow(`SELECT last_number FROM invoice_sequences WHERE venue_id = ? AND financial_year = ? AND doc_type = ?`,
+		venueID, fy, docType).Scan(&n)
+	return n, err
+}
+
+// insertInvoiceTx stores an issued document
+func insertInvoiceTx(tx *sql.Tx, inv *Invoice) error {
+	query := `
+		INSERT INTO invoices (
+			venue_id, booking_id, user_id, doc_type, number, financial_year, original_invoice_id, issued_at,
+			supplier_name, supplier_gstin, supplier_address, supplier_state,
+			buyer_name, buyer_gstin, buyer_address, buyer_state,
+			place_of_supply, sac_code, description, hours,
+			taxable_value, cgst_rate, cgst_amount, sgst_rate, sgst_amount, igst_rate, igst_amount, total
+		) VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, 0), ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''),
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.Exec(query,
+		inv.VenueID, inv.BookingID, inv.UserID, inv.DocType, inv.Number, inv.FinancialYear, inv.OriginalInvoiceID, inv.IssuedAt,
+		inv.SupplierName, inv.SupplierGSTIN, inv.SupplierAddress, inv.SupplierState,
+		inv.BuyerName, inv.BuyerGSTIN, inv.BuyerAddress, inv.BuyerState,
+		inv.PlaceOfSupply, inv.SACCode, inv.Description, inv.Hours,
+		inv.TaxableValue, inv.CGSTRate, inv.CGSTAmount, inv.SGSTRate, inv.SGSTAmount, inv.IGSTRate, inv.IGSTAmount, inv.Total,
+	)
+	if err != nil {
+		log.Println("Error inserting invoice:", err)
+		return err
+	}
+	inv.ID, _ = result.LastInsertId()
+	return nil
+}
+
+const invoiceColumns = `
+	i.id, i.venue_id, i.booking_id, i.user_id, i.doc_type, i.number, i.financial_year,
+	COALESCE(i.original_invoice_id, 0), COALESCE(o.number, ''), o.issued_at, i.issued_at,
+	i.supplier_name, i.supplier_gstin, i.supplier_address, i.supplier_state,
+	i.buyer_name, COALESCE(i.buyer_gstin, ''), COALESCE(i.buyer_address, ''), COALESCE(i.buyer_state, ''),
+	i.place_of_supply, i.sac_code, i.description, i.hours,
+	i.taxable_value, i.cgst_rate, i.cgst_amount, i.sgst_rate, i.sgst_amount, i.igst_rate, i.igst_amount, i.total, i.created_at
+`
+
+const invoiceFrom = ` FROM invoices i LEFT JOIN invoices o ON i.original_invoice_id = o.id `
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvoice(row rowScanner) (*Invoice, error) {
+	var inv Invoice
+	var originalIssued sql.NullTime
+	err := row.Scan(
+		&inv.ID, &inv.VenueID, &inv.BookingID, &inv.UserID, &inv.DocType, &inv.Number, &inv.FinancialYear,
+		&inv.OriginalInvoiceID, &inv.OriginalNumber, &originalIssued, &inv.IssuedAt,
+		&inv.SupplierName, &inv.SupplierGSTIN, &inv.SupplierAddress, &inv.SupplierState,
+		&inv.BuyerName, &inv.BuyerGSTIN, &inv.BuyerAddress, &inv.BuyerState,
+		&inv.PlaceOfSupply, &inv.SACCode, &inv.Description, &inv.Hours,
+		&inv.TaxableValue, &inv.CGSTRate, &inv.CGSTAmount, &inv.SGSTRate, &inv.SGSTAmount, &inv.IGSTRate, &inv.IGSTAmount, &inv.Total, &inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if originalIssued.Valid {
+		inv.OriginalIssuedAt = &originalIssued.Time
+	}
+	return &inv, nil
+}
+
+func queryInvoices(query string, args ...interface{}) ([]Invoice, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching invoices:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Invoice, 0)
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			log.Println("Error scanning invoice:", err)
+			continue
+		}
+		list = append(list, *inv)
+	}
+	return list, nil
+}
+
+// FindInvoiceByID returns one document
+func FindInvoiceByID(id int64) (*Invoice, error) {
+	return scanInvoice(db.DB.QueryRow(`SELECT `+invoiceColumns+invoiceFrom+`WHERE i.id = ?`, id))
+}
+
+// FindInvoicesByBookingID lists the invoice and any credit notes for a booking, oldest first
+func FindInvoicesByBookingID(bookingID int64) ([]Invoice, error) {
+	return queryInvoices(`SELECT `+invoiceColumns+invoiceFrom+`WHERE i.booking_id = ? ORDER BY i.issued_at ASC, i.id ASC`, bookingID)
+}
+
+// FindInvoicesByVenueID is the venue's register for a financial year
+func FindInvoicesByVenueID(venueID int64, fy string) ([]Invoice, error) {
+	return queryInvoices(`SELECT `+invoiceColumns+invoiceFrom+`WHERE i.venue_id = ? AND i.financial_year = ? ORDER BY i.doc_type ASC, i.id ASC`, venueID, fy)
+}
+
+// findBookingInvoiceTx locks the booking's tax invoice, if one was issued
+func findBookingInvoiceTx(tx *sql.Tx, bookingID int64) (*Invoice, error) {
+	query := `SELECT ` + invoiceColumns + invoiceFrom + `WHERE i.booking_id = ? AND i.doc_type = 'invoice' FOR UPDATE`
+	return scanInvoice(tx.QueryRow(query, bookingID))
+}
+
+// creditedTotalTx sums what credit notes already reversed against an invoice
+func creditedTotalTx(tx *sql.Tx, invoiceID int64) (money.Money, error) {
+	var total money.Money
+	err := tx.QueryRow(`SELECT COALESCE(SUM(total), 0) FROM invoices WHERE original_invoice_id = ?`, invoiceID).Scan(&total)
+	return total, err
+}
@@ -0,0 +1,299 @@
+// invoice/invoice_service.go
+package invoice
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+var (
+	gstinPattern  = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+	sacPattern    = regexp.MustCompile(`^[0-9]{4,8}$`)
+	prefixPattern = regexp.MustCompile(`^[A-Z0-9]{1,4}$`)
+)
+
+const gstinChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// ValidateGSTIN checks the format, the state code and the check character
+func ValidateGSTIN(gstin string) error {
+	if !gstinPattern.MatchString(gstin) {
+		return ErrInvalidGSTIN
+	}
+	if _, ok := stateNames[gstin[:2]]; !ok {
+		return ErrInvalidGSTIN
+	}
+	sum := 0
+	for i := 0; i < 14; i++ {
+		product := strings.IndexByte(gstinChars, gstin[i]) * (i%2 + 1)
+		sum += product/36 + product%36
+	}
+	if gstinChars[(36-sum%36)%36] != gstin[14] {
+		return ErrInvalidGSTIN
+	}
+	return nil
+}
+
+// SaveTaxProfile validates and stores a venue's GST registration
+func SaveTaxProfile(p *TaxProfile) (*TaxProfile, error) {
+	p.LegalName = strings.TrimSpace(p.LegalName)
+	p.Address = strings.TrimSpace(p.Address)
+	p.GSTIN = strings.ToUpper(strings.TrimSpace(p.GSTIN))
+	p.InvoicePrefix = strings.ToUpper(strings.TrimSpace(p.InvoicePrefix))
+
+	if p.LegalName == "" || p.Address == "" {
+		return nil, errors.New("legal name and address are required")
+	}
+	if err := ValidateGSTIN(p.GSTIN); err != nil {
+		return nil, err
+	}
+	if p.StateCode == "" {
+		p.StateCode = p.GSTIN[:2]
+	}
+	if _, ok := stateNames[p.StateCode]; !ok {
+		return nil, errors.New("invalid state code")
+	}
+	if p.GSTRate == 0 {
+		p.GSTRate = DefaultGSTRate
+	}
+	switch p.GSTRate {
+	case 5, 12, 18, 28:
+	default:
+		return nil, errors.New("GST rate must be 5, 12, 18 or 28")
+	}
+	if p.SACCode == "" {
+		p.SACCode = DefaultSACCode
+	}
+	if !sacPattern.MatchString(p.SACCode) {
+		return nil, errors.New("SAC code must be 4 to 8 digits")
+	}
+	if p.InvoicePrefix == "" {
+		p.InvoicePrefix = DefaultPrefix
+	}
+	if !prefixPattern.MatchString(p.InvoicePrefix) {
+		return nil, errors.New("invoice prefix must be 1 to 4 letters or digits")
+	}
+
+	// Invoice numbers must be unique per GSTIN, and each venue keeps its own counter
+	taken, err := PrefixTaken(p.VenueID, p.GSTIN, p.InvoicePrefix)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, errors.New("another venue under this GSTIN already uses this invoice prefix")
+	}
+
+	if err := UpsertTaxProfile(p); err != nil {
+		return nil, err
+	}
+	return FindTaxProfile(p.VenueID)
+}
+
+// FinancialYear returns the Indian financial year (April to March) containing t,
+// as a label ("2026-27") and the short form used in document numbers ("2627")
+func FinancialYear(t time.Time) (string, string) {
+	t = t.In(venue.IST)
+	start := t.Year()
+	if t.Month() < time.April {
+		start--
+	}
+	end := (start + 1) % 100
+	return fmt.Sprintf("%d-%02d", start, end), fmt.Sprintf("%02d%02d", start%100, end)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// applyTax splits a GST-inclusive amount into taxable value and tax.
+// Court bookings are services tied to immovable property, so the place of supply is the
+// venue's state whoever the buyer is; IGST applies only when the supplier is registered
+// in a different state from the venue.
+func applyTax(inv *Invoice, amount money.Money, rate float64) {
+	inv.Total = amount
+	inv.TaxableValue = amount.Mul(100 / (100 + rate))
+	tax := inv.Total - inv.TaxableValue
+
+	if inv.SupplierGSTIN[:2] == inv.PlaceOfSupply {
+		inv.CGSTRate, inv.SGSTRate = rate/2, rate/2
+		inv.CGSTAmount = tax.Mul(0.5)
+		inv.SGSTAmount = tax - inv.CGSTAmount
+	} else {
+		inv.IGSTRate = rate
+		inv.IGSTAmount = tax
+	}
+}
+
+// rateOf recovers the total GST rate an issued invoice used
+func rateOf(inv *Invoice) float64 {
+	return inv.CGSTRate + inv.SGSTRate + inv.IGSTRate
+}
+
+// IssueInvoice creates the tax invoice for a paid booking. A booking gets one invoice;
+// later refunds are handled with credit notes against it.
+func IssueInvoice(supply Supply, buyer Buyer) (*Invoice, error) {
+	if supply.Amount <= 0 {
+		return nil, ErrNothingToInvoice
+	}
+	profile, err := FindTaxProfile(supply.VenueID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoTaxProfile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	buyer.Name = strings.TrimSpace(buyer.Name)
+	buyer.GSTIN = strings.ToUpper(strings.TrimSpace(buyer.GSTIN))
+	buyer.Address = strings.TrimSpace(buyer.Address)
+	if buyer.Name == "" {
+		return nil, errors.New("buyer name is required")
+	}
+	if buyer.GSTIN != "" {
+		if err := ValidateGSTIN(buyer.GSTIN); err != nil {
+			return nil, errors.New("invalid buyer GSTIN")
+		}
+		if buyer.Address == "" {
+			return nil, errors.New("buyer address is required with a GSTIN")
+		}
+		buyer.StateCode = buyer.GSTIN[:2]
+	} else if buyer.StateCode != "" {
+		if _, ok := stateNames[buyer.StateCode]; !ok {
+			return nil, errors.New("invalid buyer state code")
+		}
+	}
+
+	now := time.Now()
+	fy, fyShort := FinancialYear(now)
+	inv := &Invoice{
+		VenueID:         supply.VenueID,
+		BookingID:       supply.BookingID,
+		UserID:          supply.UserID,
+		DocType:         DocInvoice,
+		FinancialYear:   fy,
+		IssuedAt:        now,
+		SupplierName:    profile.LegalName,
+		SupplierGSTIN:   profile.GSTIN,
+		SupplierAddress: profile.Address,
+		SupplierState:   profile.GSTIN[:2],
+		BuyerName:       buyer.Name,
+		BuyerGSTIN:      buyer.GSTIN,
+		BuyerAddress:    buyer.Address,
+		BuyerState:      buyer.StateCode,
+		PlaceOfSupply:   profile.StateCode,
+		SACCode:         profile.SACCode,
+		Description:     supply.Description,
+		Hours:           supply.Hours,
+	}
+	applyTax(inv, supply.Amount, profile.GSTRate)
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Taking the number first serializes invoicing per venue, so the duplicate check below can't race
+	n, err := nextNumberTx(tx, supply.VenueID, fy, DocInvoice)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := findBookingInvoiceTx(tx, supply.BookingID); err == nil {
+		return nil, ErrAlreadyInvoiced
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+	inv.Number = fmt.Sprintf("%s/%s/%05d", profile.InvoicePrefix, fyShort, n)
+
+	if err := insertInvoiceTx(tx, inv); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// IssueCreditNoteTx reverses up to amount (GST-inclusive) of a booking's invoice.
+// Bookings that were never invoiced have nothing to reverse, so it returns nil, nil.
+func IssueCreditNoteTx(tx *sql.Tx, bookingID int64, amount money.Money) (*Invoice, error) {
+	original, err := findBookingInvoiceTx(tx, bookingID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	credited, err := creditedTotalTx(tx, original.ID)
+	if err != nil {
+		return nil, err
+	}
+	amount = money.Min(amount, original.Total-credited)
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	// Credit notes are numbered in the year they are issued, which may be after the invoice's
+	profile, err := FindTaxProfile(original.VenueID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	fy, fyShort := FinancialYear(now)
+	n, err := nextNumberTx(tx, original.VenueID, fy, DocCreditNote)
+	if err != nil {
+		return nil, err
+	}
+
+	note := *original
+	note.ID = 0
+	note.DocType = DocCreditNote
+	note.FinancialYear = fy
+	note.Number = fmt.Sprintf("%sC/%s/%05d", profile.InvoicePrefix, fyShort, n)
+	note.OriginalInvoiceID = original.ID
+	note.OriginalNumber = original.Number
+	note.OriginalIssuedAt = &original.IssuedAt
+	note.IssuedAt = now
+	note.CGSTRate, note.CGSTAmount, note.SGSTRate, note.SGSTAmount, note.IGSTRate, note.IGSTAmount = 0, 0, 0, 0, 0, 0
+	note.Hours = round2(original.Hours * float64(amount) / float64(original.Total))
+	applyTax(&note, amount, rateOf(original))
+
+	if err := insertInvoiceTx(tx, &note); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// IssueCreditNote is IssueCreditNoteTx in its own transaction
+func IssueCreditNote(bookingID int64, amount money.Money) (*Invoice, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	note, err := IssueCreditNoteTx(tx, bookingID, amount)
+	if err != nil {
+		return nil, err
+	}
+	return note, tx.Commit()
+}
+
+// CanAccessInvoice reports whether a user may download a document: the buyer,
+// the venue's owner or staff, or an admin
+func CanAccessInvoice(inv *Invoice, userID int64, role string) bool {
+	if role == "admin" || inv.UserID == userID {
+		return true
+	}
+	return venue.VerifyVenueStaffAccess(inv.VenueID, userID) == nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/JkD004/playarena-backend/venue"
 	"github.com/JkD004/playarena-backend/user"
 	"github.com/JkD004/playarena-backend/payment"
+	"github.com/JkD004/playarena-backend/pkg/ticket"
 	"github.com/JkD004/playarena-backend/worker"
 
 )
@@ -67,17 +68,25 @@ func main() {
 	router := gin.Default()
 
 	// ✅ Initialize Payment System
-    payment.InitRazorpay()
+    payment.InitGateway()
+
+	// ✅ Load Ticket Signing Keys
+	ticket.Init()
 
 	// 🚀 START BACKGROUND WORKER (Run in a separate goroutine)
 	go worker.StartCleanupTask()
+	go worker.StartCalendarSyncTask()
+	go worker.StartSettlementTask()
+	go worker.StartPaymentReconcileTask()
+	go worker.StartRefundRetryTask()
 
 
 	// ✅ CORS Configuration
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true 
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "Idempotency-Key"}
+	config.ExposeHeaders = []string{"Idempotent-Replayed"}
 	router.Use(cors.New(config))
 
 	// ✅ Set up routes
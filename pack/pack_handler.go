@@ -0,0 +1,189 @@
+// pack/pack_handler.go
+package pack
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/JkD004/playarena-backend/venue"
+	"github.com/gin-gonic/gin"
+)
+
+// GetVenuePackagesHandler handles GET /api/v1/venues/:id/packages
+func GetVenuePackagesHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	list, err := FindPackagesByVenueID(venueID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch packages"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// CreatePackageHandler handles POST /api/v1/venues/:id/packages
+func CreatePackageHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var p Package
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	p.VenueID = venueID
+
+	created, err := CreateNewPackage(&p)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// DeletePackageHandler handles DELETE /api/v1/packages/:id
+func DeletePackageHandler(c *gin.Context) {
+	packageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if err := RemovePackage(packageID, userID, userRole); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Package removed"})
+}
+
+// PurchasePackageHandler handles POST /api/v1/packages/:id/purchase
+func PurchasePackageHandler(c *gin.Context) {
+	packageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid package ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	purchase, err := StartPurchase(packageID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"purchase_id": purchase.ID,
+		"order_id":    purchase.RazorpayOrderID,
+		"amount":      purchase.Price,
+		"key_id":      gateway.KeyID(),
+	})
+}
+
+// VerifyPurchaseHandler handles POST /api/v1/pack-purchases/:id/verify
+func VerifyPurchaseHandler(c *gin.Context) {
+	purchaseID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase ID"})
+		return
+	}
+
+	var req struct {
+		RazorpayOrderID   string `json:"razorpay_order_id"`
+		RazorpayPaymentID string `json:"razorpay_payment_id"`
+		RazorpaySignature string `json:"razorpay_signature"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	purchase, err := CompletePurchase(purchaseID, userID, req.RazorpayOrderID, req.RazorpayPaymentID, req.RazorpaySignature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, purchase)
+}
+
+// GetMyPurchasesHandler handles GET /api/v1/packs/mine
+func GetMyPurchasesHandler(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	list, err := GetMyPurchases(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch packs"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetPurchaseUsageHandler handles GET /api/v1/pack-purchases/:id/usage
+func GetPurchaseUsageHandler(c *gin.Context) {
+	purchaseID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	purchase, usages, err := GetPurchaseUsage(purchaseID, userID, userRole)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"purchase": purchase, "usage": usages})
+}
+
+// GetVenuePurchasesHandler handles GET /api/v1/venues/:id/pack-purchases
+func GetVenuePurchasesHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	list, err := FindPurchasesByVenueID(venueID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch pack purchases"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
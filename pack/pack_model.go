@@ -0,0 +1,67 @@
+// pack/pack_model.go
+package pack
+
+import (
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Package kinds
+const (
+	KindHours     = "hours"     // N hours to use within ValidityDays
+	KindUnlimited = "unlimited" // Any number of bookings inside the time window while valid
+)
+
+// Package is something a venue sells up front: an hour pack or an unlimited pass
+type Package struct {
+	ID           int64       `json:"id"`
+	VenueID      int64       `json:"venue_id"`
+	Name         string      `json:"name"`            // e.g. "20-hour pack"
+	Kind         string      `json:"kind"`            // 'hours' or 'unlimited'
+	Hours        float64     `json:"hours,omitempty"` // Hour packs only
+	ValidityDays int         `json:"validity_days"`
+	Price        money.Money `json:"price"`
+	// Window limits when the pass can be used (unlimited passes); empty = opening hours, every day
+	Days      []int     `json:"days,omitempty"`       // 0 = Sunday ... 6 = Saturday
+	StartTime string    `json:"start_time,omitempty"` // "HH:MM" (IST)
+	EndTime   string    `json:"end_time,omitempty"`   // "HH:MM" (IST), "24:00" for midnight
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Purchase is a package bought by a player. It is 'pending' until the Razorpay payment is verified.
+type Purchase struct {
+	ID              int64       `json:"id"`
+	PackageID       int64       `json:"package_id"`
+	PackageName     string      `json:"package_name"`
+	UserID          int64       `json:"user_id"`
+	VenueID         int64       `json:"venue_id"`
+	Kind            string      `json:"kind"`
+	HoursTotal      float64     `json:"hours_total,omitempty"`
+	HoursUsed       float64     `json:"hours_used"`
+	HoursRemaining  float64     `json:"hours_remaining,omitempty"` // Hour packs only
+	Price           money.Money `json:"price"`
+	Status          string      `json:"status"` // 'pending', 'active' or 'expired'
+	ValidFrom       *time.Time  `json:"valid_from,omitempty"`
+	ValidUntil      *time.Time  `json:"valid_until,omitempty"`
+	RazorpayOrderID string      `json:"razorpay_order_id,omitempty"`
+	PaymentID       string      `json:"razorpay_payment_id,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+
+	// Copied from the package so the window can be checked without another query
+	Days      []int  `json:"days,omitempty"`
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
+}
+
+// Usage is one booking paid from a purchase
+type Usage struct {
+	ID         int64     `json:"id"`
+	PurchaseID int64     `json:"purchase_id"`
+	BookingID  int64     `json:"booking_id"`
+	StartTime  time.Time `json:"start_time"`
+	Hours      float64   `json:"hours"`
+	Status     string    `json:"status"` // 'used' or 'returned' (booking canceled in time)
+	CreatedAt  time.Time `json:"created_at"`
+}
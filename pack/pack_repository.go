@@ -0,0 +1,317 @@
+// pack/pack_repository.go
+package pack
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+func joinDays(days []int) string {
+	parts := make([]string, len(days))
+	for i, d := range days {
+		parts[i] = strconv.Itoa(d)
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitDays(s string) []int {
+	days := make([]int, 0)
+	for _, p := range strings.Split(s, ",") {
+		if d, err := strconv.Atoi(strings.TrimSpace(p)); err == nil {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// CreatePackage inserts a new package for a venue
+func CreatePackage(p *Package) error {
+	query := `
+		INSERT INTO venue_packages (venue_id, name, kind, hours, validity_days, price, days, start_time, end_time)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''))
+	`
+	result, err := db.DB.Exec(query,
+		p.VenueID, p.Name, p.Kind, p.Hours, p.ValidityDays, p.Price,
+		joinDays(p.Days), p.StartTime, p.EndTime,
+	)
+	if err != nil {
+		log.Println("Error inserting package:", err)
+		return err
+	}
+	p.ID, _ = result.LastInsertId()
+	p.IsActive = true
+	return nil
+}
+
+const packageColumns = `
+	id, venue_id, name, kind, hours, validity_days, price,
+	COALESCE(days, ''), COALESCE(start_time, ''), COALESCE(end_time, ''), is_active, created_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPackage(row rowScanner) (*Package, error) {
+	var p Package
+	var days string
+	err := row.Scan(&p.ID, &p.VenueID, &p.Name, &p.Kind, &p.Hours, &p.ValidityDays, &p.Price,
+		&days, &p.StartTime, &p.EndTime, &p.IsActive, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	p.Days = splitDays(days)
+	return &p, nil
+}
+
+// FindPackagesByVenueID lists the packages a venue currently sells
+func FindPackagesByVenueID(venueID int64) ([]Package, error) {
+	rows, err := db.DB.Query(`SELECT `+packageColumns+` FROM venue_packages WHERE venue_id = ? AND is_active = 1 ORDER BY price ASC`, venueID)
+	if err != nil {
+		log.Println("Error fetching packages:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Package, 0)
+	for rows.Next() {
+		p, err := scanPackage(rows)
+		if err != nil {
+			log.Println("Error scanning package:", err)
+			continue
+		}
+		list = append(list, *p)
+	}
+	return list, nil
+}
+
+// FindPackageByID fetches a single package
+func FindPackageByID(packageID int64) (*Package, error) {
+	return scanPackage(db.DB.QueryRow(`SELECT `+packageColumns+` FROM venue_packages WHERE id = ?`, packageID))
+}
+
+// DeactivatePackage stops selling a package; purchases already made keep working
+func DeactivatePackage(packageID int64) error {
+	_, err := db.DB.Exec(`UPDATE venue_packages SET is_active = 0 WHERE id = ?`, packageID)
+	if err != nil {
+		log.Println("Error deactivating package:", err)
+	}
+	return err
+}
+
+// CreatePurchase records a pending purchase
+func CreatePurchase(p *Purchase) error {
+	query := `
+		INSERT INTO pack_purchases (package_id, user_id, venue_id, kind, hours_total, price, status)
+		VALUES (?, ?, ?, ?, ?, ?, 'pending')
+	`
+	result, err := db.DB.Exec(query, p.PackageID, p.UserID, p.VenueID, p.Kind, p.HoursTotal, p.Price)
+	if err != nil {
+		log.Println("Error inserting pack purchase:", err)
+		return err
+	}
+	p.ID, _ = result.LastInsertId()
+	p.Status = "pending"
+	return nil
+}
+
+// SetPurchaseOrder stores the Razorpay order of a purchase
+func SetPurchaseOrder(purchaseID int64, orderID string) error {
+	_, err := db.DB.Exec(`UPDATE pack_purchases SET razorpay_order_id = ? WHERE id = ?`, orderID, purchaseID)
+	if err != nil {
+		log.Println("Error saving pack order:", err)
+	}
+	return err
+}
+
+// ActivatePurchase starts the validity of a paid purchase. Only a pending purchase is activated,
+// so verifying the same payment twice doesn't extend it.
+func ActivatePurchase(purchaseID int64, orderID, paymentID string, validFrom, validUntil time.Time) error {
+	result, err := db.DB.Exec(`
+		UPDATE pack_purchases
+		SET status = 'active', razorpay_payment_id = ?, valid_from = ?, valid_until = ?
+		WHERE id = ? AND razorpay_order_id = ? AND status = 'pending'
+	`, paymentID, validFrom, validUntil, purchaseID, orderID)
+	if err != nil {
+		log.Println("Error activating pack purchase:", err)
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.New("purchase is not waiting for this payment")
+	}
+	return nil
+}
+
+const purchaseColumns = `
+	pp.id, pp.package_id, vp.name, pp.user_id, pp.venue_id, pp.kind, pp.hours_total,
+	COALESCE((SELECT SUM(u.hours) FROM pack_usages u WHERE u.purchase_id = pp.id AND u.status = 'used'), 0),
+	pp.price, pp.status, pp.valid_from, pp.valid_until,
+	COALESCE(pp.razorpay_order_id, ''), COALESCE(pp.razorpay_payment_id, ''), pp.created_at,
+	COALESCE(vp.days, ''), COALESCE(vp.start_time, ''), COALESCE(vp.end_time, '')
+`
+
+func scanPurchase(row rowScanner) (*Purchase, error) {
+	var p Purchase
+	var validFrom, validUntil sql.NullTime
+	var days string
+	err := row.Scan(&p.ID, &p.PackageID, &p.PackageName, &p.UserID, &p.VenueID, &p.Kind, &p.HoursTotal,
+		&p.HoursUsed, &p.Price, &p.Status, &validFrom, &validUntil,
+		&p.RazorpayOrderID, &p.PaymentID, &p.CreatedAt,
+		&days, &p.StartTime, &p.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if validFrom.Valid {
+		p.ValidFrom = &validFrom.Time
+	}
+	if validUntil.Valid {
+		p.ValidUntil = &validUntil.Time
+		// Validity is computed on read, no job needed
+		if p.Status == "active" && time.Now().After(validUntil.Time) {
+			p.Status = "expired"
+		}
+	}
+	p.Days = splitDays(days)
+	if p.Kind == KindHours {
+		p.HoursRemaining = p.HoursTotal - p.HoursUsed
+	}
+	return &p, nil
+}
+
+func queryPurchases(where string, args ...interface{}) ([]Purchase, error) {
+	query := `SELECT ` + purchaseColumns + ` FROM pack_purchases pp JOIN venue_packages vp ON pp.package_id = vp.id WHERE ` + where
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching pack purchases:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Purchase, 0)
+	for rows.Next() {
+		p, err := scanPurchase(rows)
+		if err != nil {
+			log.Println("Error scanning pack purchase:", err)
+			continue
+		}
+		list = append(list, *p)
+	}
+	return list, nil
+}
+
+// FindPurchaseByID fetches a single purchase
+func FindPurchaseByID(purchaseID int64) (*Purchase, error) {
+	list, err := queryPurchases(`pp.id = ?`, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &list[0], nil
+}
+
+// FindPurchaseByOrderID fetches the purchase a Razorpay order was created for
+func FindPurchaseByOrderID(orderID string) (*Purchase, error) {
+	list, err := queryPurchases(`pp.razorpay_order_id = ?`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &list[0], nil
+}
+
+// FindPurchasesByUserID lists a player's purchases, newest first
+func FindPurchasesByUserID(userID int64) ([]Purchase, error) {
+	return queryPurchases(`pp.user_id = ? AND pp.status <> 'pending' ORDER BY pp.created_at DESC`, userID)
+}
+
+// FindPurchasesByVenueID lists the paid purchases at a venue, newest first
+func FindPurchasesByVenueID(venueID int64) ([]Purchase, error) {
+	return queryPurchases(`pp.venue_id = ? AND pp.status <> 'pending' ORDER BY pp.created_at DESC`, venueID)
+}
+
+// FindActivePurchases returns the player's usable purchases at a venue, soonest expiry first
+func FindActivePurchases(userID, venueID int64, at time.Time) ([]Purchase, error) {
+	return queryPurchases(
+		`pp.user_id = ? AND pp.venue_id = ? AND pp.status = 'active' AND pp.valid_from <= ? AND pp.valid_until > ? ORDER BY pp.valid_until ASC`,
+		userID, venueID, at, at,
+	)
+}
+
+// DrawDownTx records the booking against the purchase, re-checking the balance under a lock
+func DrawDownTx(tx *sql.Tx, purchaseID, bookingID int64, hours float64) error {
+	var kind, status string
+	var hoursTotal float64
+	err := tx.QueryRow(`SELECT kind, hours_total, status FROM pack_purchases WHERE id = ? FOR UPDATE`, purchaseID).
+		Scan(&kind, &hoursTotal, &status)
+	if err != nil {
+		log.Println("Error locking pack purchase:", err)
+		return err
+	}
+	if status != "active" {
+		return ErrPackNotUsable
+	}
+
+	if kind == KindHours {
+		var used float64
+		err := tx.QueryRow(`SELECT COALESCE(SUM(hours), 0) FROM pack_usages WHERE purchase_id = ? AND status = 'used'`, purchaseID).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used+hours > hoursTotal+1e-9 {
+			return ErrPackNotUsable
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO pack_usages (purchase_id, booking_id, hours, status) VALUES (?, ?, ?, 'used')`, purchaseID, bookingID, hours)
+	if err != nil {
+		log.Println("Error recording pack usage:", err)
+	}
+	return err
+}
+
+// ReturnUsageTx gives the hours of a canceled booking back to its purchase (no-op for other bookings)
+func ReturnUsageTx(tx *sql.Tx, bookingID int64) error {
+	_, err := tx.Exec(`UPDATE pack_usages SET status = 'returned' WHERE booking_id = ? AND status = 'used'`, bookingID)
+	if err != nil {
+		log.Println("Error returning pack usage:", err)
+	}
+	return err
+}
+
+// FindUsagesByPurchaseID lists the bookings paid from a purchase, newest first
+func FindUsagesByPurchaseID(purchaseID int64) ([]Usage, error) {
+	query := `
+		SELECT u.id, u.purchase_id, u.booking_id, b.start_time, u.hours, u.status, u.created_at
+		FROM pack_usages u
+		JOIN bookings b ON u.booking_id = b.id
+		WHERE u.purchase_id = ?
+		ORDER BY b.start_time DESC
+	`
+	rows, err := db.DB.Query(query, purchaseID)
+	if err != nil {
+		log.Println("Error fetching pack usage:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Usage, 0)
+	for rows.Next() {
+		var u Usage
+		if err := rows.Scan(&u.ID, &u.PurchaseID, &u.BookingID, &u.StartTime, &u.Hours, &u.Status, &u.CreatedAt); err != nil {
+			log.Println("Error scanning pack usage:", err)
+			continue
+		}
+		list = append(list, u)
+	}
+	return list, nil
+}
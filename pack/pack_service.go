@@ -0,0 +1,260 @@
+// pack/pack_service.go
+package pack
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/JkD004/playarena-backend/settlement"
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// ErrPackNotUsable is returned when a purchase can no longer cover a booking
+var ErrPackNotUsable = errors.New("pack cannot cover this booking")
+
+// CreateNewPackage validates and stores a package for a venue
+func CreateNewPackage(p *Package) (*Package, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return nil, errors.New("package name is required")
+	}
+	switch p.Kind {
+	case KindHours:
+		if p.Hours <= 0 || p.Hours > 1000 {
+			return nil, errors.New("hours must be between 0 and 1000")
+		}
+	case KindUnlimited:
+		p.Hours = 0
+	default:
+		return nil, errors.New("kind must be 'hours' or 'unlimited'")
+	}
+	if p.ValidityDays < 1 || p.ValidityDays > 366 {
+		return nil, errors.New("validity_days must be between 1 and 366")
+	}
+	if p.Price < money.FromPaise(100) {
+		return nil, errors.New("price must be at least ₹1")
+	}
+
+	for _, d := range p.Days {
+		if d < 0 || d > 6 {
+			return nil, errors.New("days must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if (p.StartTime == "") != (p.EndTime == "") {
+		return nil, errors.New("start_time and end_time must be set together")
+	}
+	if p.StartTime != "" {
+		start, err := venue.ParseClock(p.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		end, err := venue.ParseClock(p.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, errors.New("end_time must be after start_time")
+		}
+	}
+
+	if err := CreatePackage(p); err != nil {
+		return nil, errors.New("failed to create package")
+	}
+	return p, nil
+}
+
+// RemovePackage stops selling a package after checking the caller may manage its venue
+func RemovePackage(packageID, userID int64, userRole string) error {
+	p, err := FindPackageByID(packageID)
+	if err != nil {
+		return errors.New("package not found")
+	}
+	if userRole != "admin" {
+		if err := venue.VerifyVenueOwnership(p.VenueID, userID); err != nil {
+			return err
+		}
+	}
+	return DeactivatePackage(packageID)
+}
+
+// StartPurchase creates a pending purchase and its Razorpay order
+func StartPurchase(packageID, userID int64) (*Purchase, error) {
+	p, err := FindPackageByID(packageID)
+	if err != nil || !p.IsActive {
+		return nil, errors.New("package not found")
+	}
+
+	purchase := &Purchase{
+		PackageID:   p.ID,
+		PackageName: p.Name,
+		UserID:      userID,
+		VenueID:     p.VenueID,
+		Kind:        p.Kind,
+		HoursTotal:  p.Hours,
+		Price:       p.Price,
+	}
+	if err := CreatePurchase(purchase); err != nil {
+		return nil, errors.New("failed to start purchase")
+	}
+
+	order, err := gateway.CreateOrderFor(gateway.PurposePack, purchase.ID, userID, p.Price)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetPurchaseOrder(purchase.ID, order.OrderID); err != nil {
+		return nil, errors.New("failed to start purchase")
+	}
+	purchase.RazorpayOrderID = order.OrderID
+	return purchase, nil
+}
+
+// CompletePurchase verifies the Razorpay payment and activates the purchase from now
+func CompletePurchase(purchaseID, userID int64, orderID, paymentID, signature string) (*Purchase, error) {
+	if _, err := gateway.VerifyCheckout(gateway.PurposePack, purchaseID, orderID, paymentID, signature); err != nil {
+		return nil, err
+	}
+
+	purchase, err := FindPurchaseByID(purchaseID)
+	if err != nil || purchase.UserID != userID {
+		return nil, errors.New("purchase not found")
+	}
+	if purchase.Status != "pending" {
+		return purchase, nil // Already verified
+	}
+
+	if err := activatePaidPurchase(purchase, orderID, paymentID); err != nil {
+		// The payment webhook may have activated it a moment earlier
+		if latest, findErr := FindPurchaseByID(purchase.ID); findErr == nil && latest.Status != "pending" {
+			return latest, nil
+		}
+		return nil, err
+	}
+	_ = gateway.MarkOrderPaid(orderID)
+	return FindPurchaseByID(purchase.ID)
+}
+
+// ConfirmPurchaseByOrder activates the purchase a captured Razorpay order belongs to (payment webhook).
+// It returns false when no purchase uses the order.
+func ConfirmPurchaseByOrder(orderID, paymentID string) (bool, error) {
+	purchase, err := FindPurchaseByOrderID(orderID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if purchase.Status != "pending" {
+		return true, nil // Already activated
+	}
+	return true, activatePaidPurchase(purchase, orderID, paymentID)
+}
+
+// activatePaidPurchase starts the validity period and credits the venue owner
+func activatePaidPurchase(purchase *Purchase, orderID, paymentID string) error {
+	p, err := FindPackageByID(purchase.PackageID)
+	if err != nil {
+		return errors.New("package not found")
+	}
+	validFrom := time.Now()
+	validUntil := validFrom.AddDate(0, 0, p.ValidityDays)
+	if err := ActivatePurchase(purchase.ID, orderID, paymentID, validFrom, validUntil); err != nil {
+		return err
+	}
+
+	// Credit the venue owner's settlement ledger
+	err = settlement.RecordSale(settlement.Sale{
+		Type:           settlement.EntryPackPurchase,
+		Reference:      fmt.Sprintf("pack:%d", purchase.ID),
+		VenueID:        purchase.VenueID,
+		PackPurchaseID: purchase.ID,
+		Gross:          purchase.Price,
+		GatewayCharged: purchase.Price,
+	})
+	if err != nil {
+		log.Println("Error recording pack sale for settlement:", err)
+	}
+	return nil
+}
+
+// covers tells whether the purchase's time window includes the whole booking (IST)
+func covers(p *Purchase, start, end time.Time) bool {
+	s, e := start.In(venue.IST), end.In(venue.IST)
+	if len(p.Days) > 0 {
+		ok := false
+		for _, d := range p.Days {
+			if int(s.Weekday()) == d {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if p.StartTime == "" {
+		return true
+	}
+
+	windowStart, _ := venue.ParseClock(p.StartTime)
+	windowEnd, _ := venue.ParseClock(p.EndTime)
+	startMin := s.Hour()*60 + s.Minute()
+	endMin := startMin + int(e.Sub(s).Minutes())
+	return startMin >= windowStart && endMin <= windowEnd
+}
+
+// FindApplicable returns the purchase that should pay for a booking, or nil if none can.
+// When several fit, the one expiring first is used.
+func FindApplicable(userID, venueID int64, start, end time.Time) (*Purchase, error) {
+	purchases, err := FindActivePurchases(userID, venueID, start)
+	if err != nil {
+		return nil, err
+	}
+	hours := end.Sub(start).Hours()
+	for i := range purchases {
+		p := &purchases[i]
+		if p.ValidUntil == nil || end.After(*p.ValidUntil) {
+			continue
+		}
+		if p.Kind == KindHours && p.HoursRemaining+1e-9 < hours {
+			continue
+		}
+		if covers(p, start, end) {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetMyPurchases lists the player's packs with remaining balance
+func GetMyPurchases(userID int64) ([]Purchase, error) {
+	return FindPurchasesByUserID(userID)
+}
+
+// GetPurchaseUsage returns a purchase's bookings to its player, the venue owner or an admin
+func GetPurchaseUsage(purchaseID, userID int64, userRole string) (*Purchase, []Usage, error) {
+	p, err := FindPurchaseByID(purchaseID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, errors.New("purchase not found")
+		}
+		return nil, nil, err
+	}
+	if userRole != "admin" && p.UserID != userID {
+		isOwner, err := venue.IsVenueOwner(p.VenueID, userID)
+		if err != nil || !isOwner {
+			return nil, nil, errors.New("unauthorized")
+		}
+	}
+	usages, err := FindUsagesByPurchaseID(purchaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, usages, nil
+}
@@ -0,0 +1,40 @@
+// payment/fake_handler.go
+package payment
+
+import (
+	"net/http"
+
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/gin-gonic/gin"
+)
+
+// FakeCheckoutHandler handles POST /api/v1/payment/fake/checkout
+// Stands in for the Razorpay checkout when PAYMENT_GATEWAY=fake.
+// Body: {"order_id": "...", "outcome": "success" | "failure" | "delayed"}
+// A success returns the fields /payment/verify expects; the others complete through webhook events.
+func FakeCheckoutHandler(c *gin.Context) {
+	fake, ok := gateway.Provider.(*gateway.Fake)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fake gateway is not enabled"})
+		return
+	}
+
+	var req struct {
+		OrderID string `json:"order_id" binding:"required"`
+		Outcome string `json:"outcome"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_id is required"})
+		return
+	}
+	if req.Outcome == "" {
+		req.Outcome = gateway.FakeSuccess
+	}
+
+	checkout, err := fake.Pay(req.OrderID, req.Outcome)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, checkout)
+}
@@ -2,14 +2,16 @@
 package payment
 
 import (
+	"errors"
 	"net/http"
-	"os"
 	"strconv"
 	"time" // <--- Import time
 
 	"github.com/JkD004/playarena-backend/booking"
 	"github.com/JkD004/playarena-backend/gateway"
 	"github.com/gin-gonic/gin"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
 )
 
 // CreateOrderHandler: Frontend asks to start payment process
@@ -20,9 +22,9 @@ func CreateOrderHandler(c *gin.Context) {
 		return
 	}
 
-	// 1. Fetch Booking
+	// 1. Fetch Booking (only the player's own)
 	b, err := booking.FindBookingByID(bookingID)
-	if err != nil {
+	if err != nil || b.UserID != c.MustGet("userID").(int64) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
 		return
 	}
@@ -52,21 +54,152 @@ func CreateOrderHandler(c *gin.Context) {
 		return
     }
 
-	// 2. Create Razorpay Order
-	orderID, err := CreateRazorpayOrder(b.ID, b.TotalPrice)
+	// ---------------------------------------------------------
+	// 🛑 SECURITY CHECK 4: Has the slot hold run out?
+	// ---------------------------------------------------------
+	if b.HoldExpiresAt != nil && time.Now().After(*b.HoldExpiresAt) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Your hold on this slot has expired. Please book again."})
+		return
+	}
+
+	// ---------------------------------------------------------
+	// 🎟️ Optional promo code (if the booking doesn't have one yet)
+	// ---------------------------------------------------------
+	var body struct {
+		CouponCode string `json:"coupon_code"`
+		UseWallet  bool   `json:"use_wallet"` // Pay what the wallet covers, Razorpay the rest
+	}
+	_ = c.ShouldBindJSON(&body) // The body is optional
+	if body.CouponCode != "" {
+		userID := c.MustGet("userID").(int64)
+		b, err = booking.ApplyCouponToBooking(b.ID, userID, body.CouponCode)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// ---------------------------------------------------------
+	// 👛 Optional wallet payment
+	// ---------------------------------------------------------
+	if body.UseWallet && b.RescheduleOf != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reschedule top-up can't be paid from the wallet"})
+		return
+	}
+	if body.UseWallet && b.WalletAmount == 0 && b.TotalPrice > 0 {
+		userID := c.MustGet("userID").(int64)
+		b, err = booking.PayFromWallet(b.ID, userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if b.Status == booking.StatusConfirmed {
+			c.JSON(http.StatusOK, gin.H{"status": "confirmed", "amount": 0, "wallet_amount": b.WalletAmount})
+			return
+		}
+	}
+
+	// Nothing to charge: the coupon covered the whole price
+	if b.TotalPrice <= 0 {
+		userID := c.MustGet("userID").(int64)
+		if err := booking.ConfirmFreeBooking(b.ID, userID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "confirmed", "amount": 0, "discount_amount": b.DiscountAmount})
+		return
+	}
+
+	// 2. Create Razorpay Order (only for what the wallet didn't cover)
+	amount := b.TotalPrice - b.WalletAmount
+	orderID, err := CreateRazorpayOrder(b.ID, b.UserID, amount)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	// The payment webhook finds the booking through its order
+	if err := booking.SetBookingOrder(b.ID, orderID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not start payment"})
+		return
+	}
 
 	// 3. Send Order ID
 	c.JSON(http.StatusOK, gin.H{
-		"order_id": orderID,
-		"amount":   b.TotalPrice,
-		"key_id":   os.Getenv("RAZORPAY_KEY_ID"),
+		"order_id":        orderID,
+		"amount":          amount,
+		"wallet_amount":   b.WalletAmount,
+		"discount_amount": b.DiscountAmount,
+		"coupon_code":     b.CouponCode,
+		"key_id":          gateway.KeyID(),
+		"hold_expires_at": b.HoldExpiresAt,
 	})
 }
 
+// CreateSeriesOrderHandler: one Razorpay order for all held occurrences of a recurring series
+func CreateSeriesOrderHandler(c *gin.Context) {
+	seriesID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	series, err := booking.FindSeriesByID(seriesID)
+	if err != nil || series.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		return
+	}
+
+	amount, count, err := booking.GetSeriesPayableAmount(seriesID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not calculate series amount"})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Nothing to pay: the holds on this series have expired or it is already paid."})
+		return
+	}
+
+	orderID, err := CreateSeriesRazorpayOrder(seriesID, userID, amount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := booking.SetSeriesOrder(seriesID, orderID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not start payment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"order_id":    orderID,
+		"amount":      amount,
+		"occurrences": count,
+		"key_id":      gateway.KeyID(),
+	})
+}
+
+// PaymentFailedHandler: Frontend reports a failed/dismissed checkout so the slot is released right away
+func PaymentFailedHandler(c *gin.Context) {
+	var req struct {
+		BookingID int64 `json:"booking_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "booking_id is required"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	if err := booking.ReleaseBookingHold(req.BookingID, userID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "released", "message": "Slot hold released"})
+}
+
 
 
 // VerifyPaymentHandler: Frontend sends success data to verify
@@ -74,6 +207,7 @@ func CreateOrderHandler(c *gin.Context) {
 func VerifyPaymentHandler(c *gin.Context) {
 	var req struct {
 		BookingID         int64  `json:"booking_id"`
+		SeriesID          int64  `json:"series_id"` // Set instead of booking_id when paying a recurring series
 		RazorpayOrderID   string `json:"razorpay_order_id"`
 		RazorpayPaymentID string `json:"razorpay_payment_id"`
 		RazorpaySignature string `json:"razorpay_signature"`
@@ -84,18 +218,93 @@ func VerifyPaymentHandler(c *gin.Context) {
 		return
 	}
 
-	// FIX: Use the function from the gateway package
-	if !gateway.VerifySignature(req.RazorpayOrderID, req.RazorpayPaymentID, req.RazorpaySignature) {
+	// 🔒 SECURITY CHECK: the signature must be valid and the order must have been created for this booking/series
+	purpose, referenceID := gateway.PurposeBooking, req.BookingID
+	if req.SeriesID != 0 {
+		purpose, referenceID = gateway.PurposeSeries, req.SeriesID
+	}
+	order, err := gateway.VerifyCheckout(purpose, referenceID, req.RazorpayOrderID, req.RazorpayPaymentID, req.RazorpaySignature)
+	if errors.Is(err, gateway.ErrInvalidSignature) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid payment signature"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This payment does not belong to the booking"})
+		return
+	}
+
+	// The payment webhook may have got here first
+	if applied, _ := booking.IsPaymentApplied(req.RazorpayPaymentID); applied {
+		_ = gateway.MarkOrderPaid(order.OrderID)
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Payment already confirmed"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK: the order must cover what is owed now. A mismatched payment is left to the
+	// webhook and reconciliation, which refund it once the hold has run out.
+	if err := booking.CheckPaidAmount(req.BookingID, req.SeriesID, order.Amount); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Payment amount does not match the booking. If you were charged, it will be refunded automatically."})
+		return
+	}
+
+	// Recurring series: one payment confirms every held occurrence
+	if req.SeriesID != 0 {
+		if err := booking.ConfirmSeries(req.SeriesID, req.RazorpayPaymentID); err != nil {
+			if errors.Is(err, booking.ErrHoldLost) {
+				respondHoldLost(c, order.OrderID, req.RazorpayPaymentID, order.Amount)
+				return
+			}
+			if applied, _ := booking.IsPaymentApplied(req.RazorpayPaymentID); applied {
+				_ = gateway.MarkOrderPaid(order.OrderID)
+				c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Series confirmed"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "DB update failed"})
+			return
+		}
+		_ = gateway.MarkOrderPaid(order.OrderID)
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Series confirmed"})
+		return
+	}
+
+	// Reschedule top-up: the payment moves the original booking instead of confirming the hold
+	if hold, err := booking.FindBookingByID(req.BookingID); err == nil && hold.RescheduleOf != 0 {
+		if err := booking.CompleteReschedule(req.BookingID, req.RazorpayPaymentID); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not move the booking, the payment will be refunded"})
+			return
+		}
+		_ = gateway.MarkOrderPaid(order.OrderID)
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Booking rescheduled", "booking_id": hold.RescheduleOf})
+		return
+	}
 
 	// Confirm Booking
-	err := booking.ConfirmBookingPayment(req.BookingID, req.RazorpayPaymentID)
+	err = booking.ConfirmBookingPayment(req.BookingID, req.RazorpayPaymentID)
+	if errors.Is(err, booking.ErrPaymentAlreadyApplied) {
+		_ = gateway.MarkOrderPaid(order.OrderID)
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Booking confirmed"})
+		return
+	}
+	if errors.Is(err, booking.ErrHoldLost) {
+		respondHoldLost(c, order.OrderID, req.RazorpayPaymentID, order.Amount)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB update failed"})
 		return
 	}
+	_ = gateway.MarkOrderPaid(order.OrderID)
+	booking.AfterPaymentConfirmed(req.BookingID)
 
 	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Booking confirmed"})
-}
\ No newline at end of file
+}
+// respondHoldLost refunds a payment that arrived after its slot was taken and tells the player
+func respondHoldLost(c *gin.Context, orderID, paymentID string, amount money.Money) {
+	if err := booking.RefundLostHold(orderID, paymentID, amount); err != nil {
+		// The webhook and reconciliation retry the refund
+		c.JSON(http.StatusConflict, gin.H{"error": "Your hold on this slot expired and it was booked by someone else. The payment will be refunded."})
+		return
+	}
+	_ = gateway.MarkOrderRefunded(orderID)
+	c.JSON(http.StatusConflict, gin.H{"error": "Your hold on this slot expired and it was booked by someone else. The payment is being refunded."})
+}
@@ -2,44 +2,44 @@
 package payment
 
 import (
-	"errors"
-	"os"
+	"github.com/JkD004/playarena-backend/gateway"
 
-	"github.com/razorpay/razorpay-go"
+	"github.com/JkD004/playarena-backend/pkg/money"
 )
 
-var client *razorpay.Client
+// InitGateway sets up the payment provider (see gateway.Init). With the fake provider,
+// its simulated webhook events are applied in-process.
+func InitGateway() {
+	gateway.Init()
+	if fake, ok := gateway.Provider.(*gateway.Fake); ok {
+		fake.OnEvent = deliverFakeEvent
+	}
+}
 
-// InitRazorpay initializes the global Razorpay client
-func InitRazorpay() {
-	client = razorpay.NewClient(os.Getenv("RAZORPAY_KEY_ID"), os.Getenv("RAZORPAY_KEY_SECRET"))
+// FakeGatewayEnabled reports whether payments are being simulated
+func FakeGatewayEnabled() bool {
+	_, ok := gateway.Provider.(*gateway.Fake)
+	return ok
 }
 
 // CreateRazorpayOrder creates an order ID for the frontend checkout
-func CreateRazorpayOrder(bookingID int64, amount float64) (string, error) {
-	// Razorpay expects amount in paise (multiply by 100)
-	amountInPaise := int(amount * 100)
-
-	data := map[string]interface{}{
-		"amount":   amountInPaise,
-		"currency": "INR",
-		"receipt":  "receipt_booking_" + string(rune(bookingID)), // simple ID conversion
-		"payment_capture": 1,
-	}
-
-	body, err := client.Order.Create(data, nil)
+func CreateRazorpayOrder(bookingID, userID int64, amount money.Money) (string, error) {
+	o, err := gateway.CreateOrderFor(gateway.PurposeBooking, bookingID, userID, amount)
 	if err != nil {
-		return "", errors.New("failed to create razorpay order: " + err.Error())
+		return "", err
 	}
+	return o.OrderID, nil
+}
 
-	orderID, ok := body["id"].(string)
-	if !ok {
-		return "", errors.New("invalid response from razorpay")
+// CreateSeriesRazorpayOrder creates one order covering every held occurrence of a series
+func CreateSeriesRazorpayOrder(seriesID, userID int64, amount money.Money) (string, error) {
+	o, err := gateway.CreateOrderFor(gateway.PurposeSeries, seriesID, userID, amount)
+	if err != nil {
+		return "", err
 	}
-
-	return orderID, nil
+	return o.OrderID, nil
 }
 
 
-// NOTE: Refund logic has moved to the 'gateway' package.
+// NOTE: Order creation and refund logic have moved to the 'gateway' package.
 // NOTE: Verification logic has moved to 'payment_handler.go' using 'gateway'.
\ No newline at end of file
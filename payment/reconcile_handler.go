@@ -0,0 +1,49 @@
+// payment/reconcile_handler.go
+package payment
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPaymentOrdersHandler handles GET /api/v1/admin/payment-orders?status=attempted
+func GetPaymentOrdersHandler(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", gateway.OrderCreated, gateway.OrderAttempted, gateway.OrderPaid, gateway.OrderExpired, gateway.OrderRefunded:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	list, err := gateway.FindOrders(status, 200)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch payment orders"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetPaymentOrderHandler handles GET /api/v1/admin/payment-orders/:order_id
+func GetPaymentOrderHandler(c *gin.Context) {
+	o, err := gateway.GetOrder(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment order not found"})
+		return
+	}
+	c.JSON(http.StatusOK, o)
+}
+
+// ReconcilePaymentsHandler handles POST /api/v1/admin/payment-orders/reconcile
+// Runs the same catch-up as the background job, right away.
+func ReconcilePaymentsHandler(c *gin.Context) {
+	result, err := ReconcileOrders(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not reconcile payments"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
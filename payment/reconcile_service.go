@@ -0,0 +1,105 @@
+// payment/reconcile_service.go
+package payment
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/refund"
+)
+
+const (
+	reconcileAfter = 15 * time.Minute // Give the checkout and webhook time to finish first
+	abandonAfter   = 2 * time.Hour    // Orders with nothing captured by now are closed
+	reconcileSince = 72 * time.Hour   // Older orders are left for manual follow-up
+	reconcileBatch = 100
+)
+
+// ReconcileResult counts what a reconciliation run changed
+type ReconcileResult struct {
+	Checked  int `json:"checked"`
+	Applied  int `json:"applied"`  // Captured payments applied to their booking, pack or top-up
+	Refunded int `json:"refunded"` // Captured payments on replaced orders, given back
+	Expired  int `json:"expired"`
+	Errors   int `json:"errors"`
+}
+
+// ReconcileOrders asks the gateway about orders nothing has been applied to yet and catches up
+// on payments whose verify call and webhook were both lost
+func ReconcileOrders(now time.Time) (*ReconcileResult, error) {
+	orders, err := gateway.FindUnsettledOrders(now.Add(-reconcileSince), now.Add(-reconcileAfter), reconcileBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{}
+	for i := range orders {
+		result.Checked++
+		if err := reconcileOrder(&orders[i], now, result); err != nil {
+			log.Printf("Error reconciling order %s: %v\n", orders[i].OrderID, err)
+			result.Errors++
+		}
+	}
+	return result, nil
+}
+
+func reconcileOrder(o *gateway.Order, now time.Time, result *ReconcileResult) error {
+	payments, err := gateway.FetchOrderPayments(o.OrderID)
+	if err != nil {
+		return err
+	}
+	_ = gateway.TouchOrder(o.OrderID)
+
+	var captured *gateway.Payment
+	pending := false
+	for i := range payments {
+		p := &payments[i]
+		if err := gateway.RecordAttempt(o.OrderID, p.ID, p.Amount, p.Status, gateway.SourceReconcile, "", p.Raw); err != nil {
+			return err
+		}
+		switch p.Status {
+		case gateway.PaymentCaptured:
+			if captured == nil {
+				captured = p
+			}
+		case gateway.PaymentCreated, gateway.PaymentAuthorized:
+			pending = true
+		}
+	}
+
+	if captured == nil {
+		if !pending && now.Sub(o.CreatedAt) > abandonAfter {
+			result.Expired++
+			return gateway.MarkOrderExpired(o.OrderID)
+		}
+		return nil
+	}
+
+	status, err := applyCapturedPayment(o.OrderID, captured.ID, captured.Amount)
+	if err != nil {
+		return err
+	}
+	if status == EventProcessed {
+		result.Applied++
+		return nil
+	}
+
+	// Nothing uses the order any more, e.g. the player opened a newer checkout for the same booking
+	_, err = refund.Initiate(refund.Request{
+		Reference: "order:" + o.OrderID,
+		UserID:    o.UserID,
+		PaymentID: captured.ID,
+		Amount:    captured.Amount,
+		Reason:    "payment on a replaced checkout",
+	})
+	if err != nil {
+		return err
+	}
+	result.Refunded++
+	msg := fmt.Sprintf("Your payment of %s was for a checkout that had been replaced, so it is being refunded.", captured.Amount.Format())
+	_ = notification.CreateNotification(o.UserID, msg, "warning")
+	return gateway.MarkOrderRefunded(o.OrderID)
+}
@@ -0,0 +1,42 @@
+// payment/webhook_handler.go
+package payment
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody caps what is read from a webhook request
+const maxWebhookBody = 1 << 20
+
+// RazorpayWebhookHandler handles POST /api/v1/payment/webhook
+// The provider retries any delivery that doesn't get a 2xx, so failures answer 500 on purpose.
+func RazorpayWebhookHandler(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read body"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK: the provider signs every delivery
+	event, err := gateway.ParseWebhook(body, c.GetHeader)
+	if errors.Is(err, gateway.ErrInvalidWebhookSignature) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook signature"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event"})
+		return
+	}
+
+	status, err := handleEvent(event, body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Event could not be processed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
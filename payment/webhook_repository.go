@@ -0,0 +1,51 @@
+// payment/webhook_repository.go
+package payment
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// beginEvent records a delivery of an event and locks it until finishEvent, so a duplicate
+// delivery waits for the first one instead of processing in parallel.
+// It reports whether the event was already handled by an earlier delivery.
+func beginEvent(eventID, eventType string, payload []byte) (*sql.Tx, bool, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO webhook_events (event_id, event_type, payload, status)
+		VALUES (?, ?, ?, 'received')
+		ON DUPLICATE KEY UPDATE attempts = attempts + 1
+	`, eventID, eventType, payload)
+	if err != nil {
+		tx.Rollback()
+		log.Println("Error recording webhook event:", err)
+		return nil, false, err
+	}
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM webhook_events WHERE event_id = ? FOR UPDATE`, eventID).Scan(&status); err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	return tx, status == EventProcessed || status == EventIgnored, nil
+}
+
+// finishEvent stores the outcome and releases the event
+func finishEvent(tx *sql.Tx, eventID, status, errMsg string) error {
+	_, err := tx.Exec(`
+		UPDATE webhook_events SET status = ?, error = NULLIF(?, ''), processed_at = NOW()
+		WHERE event_id = ?
+	`, status, errMsg, eventID)
+	if err != nil {
+		tx.Rollback()
+		log.Println("Error finishing webhook event:", err)
+		return err
+	}
+	return tx.Commit()
+}
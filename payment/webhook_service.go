@@ -0,0 +1,125 @@
+// payment/webhook_service.go
+package payment
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/JkD004/playarena-backend/booking"
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/JkD004/playarena-backend/pack"
+	"github.com/JkD004/playarena-backend/refund"
+	"github.com/JkD004/playarena-backend/wallet"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Webhook event statuses
+const (
+	EventReceived  = "received"
+	EventProcessed = "processed"
+	EventIgnored   = "ignored" // Not an event we act on, or about an order that isn't ours
+	EventFailed    = "failed"  // The provider is told to retry
+	EventDuplicate = "duplicate"
+)
+
+// handleEvent applies an event once, however many times it is delivered, and returns its outcome
+func handleEvent(e *gateway.Event, payload []byte) (string, error) {
+	tx, handled, err := beginEvent(e.ID, e.Type, payload)
+	if err != nil {
+		return EventFailed, err
+	}
+	if handled {
+		tx.Rollback()
+		return EventDuplicate, nil
+	}
+
+	status, procErr := processEvent(e)
+	errMsg := ""
+	if procErr != nil {
+		log.Printf("Webhook %s (%s) failed: %v\n", e.ID, e.Type, procErr)
+		status, errMsg = EventFailed, procErr.Error()
+	}
+	if err := finishEvent(tx, e.ID, status, errMsg); err != nil {
+		return EventFailed, err
+	}
+	return status, procErr
+}
+
+// deliverFakeEvent takes the events the fake gateway raises in-process, as the webhook would
+func deliverFakeEvent(e *gateway.Event) {
+	payload, _ := json.Marshal(e)
+	if _, err := handleEvent(e, payload); err != nil {
+		log.Println("Error handling fake gateway event:", err)
+	}
+}
+
+// processEvent applies a verified webhook event and returns the status to store for it
+func processEvent(e *gateway.Event) (string, error) {
+	switch e.Type {
+	case gateway.EventPaymentCaptured, gateway.EventOrderPaid:
+		if err := gateway.RecordAttempt(e.OrderID, e.PaymentID, e.Amount, gateway.PaymentCaptured, gateway.SourceWebhook, "", ""); err != nil {
+			return EventFailed, err
+		}
+		return applyCapturedPayment(e.OrderID, e.PaymentID, e.Amount)
+
+	case gateway.EventPaymentFailed:
+		// Checkout lets the player retry on the same order, so one failed attempt doesn't
+		// release the slot; the hold expiring does that if no payment follows
+		log.Printf("Payment %s for order %s failed: %s\n", e.PaymentID, e.OrderID, e.Error)
+		if err := gateway.RecordAttempt(e.OrderID, e.PaymentID, e.Amount, gateway.PaymentFailed, gateway.SourceWebhook, e.Error, ""); err != nil {
+			return EventFailed, err
+		}
+		return EventProcessed, nil
+
+	case gateway.EventRefundProcessed, gateway.EventRefundFailed:
+		if e.PaymentID == "" {
+			return EventIgnored, nil
+		}
+		status := refund.StatusProcessed
+		if e.Type == gateway.EventRefundFailed {
+			status = refund.StatusFailed
+		}
+		handled, err := refund.ApplyEvent(e.RefundID, e.PaymentID, status, e.Amount)
+		if err == nil && handled && status == refund.StatusProcessed {
+			err = booking.SettleProcessedRefunds(e.PaymentID)
+		}
+		if err == nil && !handled {
+			// Refunds started before they were tracked are only recorded on the booking
+			handled, err = booking.ApplyRefundStatus(e.PaymentID, e.RefundID, status, e.Amount)
+		}
+		if err != nil {
+			return EventFailed, err
+		}
+		if !handled {
+			return EventIgnored, nil
+		}
+		return EventProcessed, nil
+	}
+	return EventIgnored, nil
+}
+
+// applyCapturedPayment hands a captured payment to whatever the order was created for:
+// bookings, a pack purchase or a wallet top-up
+func applyCapturedPayment(orderID, paymentID string, amount money.Money) (string, error) {
+	if orderID == "" || paymentID == "" {
+		return EventIgnored, nil
+	}
+	appliers := []func() (bool, error){
+		func() (bool, error) { return booking.ApplyCapturedPayment(orderID, paymentID, amount) },
+		func() (bool, error) { return pack.ConfirmPurchaseByOrder(orderID, paymentID) },
+		func() (bool, error) { return wallet.ConfirmTopupByOrder(orderID, paymentID) },
+	}
+	for _, apply := range appliers {
+		handled, err := apply()
+		if err != nil {
+			return EventFailed, err
+		}
+		if handled {
+			_ = gateway.MarkOrderPaid(orderID)
+			return EventProcessed, nil
+		}
+	}
+	log.Printf("Webhook payment %s is for unknown order %s\n", paymentID, orderID)
+	return EventIgnored, nil
+}
@@ -0,0 +1,114 @@
+// pkg/idempotency/store.go
+package idempotency
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// Key statuses
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+)
+
+// StaleAfter is how long a request may stay processing before it is assumed to have died
+// and a retry may take the key over
+const StaleAfter = 2 * time.Minute
+
+// Record is a stored request and, once completed, the response it got
+type Record struct {
+	Fingerprint  string
+	Status       string
+	ResponseCode int
+	ContentType  string
+	Body         []byte
+	StartedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+func find(userID int64, key string) (*Record, error) {
+	var r Record
+	var code sql.NullInt64
+	var contentType sql.NullString
+	err := db.DB.QueryRow(`
+		SELECT fingerprint, status, response_code, response_content_type, response_body, started_at, expires_at
+		FROM idempotency_keys WHERE user_id = ? AND idem_key = ?
+	`, userID, key).Scan(&r.Fingerprint, &r.Status, &code, &contentType, &r.Body, &r.StartedAt, &r.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	r.ResponseCode = int(code.Int64)
+	r.ContentType = contentType.String
+	return &r, nil
+}
+
+// Claim reserves a key for a request. It returns nil when the caller now owns the key,
+// or the record already stored under it.
+// Expired keys, and keys whose first request died while processing, are taken over.
+func Claim(userID int64, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	now := time.Now()
+	result, err := db.DB.Exec(`
+		INSERT IGNORE INTO idempotency_keys (user_id, idem_key, fingerprint, status, started_at, expires_at)
+		VALUES (?, ?, ?, 'processing', ?, ?)
+	`, userID, key, fingerprint, now, now.Add(ttl))
+	if err != nil {
+		log.Println("Error claiming idempotency key:", err)
+		return nil, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 1 {
+		return nil, nil
+	}
+
+	result, err = db.DB.Exec(`
+		UPDATE idempotency_keys
+		SET fingerprint = ?, status = 'processing', response_code = NULL, response_content_type = NULL,
+		    response_body = NULL, started_at = ?, expires_at = ?
+		WHERE user_id = ? AND idem_key = ?
+		  AND (expires_at < ? OR (status = 'processing' AND started_at < ? AND fingerprint = ?))
+	`, fingerprint, now, now.Add(ttl), userID, key, now, now.Add(-StaleAfter), fingerprint)
+	if err != nil {
+		log.Println("Error taking over idempotency key:", err)
+		return nil, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 1 {
+		return nil, nil
+	}
+
+	return find(userID, key)
+}
+
+// Complete stores the response for a claimed key so retries get it replayed
+func Complete(userID int64, key string, code int, contentType string, body []byte) error {
+	_, err := db.DB.Exec(`
+		UPDATE idempotency_keys
+		SET status = 'completed', response_code = ?, response_content_type = ?, response_body = ?
+		WHERE user_id = ? AND idem_key = ? AND status = 'processing'
+	`, code, contentType, body, userID, key)
+	if err != nil {
+		log.Println("Error saving idempotent response:", err)
+	}
+	return err
+}
+
+// Release frees a claimed key without a response, so the request can be retried
+func Release(userID int64, key string) error {
+	_, err := db.DB.Exec(`DELETE FROM idempotency_keys WHERE user_id = ? AND idem_key = ? AND status = 'processing'`, userID, key)
+	if err != nil {
+		log.Println("Error releasing idempotency key:", err)
+	}
+	return err
+}
+
+// PurgeExpired deletes keys past their TTL
+func PurgeExpired(now time.Time) (int64, error) {
+	result, err := db.DB.Exec(`DELETE FROM idempotency_keys WHERE expires_at < ?`, now)
+	if err != nil {
+		log.Println("Error purging idempotency keys:", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
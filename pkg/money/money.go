@@ -0,0 +1,194 @@
+// pkg/money/money.go
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is the ISO 4217 code of every amount on the platform. A Money value doesn't carry
+// its currency: the platform only takes INR, and supporting another would mean adding one here.
+const Currency = "INR"
+
+// Money is an amount in paise, the minor unit of Currency. Amounts are added and compared as
+// integers so totals, refunds and payouts add up exactly; anything that scales an amount
+// (hours, percentages) rounds once, half away from zero, to the nearest paisa.
+//
+// In memory it is always paise, while the database keeps rupees in DECIMAL(…, 2) columns:
+// Scan and Value convert between the two without going through float64. It is JSON-encoded
+// as a plain number in rupees ("1250.50"), like the float fields it replaced.
+type Money int64
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// FromPaise wraps an integer number of paise
+func FromPaise(p int64) Money {
+	return Money(p)
+}
+
+// FromRupees converts a rupee amount, rounding to the nearest paisa
+func FromRupees(r float64) Money {
+	return Money(math.Round(r * 100))
+}
+
+// Parse reads a decimal rupee amount such as "1250", "1250.5" or "-99.999".
+// Digits past the paisa are rounded half away from zero.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, ErrInvalidAmount
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	for _, part := range []string{whole, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, ErrInvalidAmount
+			}
+		}
+	}
+
+	rupees, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || rupees > math.MaxInt64/100-1 {
+		return 0, ErrInvalidAmount
+	}
+	paise := int64(0)
+	for i := 0; i < 2; i++ {
+		paise *= 10
+		if i < len(frac) {
+			paise += int64(frac[i] - '0')
+		}
+	}
+	if len(frac) > 2 && frac[2] >= '5' {
+		paise++
+	}
+
+	m := Money(rupees*100 + paise)
+	if neg {
+		m = -m
+	}
+	return m, nil
+}
+
+// Paise returns the amount in paise, as payment gateways expect it
+func (m Money) Paise() int64 {
+	return int64(m)
+}
+
+// Rupees returns the amount as a float, for display and rate calculations only
+func (m Money) Rupees() float64 {
+	return float64(m) / 100
+}
+
+// String formats the amount in rupees with two decimals: "1250.50"
+func (m Money) String() string {
+	sign := ""
+	p := int64(m)
+	if p < 0 {
+		sign, p = "-", -p
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, p/100, p%100)
+}
+
+// Format adds the rupee sign: "₹1250.50"
+func (m Money) Format() string {
+	if m < 0 {
+		return "-₹" + (-m).String()
+	}
+	return "₹" + m.String()
+}
+
+// Mul scales the amount, e.g. an hourly rate by a number of hours, rounding to the paisa
+func (m Money) Mul(f float64) Money {
+	return Money(math.Round(float64(m) * f))
+}
+
+// Percent returns p percent of the amount, rounded to the paisa
+func (m Money) Percent(p float64) Money {
+	return Money(math.Round(float64(m) * p / 100))
+}
+
+// Min returns the smaller of two amounts
+func Min(a, b Money) Money {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Max returns the larger of two amounts
+func Max(a, b Money) Money {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// MarshalJSON writes the amount as a number in rupees
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a numeric string in rupees
+func (m *Money) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "null" || s == "" {
+		*m = 0
+		return nil
+	}
+	if strings.ContainsAny(s, "eE") { // Exponent notation from some JSON encoders
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return ErrInvalidAmount
+		}
+		*m = FromRupees(f)
+		return nil
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Scan reads a DECIMAL column (or a SUM over one)
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	case int64:
+		*m = Money(v * 100)
+	case float64:
+		*m = FromRupees(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Value writes the amount as an exact decimal string
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
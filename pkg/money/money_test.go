@@ -0,0 +1,141 @@
+// pkg/money/money_test.go
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMulRoundsHalfAwayFromZero(t *testing.T) {
+	cases := []struct {
+		m    Money
+		f    float64
+		want Money
+	}{
+		{FromPaise(100000), 1.5, FromPaise(150000)}, // ₹1000/h for 90 minutes
+		{FromPaise(99999), 1.0 / 3, FromPaise(33333)},
+		{FromPaise(100), 0.125, FromPaise(13)}, // 12.5 paise
+		{FromPaise(-100), 0.125, FromPaise(-13)},
+		{FromPaise(101), 0.5, FromPaise(51)}, // 50.5 paise
+		{FromPaise(12345), 0, 0},
+	}
+	for _, tc := range cases {
+		if got := tc.m.Mul(tc.f); got != tc.want {
+			t.Errorf("%s.Mul(%v) = %s, want %s", tc.m, tc.f, got, tc.want)
+		}
+	}
+}
+
+func TestPercentRounds(t *testing.T) {
+	cases := []struct {
+		m    Money
+		p    float64
+		want Money
+	}{
+		{FromPaise(99900), 50, FromPaise(49950)},
+		{FromPaise(99950), 50, FromPaise(49975)},
+		{FromPaise(333), 50, FromPaise(167)}, // 166.5 paise
+		{FromPaise(100001), 18, FromPaise(18000)},
+		{FromPaise(1), 12.5, 0},
+		{FromPaise(4), 12.5, FromPaise(1)}, // 0.5 paise
+		{FromPaise(-333), 50, FromPaise(-167)},
+		{FromPaise(123456), 100, FromPaise(123456)},
+		{FromPaise(123456), 0, 0},
+	}
+	for _, tc := range cases {
+		if got := tc.m.Percent(tc.p); got != tc.want {
+			t.Errorf("%s.Percent(%v) = %s, want %s", tc.m, tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestPercentSplitAddsUp(t *testing.T) {
+	// A refund tier and what the venue keeps must always add back to the total
+	total := FromPaise(133333)
+	for p := 0; p <= 100; p++ {
+		refund := total.Percent(float64(p))
+		if kept := total - refund; kept < 0 || refund+kept != total {
+			t.Fatalf("%d%%: refund %s and kept %s don't add up to %s", p, refund, kept, total)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]Money{
+		"1250":    FromPaise(125000),
+		"1250.5":  FromPaise(125050),
+		"1250.50": FromPaise(125050),
+		".5":      FromPaise(50),
+		"-99.999": FromPaise(-10000),
+		"0.004":   0,
+		"0.005":   FromPaise(1),
+		"+12.34":  FromPaise(1234),
+	}
+	for s, want := range cases {
+		got, err := Parse(s)
+		if err != nil || got != want {
+			t.Errorf("Parse(%q) = %s, %v; want %s", s, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", ".", "12a", "1,000", "--5", "99999999999999999999"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := FromPaise(125005).String(); got != "1250.05" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := FromPaise(-5).Format(); got != "-₹0.05" {
+		t.Errorf("Format() = %q", got)
+	}
+}
+
+func TestScanDecimalColumns(t *testing.T) {
+	cases := []struct {
+		src  interface{}
+		want Money
+	}{
+		{[]byte("1250.50"), FromPaise(125050)}, // DECIMAL as the MySQL driver returns it
+		{"0.10", FromPaise(10)},
+		{int64(3), FromPaise(300)}, // COUNT or an integer SUM
+		{0.1 + 0.2, FromPaise(30)},
+		{nil, 0},
+	}
+	for _, tc := range cases {
+		var m Money
+		if err := m.Scan(tc.src); err != nil || m != tc.want {
+			t.Errorf("Scan(%v) = %s, %v; want %s", tc.src, m, err, tc.want)
+		}
+	}
+
+	v, err := FromPaise(125050).Value()
+	if err != nil || v != "1250.50" {
+		t.Errorf("Value() = %v, %v; want the rupee decimal", v, err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var body struct {
+		Amount Money `json:"amount"`
+	}
+	for in, want := range map[string]Money{
+		`{"amount": 1250.5}`:  FromPaise(125050),
+		`{"amount": "99.99"}`: FromPaise(9999),
+		`{"amount": 1.5e3}`:   FromPaise(150000),
+		`{"amount": null}`:    0,
+	} {
+		body.Amount = -1
+		if err := json.Unmarshal([]byte(in), &body); err != nil || body.Amount != want {
+			t.Errorf("%s: got %s, %v; want %s", in, body.Amount, err, want)
+		}
+	}
+
+	body.Amount = FromPaise(125050)
+	out, _ := json.Marshal(body)
+	if string(out) != `{"amount":1250.50}` {
+		t.Errorf("Marshal = %s", out)
+	}
+}
@@ -0,0 +1,159 @@
+// pkg/ticket/keys.go
+package ticket
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Key configuration (all values base64, standard or URL alphabet):
+//
+//	TICKET_SIGNING_KEYS  = "kid1:<32-byte seed>,kid2:<32-byte seed>"   private keys we hold
+//	TICKET_ACTIVE_KEY_ID = "kid2"                                       the one new tickets are signed with
+//	TICKET_VERIFY_KEYS   = "kid0:<32-byte public key>"                  retired keys, public half only
+//
+// To rotate: add a new signing key, make it active, and keep the old key (or just its public half
+// in TICKET_VERIFY_KEYS) until the tickets it signed have expired.
+
+type keyring struct {
+	activeID string
+	signing  map[string]ed25519.PrivateKey
+	verify   map[string]ed25519.PublicKey
+}
+
+var (
+	keysOnce sync.Once
+	keys     *keyring
+)
+
+// Init loads the keys at startup so a bad configuration stops the server before any ticket is issued
+func Init() {
+	loadedKeys()
+}
+
+func loadedKeys() *keyring {
+	keysOnce.Do(func() {
+		k, err := loadKeyring()
+		if err != nil {
+			log.Fatalf("Invalid ticket key configuration: %v", err)
+		}
+		keys = k
+	})
+	return keys
+}
+
+func loadKeyring() (*keyring, error) {
+	k := &keyring{
+		signing: make(map[string]ed25519.PrivateKey),
+		verify:  make(map[string]ed25519.PublicKey),
+	}
+
+	signing, err := parseKeyList(os.Getenv("TICKET_SIGNING_KEYS"), ed25519.SeedSize)
+	if err != nil {
+		return nil, fmt.Errorf("TICKET_SIGNING_KEYS: %w", err)
+	}
+	for kid, seed := range signing {
+		priv := ed25519.NewKeyFromSeed(seed)
+		k.signing[kid] = priv
+		k.verify[kid] = priv.Public().(ed25519.PublicKey)
+	}
+
+	retired, err := parseKeyList(os.Getenv("TICKET_VERIFY_KEYS"), ed25519.PublicKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("TICKET_VERIFY_KEYS: %w", err)
+	}
+	for kid, pub := range retired {
+		if _, ok := k.verify[kid]; !ok {
+			k.verify[kid] = ed25519.PublicKey(pub)
+		}
+	}
+
+	k.activeID = strings.TrimSpace(os.Getenv("TICKET_ACTIVE_KEY_ID"))
+	if k.activeID == "" && len(k.signing) == 1 {
+		for kid := range k.signing {
+			k.activeID = kid
+		}
+	}
+
+	if len(k.signing) == 0 {
+		// A temporary key would invalidate every ticket on each deploy
+		if os.Getenv("GIN_MODE") == "release" {
+			return nil, fmt.Errorf("TICKET_SIGNING_KEYS must be set in release mode")
+		}
+		// Development: tickets stay valid only until the server restarts
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("⚠️ TICKET_SIGNING_KEYS is not set; using a temporary ticket key (tickets become invalid on restart)")
+		k.activeID = "dev"
+		k.signing["dev"] = priv
+		k.verify["dev"] = priv.Public().(ed25519.PublicKey)
+	}
+	if _, ok := k.signing[k.activeID]; !ok {
+		return nil, fmt.Errorf("active key %q is not one of the signing keys", k.activeID)
+	}
+	return k, nil
+}
+
+// parseKeyList reads "kid:base64,kid:base64" and checks each key's length
+func parseKeyList(raw string, size int) (map[string][]byte, error) {
+	out := make(map[string][]byte)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, encoded, ok := strings.Cut(entry, ":")
+		if !ok || kid == "" || strings.Contains(kid, ".") {
+			return nil, fmt.Errorf("entry %q must look like kid:base64key", entry)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil || len(key) != size {
+			return nil, fmt.Errorf("key %q must be %d bytes of base64", kid, size)
+		}
+		out[kid] = key
+	}
+	return out, nil
+}
+
+func decodeKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("not base64")
+}
+
+// PublicKey is published so scanners can verify tickets offline
+type PublicKey struct {
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	PublicKey string `json:"public_key"` // base64 (standard alphabet)
+	Active    bool   `json:"active"`
+}
+
+// PublicKeys lists every key tickets may currently be signed with
+func PublicKeys() []PublicKey {
+	k := loadedKeys()
+	list := make([]PublicKey, 0, len(k.verify))
+	for kid, pub := range k.verify {
+		list = append(list, PublicKey{
+			KeyID:     kid,
+			Algorithm: "Ed25519",
+			PublicKey: base64.StdEncoding.EncodeToString(pub),
+			Active:    kid == k.activeID,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].KeyID < list[j].KeyID })
+	return list
+}
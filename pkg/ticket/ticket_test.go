@@ -0,0 +1,200 @@
+// pkg/ticket/ticket_test.go
+package ticket
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func seed(b byte) []byte {
+	return bytes.Repeat([]byte{b}, ed25519.SeedSize)
+}
+
+func seedEntry(kid string, b byte) string {
+	return kid + ":" + base64.StdEncoding.EncodeToString(seed(b))
+}
+
+func publicEntry(kid string, b byte) string {
+	pub := ed25519.NewKeyFromSeed(seed(b)).Public().(ed25519.PublicKey)
+	return kid + ":" + base64.StdEncoding.EncodeToString(pub)
+}
+
+// useKeys loads a keyring from the given configuration and makes it the one in use
+func useKeys(t *testing.T, signing, active, verify string) {
+	t.Helper()
+	t.Setenv("GIN_MODE", "")
+	t.Setenv("TICKET_SIGNING_KEYS", signing)
+	t.Setenv("TICKET_ACTIVE_KEY_ID", active)
+	t.Setenv("TICKET_VERIFY_KEYS", verify)
+	k, err := loadKeyring()
+	if err != nil {
+		t.Fatalf("loadKeyring: %v", err)
+	}
+	keysOnce.Do(func() {})
+	keys = k
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	useKeys(t, seedEntry("k1", 1), "", "")
+	start := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	token, err := Issue(42, 7, start, end)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if !strings.HasPrefix(token, "SG1.k1.") {
+		t.Fatalf("token %q should carry the version and key ID", token)
+	}
+
+	claims, err := Verify(token, start)
+	if err != nil {
+		t.Fatalf("Verify at start: %v", err)
+	}
+	if claims.BookingID != 42 || claims.VenueID != 7 || claims.KeyID != "k1" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+	if _, err := Verify(token, start.Add(-ValidBefore)); err != nil {
+		t.Fatalf("Verify at early check-in: %v", err)
+	}
+	if _, err := Verify(token, start.Add(-ValidBefore-time.Second)); !errors.Is(err, ErrNotYetValid) {
+		t.Fatalf("before the window: got %v, want ErrNotYetValid", err)
+	}
+	if _, err := Verify(token, end); !errors.Is(err, ErrExpired) {
+		t.Fatalf("at the end: got %v, want ErrExpired", err)
+	}
+}
+
+func TestTamperedTicket(t *testing.T) {
+	useKeys(t, seedEntry("k1", 1), "", "")
+	start := time.Now()
+	token, err := Issue(42, 7, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	forged, _ := Issue(43, 7, start, start.Add(time.Hour))
+	parts[2] = strings.Split(forged, ".")[2] // Another booking's claims under this signature
+	if _, err := Parse(strings.Join(parts, ".")); !errors.Is(err, ErrSignature) {
+		t.Fatalf("swapped claims: got %v, want ErrSignature", err)
+	}
+
+	parts = strings.Split(token, ".")
+	parts[1] = "k9"
+	if _, err := Parse(strings.Join(parts, ".")); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("unknown key: got %v, want ErrUnknownKey", err)
+	}
+
+	for _, bad := range []string{"", "SG1.k1.abc", "SG2" + token[3:]} {
+		if _, err := Parse(bad); !errors.Is(err, ErrMalformed) {
+			t.Fatalf("Parse(%q): got %v, want ErrMalformed", bad, err)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	start := time.Now()
+	end := start.Add(time.Hour)
+
+	useKeys(t, seedEntry("k1", 1), "", "")
+	old, err := Issue(1, 1, start, end)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	// Step 1: add k2 and make it active, keeping k1
+	useKeys(t, seedEntry("k1", 1)+","+seedEntry("k2", 2), "k2", "")
+	current, err := Issue(2, 1, start, end)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if !strings.HasPrefix(current, "SG1.k2.") {
+		t.Fatalf("new tickets should be signed with k2, got %q", current)
+	}
+	for _, token := range []string{old, current} {
+		if _, err := Verify(token, start); err != nil {
+			t.Fatalf("Verify after adding k2: %v", err)
+		}
+	}
+
+	// Step 2: keep only k1's public half
+	useKeys(t, seedEntry("k2", 2), "", publicEntry("k1", 1))
+	for _, token := range []string{old, current} {
+		if _, err := Verify(token, start); err != nil {
+			t.Fatalf("Verify with k1 retired: %v", err)
+		}
+	}
+	published := PublicKeys()
+	if len(published) != 2 || published[0].KeyID != "k1" || published[0].Active || !published[1].Active {
+		t.Fatalf("unexpected published keys %+v", published)
+	}
+
+	// Step 3: drop k1 once its tickets have expired
+	useKeys(t, seedEntry("k2", 2), "", "")
+	if _, err := Verify(old, start); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Verify after dropping k1: got %v, want ErrUnknownKey", err)
+	}
+	if _, err := Verify(current, start); err != nil {
+		t.Fatalf("Verify k2 ticket: %v", err)
+	}
+}
+
+func TestLoadKeyringErrors(t *testing.T) {
+	cases := []struct {
+		name, signing, active, verify string
+	}{
+		{"active key not a signing key", seedEntry("k1", 1) + "," + seedEntry("k2", 2), "k3", ""},
+		{"two keys and none active", seedEntry("k1", 1) + "," + seedEntry("k2", 2), "", ""},
+		{"short seed", "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), "", ""},
+		{"missing key ID", base64.StdEncoding.EncodeToString(seed(1)), "", ""},
+		{"verify key not base64", seedEntry("k1", 1), "", "k0:not base64!"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GIN_MODE", "")
+			t.Setenv("TICKET_SIGNING_KEYS", tc.signing)
+			t.Setenv("TICKET_ACTIVE_KEY_ID", tc.active)
+			t.Setenv("TICKET_VERIFY_KEYS", tc.verify)
+			if _, err := loadKeyring(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestReleaseModeRequiresSigningKeys(t *testing.T) {
+	t.Setenv("TICKET_SIGNING_KEYS", "")
+	t.Setenv("TICKET_ACTIVE_KEY_ID", "")
+	t.Setenv("TICKET_VERIFY_KEYS", "")
+
+	t.Setenv("GIN_MODE", "release")
+	if _, err := loadKeyring(); err == nil {
+		t.Fatal("release mode without TICKET_SIGNING_KEYS should fail")
+	}
+
+	t.Setenv("GIN_MODE", "debug")
+	k, err := loadKeyring()
+	if err != nil {
+		t.Fatalf("development fallback: %v", err)
+	}
+	if k.activeID != "dev" {
+		t.Fatalf("expected the temporary dev key, got %q", k.activeID)
+	}
+}
+
+func TestDocumentSignatureIsNotATicket(t *testing.T) {
+	useKeys(t, seedEntry("k1", 1), "", "")
+	payload := []byte(`{"bid":42,"vid":7,"nbf":0,"exp":9999999999}`)
+	kid, sig := SignDocument("manifest", payload)
+
+	token := version + "." + kid + "." + EncodePayload(payload) + "." + sig
+	if _, err := Parse(token); !errors.Is(err, ErrSignature) {
+		t.Fatalf("document signature accepted as a ticket: %v", err)
+	}
+}
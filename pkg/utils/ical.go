@@ -0,0 +1,315 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CalendarEvent is one VEVENT of an iCalendar file
+type CalendarEvent struct {
+	UID         string // Stable across updates so calendar apps replace instead of duplicating
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Location    string
+	Description string
+	Cancelled   bool
+	Sequence    int // Bump when the event changes (e.g. rescheduled)
+	Updated     time.Time
+	Recurring   bool // Parsed events only: the event has an RRULE; see ExpandRecurrences
+	// Parsed events only: the RRULE (nil when it uses parts we don't expand), the dates it
+	// leaves out, and for an edited occurrence the start it replaces
+	Rule         *RecurrenceRule
+	ExDates      []time.Time
+	RecurrenceID time.Time
+}
+
+// icsIST is used for floating times and unknown TZIDs (venues are in India)
+var icsIST = time.FixedZone("IST", 5*60*60+30*60)
+
+// GenerateICS renders events as an RFC 5545 calendar (times in UTC, CRLF line endings)
+func GenerateICS(calendarName string, events []CalendarEvent) []byte {
+	var buf bytes.Buffer
+	line := func(s string) {
+		buf.WriteString(foldICSLine(s))
+		buf.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//SportGrid//Bookings//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICSText(calendarName))
+	line("X-WR-TIMEZONE:Asia/Kolkata")
+	line("X-PUBLISHED-TTL:PT1H") // Hint for how often subscribers refresh
+
+	now := time.Now()
+	for _, e := range events {
+		stamp := e.Updated
+		if stamp.IsZero() {
+			stamp = now
+		}
+		status := "CONFIRMED"
+		if e.Cancelled {
+			status = "CANCELLED"
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + formatICSTime(stamp))
+		line("DTSTART:" + formatICSTime(e.Start))
+		line("DTEND:" + formatICSTime(e.End))
+		line("SUMMARY:" + escapeICSText(e.Summary))
+		if e.Location != "" {
+			line("LOCATION:" + escapeICSText(e.Location))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeICSText(e.Description))
+		}
+		line("STATUS:" + status)
+		line(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return buf.Bytes()
+}
+
+func formatICSTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeICSText escapes the characters RFC 5545 reserves in TEXT values
+func escapeICSText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// foldICSLine splits lines longer than 75 octets, continuing with a leading space
+// (never inside a multi-byte character)
+func foldICSLine(s string) string {
+	if len(s) <= 75 {
+		return s
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+// ParseICS reads the VEVENTs of an iCalendar file. Times without a zone (and unknown
+// TZIDs) are taken as IST; all-day events span whole IST days. Recurring events are
+// returned once with Recurring set and their rule; ExpandRecurrences turns them into occurrences.
+func ParseICS(r io.Reader) ([]CalendarEvent, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]CalendarEvent, 0)
+	var current *CalendarEvent
+	var duration time.Duration
+	var hasEnd, sawCalendar bool
+	depth := 0 // Components nested inside the VEVENT (e.g. VALARM)
+
+	for _, line := range lines {
+		name, params, value, ok := splitICSLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VCALENDAR":
+			sawCalendar = true
+		case name == "BEGIN" && value == "VEVENT":
+			current, duration, hasEnd, depth = &CalendarEvent{}, 0, false, 0
+		case current == nil:
+			// Outside an event
+		case name == "BEGIN":
+			depth++
+		case name == "END" && value != "VEVENT":
+			depth--
+		case name == "END":
+			if !hasEnd {
+				current.End = current.Start.Add(duration)
+			}
+			if current.UID != "" && !current.Start.IsZero() && current.End.After(current.Start) {
+				events = append(events, *current)
+			}
+			current = nil
+		case depth > 0:
+			// Property of a nested component
+		default:
+			if err := setICSProperty(current, name, params, value, &duration, &hasEnd); err != nil {
+				return nil, fmt.Errorf("event %q: %w", current.UID, err)
+			}
+		}
+	}
+
+	if !sawCalendar {
+		return nil, errors.New("not an iCalendar file")
+	}
+	return events, nil
+}
+
+func setICSProperty(e *CalendarEvent, name string, params map[string]string, value string, duration *time.Duration, hasEnd *bool) error {
+	switch name {
+	case "UID":
+		e.UID = value
+	case "SUMMARY":
+		e.Summary = unescapeICSText(value)
+	case "LOCATION":
+		e.Location = unescapeICSText(value)
+	case "DESCRIPTION":
+		e.Description = unescapeICSText(value)
+	case "STATUS":
+		e.Cancelled = strings.EqualFold(value, "CANCELLED")
+	case "SEQUENCE":
+		e.Sequence, _ = strconv.Atoi(value)
+	case "RRULE":
+		e.Recurring = true
+		e.Rule = parseRecurrenceRule(value)
+	case "EXDATE":
+		for _, v := range strings.Split(value, ",") {
+			t, _, err := parseICSTime(strings.TrimSpace(v), params)
+			if err != nil {
+				return err
+			}
+			e.ExDates = append(e.ExDates, t)
+		}
+	case "RECURRENCE-ID":
+		t, _, err := parseICSTime(value, params)
+		if err != nil {
+			return err
+		}
+		e.RecurrenceID = t
+	case "DTSTART":
+		t, allDay, err := parseICSTime(value, params)
+		if err != nil {
+			return err
+		}
+		e.Start = t
+		if allDay && *duration == 0 {
+			*duration = 24 * time.Hour // All-day event without DTEND
+		}
+	case "DTEND":
+		t, _, err := parseICSTime(value, params)
+		if err != nil {
+			return err
+		}
+		e.End, *hasEnd = t, true
+	case "DURATION":
+		d, err := parseICSDuration(value)
+		if err != nil {
+			return err
+		}
+		*duration = d
+	case "LAST-MODIFIED", "DTSTAMP":
+		if t, _, err := parseICSTime(value, params); err == nil && t.After(e.Updated) {
+			e.Updated = t
+		}
+	}
+	return nil
+}
+
+// unfoldICSLines joins continuation lines (those starting with a space or tab)
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitICSLine splits `NAME;PARAM=x:value` (a colon inside a quoted parameter doesn't end the name)
+func splitICSLine(line string) (string, map[string]string, string, bool) {
+	inQuotes := false
+	for i, ch := range line {
+		switch {
+		case ch == '"':
+			inQuotes = !inQuotes
+		case ch == ':' && !inQuotes:
+			head, value := line[:i], line[i+1:]
+			parts := strings.Split(head, ";")
+			params := make(map[string]string)
+			for _, p := range parts[1:] {
+				if k, v, found := strings.Cut(p, "="); found {
+					params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+				}
+			}
+			return strings.ToUpper(parts[0]), params, strings.TrimSpace(value), true
+		}
+	}
+	return "", nil, "", false
+}
+
+func parseICSTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, icsIST)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	loc := icsIST
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+var icsDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICSDuration understands RFC 5545 durations such as PT1H30M or P1D
+func parseICSDuration(value string) (time.Duration, error) {
+	m := icsDurationPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func unescapeICSText(s string) string {
+	r := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return r.Replace(s)
+}
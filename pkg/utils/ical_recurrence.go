@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecurrenceRule is the part of an RRULE we expand: FREQ=DAILY or WEEKLY with INTERVAL,
+// COUNT or UNTIL, and for weekly rules BYDAY with plain weekdays
+type RecurrenceRule struct {
+	Freq     string // "DAILY" or "WEEKLY"
+	Interval int
+	Count    int       // 0 = no limit
+	Until    time.Time // Zero = no limit; inclusive
+	ByDay    []time.Weekday
+}
+
+// maxRecurrenceSteps bounds the expansion of one rule (about 27 years of a daily rule)
+const maxRecurrenceSteps = 10000
+
+var icsWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// parseRecurrenceRule returns nil for rules we don't expand (other frequencies, BYMONTH,
+// BYSETPOS, "1MO"-style BYDAY and so on); such events stay unexpanded
+func parseRecurrenceRule(value string) *RecurrenceRule {
+	rule := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil
+			}
+			rule.Count = n
+		case "UNTIL":
+			t, allDay, err := parseICSTime(val, nil)
+			if err != nil {
+				return nil
+			}
+			if allDay {
+				t = t.AddDate(0, 0, 1).Add(-time.Second) // The whole last day
+			}
+			rule.Until = t
+		case "BYDAY":
+			for _, code := range strings.Split(strings.ToUpper(val), ",") {
+				day, ok := icsWeekdays[code]
+				if !ok {
+					return nil
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "WKST":
+			// Only matters for BYDAY with INTERVAL > 1; Monday (the default) is assumed
+		default:
+			return nil
+		}
+	}
+	if rule.Freq != "DAILY" && rule.Freq != "WEEKLY" {
+		return nil
+	}
+	if rule.Freq == "DAILY" && len(rule.ByDay) > 0 {
+		return nil
+	}
+	return rule
+}
+
+// ExpandRecurrences replaces each recurring event with its occurrences that overlap [from, to).
+// Occurrences get the UID "<uid>/<start in UTC>" so each one is tracked on its own, and an
+// edited occurrence (same UID with a RECURRENCE-ID) takes the place of the one it replaces.
+// Events whose rule isn't supported are returned unchanged, still marked Recurring.
+func ExpandRecurrences(events []CalendarEvent, from, to time.Time) []CalendarEvent {
+	// Occurrences moved or canceled individually, by UID and original start
+	overridden := make(map[string]bool)
+	for _, e := range events {
+		if !e.RecurrenceID.IsZero() {
+			overridden[e.UID+"/"+formatICSTime(e.RecurrenceID)] = true
+		}
+	}
+
+	out := make([]CalendarEvent, 0, len(events))
+	for _, e := range events {
+		switch {
+		case !e.RecurrenceID.IsZero():
+			e.UID += "/" + formatICSTime(e.RecurrenceID)
+			out = append(out, e)
+		case !e.Recurring || e.Rule == nil:
+			out = append(out, e)
+		default:
+			out = append(out, expandEvent(e, from, to, overridden)...)
+		}
+	}
+	return out
+}
+
+// expandEvent lists the occurrences of one recurring event overlapping [from, to)
+func expandEvent(e CalendarEvent, from, to time.Time, overridden map[string]bool) []CalendarEvent {
+	length := e.End.Sub(e.Start)
+	excluded := make(map[int64]bool, len(e.ExDates))
+	for _, t := range e.ExDates {
+		excluded[t.Unix()] = true
+	}
+
+	occurrences := make([]CalendarEvent, 0)
+	eachRecurrence(e.Start, e.Rule, to, func(start time.Time) {
+		end := start.Add(length)
+		uid := e.UID + "/" + formatICSTime(start)
+		if excluded[start.Unix()] || overridden[uid] || !end.After(from) {
+			return
+		}
+		o := e
+		o.UID, o.Start, o.End = uid, start, end
+		o.Recurring, o.Rule, o.ExDates = false, nil, nil
+		occurrences = append(occurrences, o)
+	})
+	return occurrences
+}
+
+// eachRecurrence calls fn with every start the rule generates from dtstart (which is the first),
+// in order, stopping at COUNT, UNTIL or the first start not before `to`.
+// Steps are taken in dtstart's zone so the wall-clock time stays put across DST changes.
+func eachRecurrence(dtstart time.Time, rule *RecurrenceRule, to time.Time, fn func(time.Time)) {
+	// Days of the week the rule fires on, as offsets from the week's Monday
+	var offsets []int
+	if rule.Freq == "WEEKLY" {
+		days := rule.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{dtstart.Weekday()}
+		}
+		for _, d := range days {
+			offsets = append(offsets, (int(d)+6)%7)
+		}
+		sort.Ints(offsets)
+	}
+	weekStart := dtstart.AddDate(0, 0, -((int(dtstart.Weekday()) + 6) % 7))
+
+	emitted := 0
+	for step := 0; step < maxRecurrenceSteps; step++ {
+		var candidates []time.Time
+		if rule.Freq == "DAILY" {
+			candidates = []time.Time{dtstart.AddDate(0, 0, step*rule.Interval)}
+		} else {
+			week := weekStart.AddDate(0, 0, 7*step*rule.Interval)
+			for _, off := range offsets {
+				candidates = append(candidates, week.AddDate(0, 0, off))
+			}
+		}
+
+		for _, start := range candidates {
+			if start.Before(dtstart) {
+				continue // BYDAY days earlier in the first week
+			}
+			if !rule.Until.IsZero() && start.After(rule.Until) {
+				return
+			}
+			if !start.Before(to) {
+				return
+			}
+			fn(start)
+			emitted++
+			if rule.Count > 0 && emitted >= rule.Count {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseTestICS(t *testing.T, body string) []CalendarEvent {
+	t.Helper()
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.ReplaceAll(strings.TrimSpace(body), "\n", "\r\n") + "\r\nEND:VCALENDAR\r\n"
+	events, err := ParseICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("ParseICS: %v", err)
+	}
+	return events
+}
+
+func ist(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, icsIST)
+}
+
+func startsOf(events []CalendarEvent) []string {
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		out = append(out, e.Start.In(icsIST).Format("2006-01-02 15:04"))
+	}
+	return out
+}
+
+func expectStarts(t *testing.T, events []CalendarEvent, want ...string) {
+	t.Helper()
+	got := startsOf(events)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("starts:\n got  %v\n want %v", got, want)
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	events := parseTestICS(t, `
+BEGIN:VEVENT
+UID:utc@example.com
+DTSTART:20260310T123000Z
+DTEND:20260310T133000Z
+SUMMARY:League match\, court 2
+DESCRIPTION:Bring a long description that is folded onto
+  the next line
+BEGIN:VALARM
+TRIGGER:-PT15M
+DESCRIPTION:Reminder
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:floating@example.com
+DTSTART:20260311T070000
+DURATION:PT1H30M
+END:VEVENT
+BEGIN:VEVENT
+UID:allday@example.com
+DTSTART;VALUE=DATE:20260312
+END:VEVENT
+BEGIN:VEVENT
+UID:canceled@example.com
+DTSTART;TZID=Asia/Kolkata:20260313T180000
+DTEND;TZID=Asia/Kolkata:20260313T190000
+STATUS:CANCELLED
+SEQUENCE:3
+END:VEVENT
+BEGIN:VEVENT
+UID:no-end-after-start@example.com
+DTSTART:20260314T100000Z
+DTEND:20260314T100000Z
+END:VEVENT
+`)
+
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4 (an event that ends when it starts is dropped)", len(events))
+	}
+
+	e := events[0]
+	if !e.Start.Equal(ist(2026, 3, 10, 18, 0)) || e.End.Sub(e.Start) != time.Hour {
+		t.Fatalf("UTC times: got %v - %v", e.Start, e.End)
+	}
+	if e.Summary != "League match, court 2" {
+		t.Fatalf("summary not unescaped: %q", e.Summary)
+	}
+	if e.Description != "Bring a long description that is folded onto the next line" {
+		t.Fatalf("description not unfolded (or the VALARM leaked into it): %q", e.Description)
+	}
+
+	if e := events[1]; !e.Start.Equal(ist(2026, 3, 11, 7, 0)) || e.End.Sub(e.Start) != 90*time.Minute {
+		t.Fatalf("floating time with DURATION: got %v - %v", e.Start, e.End)
+	}
+	if e := events[2]; !e.Start.Equal(ist(2026, 3, 12, 0, 0)) || e.End.Sub(e.Start) != 24*time.Hour {
+		t.Fatalf("all-day event: got %v - %v", e.Start, e.End)
+	}
+	if e := events[3]; !e.Cancelled || e.Sequence != 3 {
+		t.Fatalf("canceled event: got %+v", e)
+	}
+}
+
+func TestParseICSRejectsOtherFiles(t *testing.T) {
+	if _, err := ParseICS(strings.NewReader("not a calendar")); err == nil {
+		t.Fatal("expected an error for a file without VCALENDAR")
+	}
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	rule := parseRecurrenceRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20260430;WKST=MO")
+	if rule == nil || rule.Freq != "WEEKLY" || rule.Interval != 2 || len(rule.ByDay) != 2 {
+		t.Fatalf("unexpected rule %+v", rule)
+	}
+	if want := ist(2026, 4, 30, 23, 59).Add(59 * time.Second); !rule.Until.Equal(want) {
+		t.Fatalf("a date UNTIL should cover the whole day, got %v", rule.Until)
+	}
+
+	for _, unsupported := range []string{
+		"FREQ=MONTHLY;BYMONTHDAY=1",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=DAILY;COUNT=0",
+		"INTERVAL=2",
+	} {
+		if rule := parseRecurrenceRule(unsupported); rule != nil {
+			t.Fatalf("%s should not be expanded, got %+v", unsupported, rule)
+		}
+	}
+}
+
+func TestExpandDailyCount(t *testing.T) {
+	events := parseTestICS(t, `
+BEGIN:VEVENT
+UID:daily@example.com
+DTSTART:20260301T060000
+DTEND:20260301T070000
+RRULE:FREQ=DAILY;INTERVAL=2;COUNT=5
+EXDATE:20260305T060000
+END:VEVENT
+`)
+	// COUNT includes occurrences before the window and the excluded one
+	out := ExpandRecurrences(events, ist(2026, 3, 3, 0, 0), ist(2026, 12, 31, 0, 0))
+	expectStarts(t, out, "2026-03-03 06:00", "2026-03-07 06:00", "2026-03-09 06:00")
+
+	for _, e := range out {
+		if e.Recurring || e.Rule != nil || e.End.Sub(e.Start) != time.Hour {
+			t.Fatalf("occurrence not flattened: %+v", e)
+		}
+		if want := "daily@example.com/" + formatICSTime(e.Start); e.UID != want {
+			t.Fatalf("occurrence UID %q, want %q", e.UID, want)
+		}
+	}
+}
+
+func TestExpandWeeklyByDayUntil(t *testing.T) {
+	events := parseTestICS(t, `
+BEGIN:VEVENT
+UID:weekly@example.com
+DTSTART;TZID=Asia/Kolkata:20260304T190000
+DTEND;TZID=Asia/Kolkata:20260304T210000
+RRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20260316T133000Z
+EXDATE;TZID=Asia/Kolkata:20260309T190000,20260311T190000
+END:VEVENT
+`)
+	// Wednesday 4th is DTSTART; Monday 2nd is before it. UNTIL is 19:00 IST on the 16th, inclusive.
+	out := ExpandRecurrences(events, ist(2026, 3, 1, 0, 0), ist(2026, 6, 1, 0, 0))
+	expectStarts(t, out, "2026-03-04 19:00", "2026-03-16 19:00")
+}
+
+func TestExpandClipsToWindow(t *testing.T) {
+	events := parseTestICS(t, `
+BEGIN:VEVENT
+UID:forever@example.com
+DTSTART:20250101T090000
+DTEND:20250101T100000
+RRULE:FREQ=WEEKLY
+END:VEVENT
+`)
+	out := ExpandRecurrences(events, ist(2026, 3, 1, 0, 0), ist(2026, 3, 22, 0, 0))
+	// 1 Jan 2025 was a Wednesday
+	expectStarts(t, out, "2026-03-04 09:00", "2026-03-11 09:00", "2026-03-18 09:00")
+}
+
+func TestExpandWithEditedOccurrence(t *testing.T) {
+	events := parseTestICS(t, `
+BEGIN:VEVENT
+UID:training@example.com
+DTSTART:20260302T170000
+DTEND:20260302T180000
+RRULE:FREQ=DAILY;COUNT=4
+END:VEVENT
+BEGIN:VEVENT
+UID:training@example.com
+RECURRENCE-ID:20260303T170000
+DTSTART:20260303T200000
+DTEND:20260303T210000
+END:VEVENT
+BEGIN:VEVENT
+UID:training@example.com
+RECURRENCE-ID:20260304T170000
+DTSTART:20260304T170000
+DTEND:20260304T180000
+STATUS:CANCELLED
+END:VEVENT
+`)
+	out := ExpandRecurrences(events, ist(2026, 3, 1, 0, 0), ist(2026, 4, 1, 0, 0))
+	if len(out) != 4 {
+		t.Fatalf("got %d events, want 2 occurrences and 2 edited ones: %v", len(out), startsOf(out))
+	}
+
+	byUID := make(map[string]CalendarEvent)
+	for _, e := range out {
+		byUID[e.UID] = e
+	}
+	moved := byUID["training@example.com/"+formatICSTime(ist(2026, 3, 3, 17, 0))]
+	if !moved.Start.Equal(ist(2026, 3, 3, 20, 0)) {
+		t.Fatalf("moved occurrence should keep its original key and new time, got %+v", moved)
+	}
+	canceled := byUID["training@example.com/"+formatICSTime(ist(2026, 3, 4, 17, 0))]
+	if !canceled.Cancelled {
+		t.Fatalf("canceled occurrence should replace the generated one, got %+v", canceled)
+	}
+	for _, key := range []string{"2026-03-02 17:00", "2026-03-05 17:00"} {
+		found := false
+		for _, s := range startsOf(out) {
+			found = found || s == key
+		}
+		if !found {
+			t.Fatalf("missing occurrence %s in %v", key, startsOf(out))
+		}
+	}
+}
+
+func TestExpandKeepsUnsupportedRules(t *testing.T) {
+	events := parseTestICS(t, `
+BEGIN:VEVENT
+UID:monthly@example.com
+DTSTART:20260301T100000
+DTEND:20260301T110000
+RRULE:FREQ=MONTHLY;BYMONTHDAY=1
+END:VEVENT
+`)
+	out := ExpandRecurrences(events, ist(2026, 3, 1, 0, 0), ist(2026, 6, 1, 0, 0))
+	if len(out) != 1 || !out[0].Recurring || out[0].UID != "monthly@example.com" {
+		t.Fatalf("unsupported rule should be returned unchanged, got %+v", out)
+	}
+}
+
+func TestExpandKeepsWallClockAcrossDST(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/London"); err != nil {
+		t.Skip("time zone data not available")
+	}
+	events := parseTestICS(t, `
+BEGIN:VEVENT
+UID:london@example.com
+DTSTART;TZID=Europe/London:20260327T090000
+DTEND;TZID=Europe/London:20260327T100000
+RRULE:FREQ=DAILY;COUNT=3
+END:VEVENT
+`)
+	out := ExpandRecurrences(events, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	if len(out) != 3 {
+		t.Fatalf("got %d occurrences, want 3", len(out))
+	}
+	for _, e := range out {
+		if h := e.Start.Hour(); h != 9 {
+			t.Fatalf("occurrence at %v should stay at 09:00 London time", e.Start)
+		}
+	}
+	// Clocks went forward on 29 March
+	if got := out[2].Start.UTC().Hour(); got != 8 {
+		t.Fatalf("after the change 09:00 London is 08:00 UTC, got %d", got)
+	}
+}
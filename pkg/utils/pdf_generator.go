@@ -2,13 +2,10 @@ package utils
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
-	"os"
 	"time"
 
+	"github.com/JkD004/playarena-backend/pkg/ticket"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/skip2/go-qrcode"
 )
@@ -19,6 +16,7 @@ func GenerateTicketPDF(
 	venueName string,
 	venueAddress string,
 	bookingID int64,
+	venueID int64,
 	userID int64,
 	startTime time.Time,
 	endTime time.Time,
@@ -166,31 +164,16 @@ func GenerateTicketPDF(
 
 	// QR
 	// ---------------------------------------------------------
-	// SECURITY UPGRADE: SIGNED QR CODE
+	// SIGNED QR CODE (see pkg/ticket: Ed25519, carries venue + validity window)
 	// ---------------------------------------------------------
-	// We create a hash to prove this QR code came from us.
-	// Format: "BookingID|Signature"
-
 	qrY := y + 44
 
-	secretKey := os.Getenv("TICKET_SECRET_KEY")
-	if secretKey == "" {
-		secretKey = "default_unsafe_secret" // Fallback (unsafe for prod, helpful for dev)
+	qrContent, err := ticket.Issue(bookingID, venueID, startTime, endTime)
+	if err != nil {
+		return nil, err
 	}
 
-	// 1. Create the data payload
-	data := fmt.Sprintf("%d", bookingID)
-
-	// 2. Create the signature (HMAC-SHA256)
-	h := hmac.New(sha256.New, []byte(secretKey))
-	h.Write([]byte(data))
-	signature := hex.EncodeToString(h.Sum(nil))
-
-	// 3. Combine them
-	// The QR code will contain: "105|a94a8fe5ccb19..."
-	qrContent := fmt.Sprintf("%s|%s", data, signature)
-
-	// 4. Generate QR
+	// Generate QR
 	qr, _ := qrcode.Encode(qrContent, qrcode.Medium, 256)
 	pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
 	pdf.ImageOptions("qr", cardX+(cardW/2)-15, qrY, 30, 30, false, gofpdf.ImageOptions{}, 0, "")
@@ -0,0 +1,55 @@
+// refund/refund_handler.go
+package refund
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRefundsHandler handles GET /api/v1/admin/refunds?status=failed
+func GetRefundsHandler(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", StatusInitiated, StatusProcessed, StatusFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	list, err := FindRefunds(status, 200)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch refunds"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// RetryRefundHandler handles POST /api/v1/admin/refunds/:id/retry
+func RetryRefundHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refund ID"})
+		return
+	}
+
+	r, err := Retry(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// RetryDueRefundsHandler handles POST /api/v1/admin/refunds/retry
+// Runs the same retry pass as the background job, right away.
+func RetryDueRefundsHandler(c *gin.Context) {
+	result, err := RetryDue(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not retry refunds"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
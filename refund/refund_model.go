@@ -0,0 +1,59 @@
+// refund/refund_model.go
+package refund
+
+import (
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Refund statuses
+const (
+	StatusInitiated = "initiated" // Accepted by the gateway; the bank hasn't confirmed it yet
+	StatusProcessed = "processed" // The money is on its way back to the player
+	StatusFailed    = "failed"    // The gateway or the bank turned it down; retried while NextRetryAt is set
+)
+
+// MaxAttempts is how many times a refund is sent to the gateway before it is left for an admin
+const MaxAttempts = 5
+
+// retryDelays is how long to wait before each retry of a failed refund
+var retryDelays = []time.Duration{10 * time.Minute, 30 * time.Minute, 2 * time.Hour, 6 * time.Hour}
+
+// Request is a refund asked for by the booking and payment packages
+type Request struct {
+	Reference string // Unique per refund (e.g. "cancel:12") so a repeated call never refunds twice
+	BookingID int64  // 0 when the payment isn't tied to a booking any more
+	UserID    int64  // Who is told about the outcome
+	PaymentID string
+	Amount    money.Money
+	Reason    string
+}
+
+// Refund is money given back on a gateway payment. A payment can have several, each
+// for part of it: a reschedule price difference, then a cancellation, and so on.
+type Refund struct {
+	ID              int64       `json:"id"`
+	Reference       string      `json:"reference"`
+	BookingID       int64       `json:"booking_id,omitempty"`
+	UserID          int64       `json:"user_id"`
+	PaymentID       string      `json:"payment_id"`
+	GatewayRefundID string      `json:"gateway_refund_id,omitempty"`
+	Amount          money.Money `json:"amount"`
+	Reason          string      `json:"reason"`
+	Status          string      `json:"status"`
+	Attempts        int         `json:"attempts"`
+	LastError       string      `json:"last_error,omitempty"`
+	NextRetryAt     *time.Time  `json:"next_retry_at,omitempty"` // Unset once processed, or when retries ran out
+	ProcessedAt     *time.Time  `json:"processed_at,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// RetryResult counts what a retry run did
+type RetryResult struct {
+	Retried   int `json:"retried"`
+	Resent    int `json:"resent"`    // Accepted by the gateway this time
+	Recovered int `json:"recovered"` // The earlier attempt had gone through after all
+	Failed    int `json:"failed"`
+}
@@ -0,0 +1,217 @@
+// refund/refund_repository.go
+package refund
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// insertRefund records a refund about to be sent. A reference that was already recorded
+// is left alone and false is returned, with r filled from the existing row.
+func insertRefund(r *Refund) (bool, error) {
+	result, err := db.DB.Exec(`
+		INSERT INTO refunds (reference, booking_id, user_id, payment_id, amount, reason, status)
+		VALUES (?, NULLIF(?, 0), ?, ?, ?, ?, 'initiated')
+		ON DUPLICATE KEY UPDATE id = id
+	`, r.Reference, r.BookingID, r.UserID, r.PaymentID, r.Amount, r.Reason)
+	if err != nil {
+		log.Println("Error saving refund:", err)
+		return false, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 1 {
+		r.ID, _ = result.LastInsertId()
+		r.Status = StatusInitiated
+		return true, nil
+	}
+	existing, err := FindByReference(r.Reference)
+	if err != nil {
+		return false, err
+	}
+	*r = *existing
+	return false, nil
+}
+
+const refundColumns = `
+	id, reference, COALESCE(booking_id, 0), user_id, payment_id, COALESCE(gateway_refund_id, ''),
+	amount, reason, status, attempts, COALESCE(last_error, ''), next_retry_at, processed_at,
+	created_at, updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRefund(row rowScanner) (*Refund, error) {
+	var r Refund
+	var nextRetry, processed sql.NullTime
+	err := row.Scan(&r.ID, &r.Reference, &r.BookingID, &r.UserID, &r.PaymentID, &r.GatewayRefundID,
+		&r.Amount, &r.Reason, &r.Status, &r.Attempts, &r.LastError, &nextRetry, &processed,
+		&r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if nextRetry.Valid {
+		r.NextRetryAt = &nextRetry.Time
+	}
+	if processed.Valid {
+		r.ProcessedAt = &processed.Time
+	}
+	return &r, nil
+}
+
+func queryRefunds(query string, args ...interface{}) ([]Refund, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching refunds:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Refund, 0)
+	for rows.Next() {
+		r, err := scanRefund(rows)
+		if err != nil {
+			log.Println("Error scanning refund:", err)
+			continue
+		}
+		list = append(list, *r)
+	}
+	return list, nil
+}
+
+// FindByID returns one refund
+func FindByID(id int64) (*Refund, error) {
+	return scanRefund(db.DB.QueryRow(`SELECT `+refundColumns+` FROM refunds WHERE id = ?`, id))
+}
+
+// FindByReference returns the refund recorded under a caller's reference
+func FindByReference(reference string) (*Refund, error) {
+	return scanRefund(db.DB.QueryRow(`SELECT `+refundColumns+` FROM refunds WHERE reference = ?`, reference))
+}
+
+// findForEvent matches a gateway refund event to its row: by the gateway's refund ID, or else the
+// oldest unconfirmed refund of that amount on the payment with no ID stored. The event can arrive
+// before the gateway's reply has been saved, or for a call that timed out and was recorded as failed.
+func findForEvent(gatewayRefundID, paymentID string, amount money.Money) (*Refund, error) {
+	r, err := scanRefund(db.DB.QueryRow(`SELECT `+refundColumns+` FROM refunds WHERE gateway_refund_id = ?`, gatewayRefundID))
+	if err != sql.ErrNoRows {
+		return r, err
+	}
+	return scanRefund(db.DB.QueryRow(`
+		SELECT `+refundColumns+` FROM refunds
+		WHERE payment_id = ? AND amount = ? AND gateway_refund_id IS NULL AND status IN ('initiated', 'failed')
+		ORDER BY id ASC LIMIT 1
+	`, paymentID, amount))
+}
+
+// FindByBookingID lists a booking's refunds, oldest first
+func FindByBookingID(bookingID int64) ([]Refund, error) {
+	return queryRefunds(`SELECT `+refundColumns+` FROM refunds WHERE booking_id = ? ORDER BY id ASC`, bookingID)
+}
+
+// FindRefunds lists refunds for the admin, newest first, optionally filtered by status
+func FindRefunds(status string, limit int) ([]Refund, error) {
+	if status == "" {
+		return queryRefunds(`SELECT `+refundColumns+` FROM refunds ORDER BY id DESC LIMIT ?`, limit)
+	}
+	return queryRefunds(`SELECT `+refundColumns+` FROM refunds WHERE status = ? ORDER BY id DESC LIMIT ?`, status, limit)
+}
+
+// FindDueRetries lists failed refunds whose next retry is due, oldest first
+func FindDueRetries(now time.Time, limit int) ([]Refund, error) {
+	return queryRefunds(`
+		SELECT `+refundColumns+` FROM refunds
+		WHERE status = 'failed' AND next_retry_at <= ?
+		ORDER BY next_retry_at ASC LIMIT ?
+	`, now, limit)
+}
+
+// sumPendingOrProcessed adds up the refunds on a payment the gateway has accepted, leaving one out
+func sumPendingOrProcessed(paymentID string, exceptID int64) (money.Money, error) {
+	var total money.Money
+	err := db.DB.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) FROM refunds
+		WHERE payment_id = ? AND id <> ? AND status IN ('initiated', 'processed')
+	`, paymentID, exceptID).Scan(&total)
+	return total, err
+}
+
+// TotalOnPayment adds up every refund recorded on a payment except the one under reference.
+// Failed ones count too: they are retried, or retried by an admin.
+func TotalOnPayment(paymentID, exceptReference string) (money.Money, error) {
+	var total money.Money
+	err := db.DB.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE payment_id = ? AND reference <> ?
+	`, paymentID, exceptReference).Scan(&total)
+	if err != nil {
+		log.Println("Error totalling refunds:", err)
+	}
+	return total, err
+}
+
+// claimRetry pushes a failed refund's next retry out by lease so nobody else picks it up meanwhile.
+// Unless force is set (an admin asking), the retry must already be due.
+func claimRetry(id int64, now time.Time, lease time.Duration, force bool) (bool, error) {
+	query := `UPDATE refunds SET next_retry_at = ? WHERE id = ? AND status = 'failed'`
+	args := []interface{}{now.Add(lease), id}
+	if !force {
+		query += ` AND next_retry_at <= ?`
+		args = append(args, now)
+	}
+	result, err := db.DB.Exec(query, args...)
+	if err != nil {
+		log.Println("Error claiming refund retry:", err)
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// markSent records that the gateway accepted an attempt. A refund already reported processed stays so.
+func markSent(id int64, gatewayRefundID string, attempts int) error {
+	_, err := db.DB.Exec(`
+		UPDATE refunds
+		SET gateway_refund_id = NULLIF(?, ''), attempts = ?, last_error = NULL, next_retry_at = NULL,
+		    status = IF(status = 'processed', status, 'initiated')
+		WHERE id = ?
+	`, gatewayRefundID, attempts, id)
+	if err != nil {
+		log.Println("Error saving sent refund:", err)
+	}
+	return err
+}
+
+// markFailed records a refused attempt; nextRetry is nil once retries have run out.
+// Returns false when the refund had already been processed.
+func markFailed(id int64, attempts int, errMsg string, nextRetry *time.Time) (bool, error) {
+	result, err := db.DB.Exec(`
+		UPDATE refunds SET status = 'failed', attempts = ?, last_error = ?, next_retry_at = ?
+		WHERE id = ? AND status <> 'processed'
+	`, attempts, errMsg, nextRetry, id)
+	if err != nil {
+		log.Println("Error saving failed refund:", err)
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// markProcessed records the bank's confirmation. Returns false when it was already recorded.
+func markProcessed(id int64, gatewayRefundID string, now time.Time) (bool, error) {
+	result, err := db.DB.Exec(`
+		UPDATE refunds
+		SET status = 'processed', processed_at = ?, next_retry_at = NULL, last_error = NULL,
+		    gateway_refund_id = COALESCE(NULLIF(?, ''), gateway_refund_id)
+		WHERE id = ? AND status <> 'processed'
+	`, now, gatewayRefundID, id)
+	if err != nil {
+		log.Println("Error saving processed refund:", err)
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
@@ -0,0 +1,216 @@
+// refund/refund_service.go
+package refund
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+const (
+	retryBatch = 50
+	retryLease = 15 * time.Minute // How long a claimed retry is kept from other runs
+)
+
+// nextRetry is when a refund that has been tried attempts times goes out again, or nil when it is left for an admin
+func nextRetry(attempts int, now time.Time) *time.Time {
+	if attempts >= MaxAttempts {
+		return nil
+	}
+	i := attempts - 1
+	if i >= len(retryDelays) {
+		i = len(retryDelays) - 1
+	}
+	t := now.Add(retryDelays[i])
+	return &t
+}
+
+// Initiate sends a refund to the gateway and tracks it until the bank confirms it.
+// A gateway error doesn't fail the call: the refund is recorded as failed and retried in the
+// background, and the player is told once the money is actually processed.
+// Calling it again with the same reference returns the refund already recorded.
+func Initiate(req Request) (*Refund, error) {
+	if req.Reference == "" || req.PaymentID == "" {
+		return nil, errors.New("a refund needs a reference and a payment")
+	}
+	if req.Amount <= 0 {
+		return nil, errors.New("refund amount must be positive")
+	}
+
+	r := &Refund{
+		Reference: req.Reference,
+		BookingID: req.BookingID,
+		UserID:    req.UserID,
+		PaymentID: req.PaymentID,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+	}
+	created, err := insertRefund(r)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return r, nil
+	}
+	if err := send(r, time.Now()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// send makes one attempt at the gateway and records the outcome on r.
+// Only a failure to save the outcome is returned.
+func send(r *Refund, now time.Time) error {
+	r.Attempts++
+	res, err := gateway.InitiateRefund(r.PaymentID, r.Amount)
+	if err != nil {
+		log.Printf("Refund #%d of %s on payment %s failed (attempt %d): %v\n", r.ID, r.Amount, r.PaymentID, r.Attempts, err)
+		return fail(r, err.Error(), now)
+	}
+	if err := markSent(r.ID, res.ID, r.Attempts); err != nil {
+		return err
+	}
+	r.GatewayRefundID, r.LastError, r.NextRetryAt = res.ID, "", nil
+	if r.Status != StatusProcessed {
+		r.Status = StatusInitiated
+	}
+	return nil
+}
+
+// fail records a refused attempt and schedules the next one. The player hears about it
+// once retries have run out.
+func fail(r *Refund, reason string, now time.Time) error {
+	retryAt := nextRetry(r.Attempts, now)
+	changed, err := markFailed(r.ID, r.Attempts, reason, retryAt)
+	if err != nil || !changed {
+		return err
+	}
+	r.Status, r.LastError, r.NextRetryAt = StatusFailed, reason, retryAt
+
+	if retryAt == nil {
+		log.Printf("Refund #%d on payment %s needs attention: %d attempts failed\n", r.ID, r.PaymentID, r.Attempts)
+		msg := fmt.Sprintf("We could not complete your refund of %s. Our team has been alerted and will sort it out.", r.Amount.Format())
+		_ = notification.CreateNotification(r.UserID, msg, "warning")
+	}
+	return nil
+}
+
+// ApplyEvent records what the gateway reports for a refund (StatusProcessed or StatusFailed).
+// It returns false when no tracked refund uses the payment, e.g. one started before refunds were tracked.
+func ApplyEvent(gatewayRefundID, paymentID, status string, amount money.Money) (bool, error) {
+	r, err := findForEvent(gatewayRefundID, paymentID, amount)
+	if err == sql.ErrNoRows {
+		// An attempt that was superseded by a retry reports under an ID that is no longer stored
+		return paymentTracked(paymentID)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	switch status {
+	case StatusProcessed:
+		changed, err := markProcessed(r.ID, gatewayRefundID, now)
+		if err != nil {
+			return true, err
+		}
+		if changed {
+			msg := fmt.Sprintf("Your refund of %s has been processed. It may take 5-7 working days to show in your account.", r.Amount.Format())
+			_ = notification.CreateNotification(r.UserID, msg, "info")
+		}
+
+	case StatusFailed:
+		if r.Status != StatusInitiated {
+			return true, nil
+		}
+		if err := fail(r, "refund failed at the bank", now); err != nil {
+			return true, err
+		}
+		if r.NextRetryAt != nil {
+			msg := fmt.Sprintf("Your refund of %s could not be processed by the bank. We will retry it automatically.", r.Amount.Format())
+			_ = notification.CreateNotification(r.UserID, msg, "warning")
+		}
+	}
+	return true, nil
+}
+
+func paymentTracked(paymentID string) (bool, error) {
+	var count int
+	err := db.DB.QueryRow(`SELECT COUNT(*) FROM refunds WHERE payment_id = ?`, paymentID).Scan(&count)
+	return count > 0, err
+}
+
+// RetryDue sends failed refunds whose retry is due back to the gateway
+func RetryDue(now time.Time) (*RetryResult, error) {
+	list, err := FindDueRetries(now, retryBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RetryResult{}
+	for i := range list {
+		claimed, err := claimRetry(list[i].ID, now, retryLease, false)
+		if err != nil || !claimed {
+			continue
+		}
+		result.Retried++
+		retry(&list[i], now, result)
+	}
+	return result, nil
+}
+
+// Retry sends one failed refund again straight away, including one whose retries ran out
+func Retry(id int64) (*Refund, error) {
+	r, err := FindByID(id)
+	if err != nil {
+		return nil, errors.New("refund not found")
+	}
+	now := time.Now()
+	claimed, err := claimRetry(id, now, retryLease, true)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, errors.New("only a failed refund can be retried")
+	}
+	retry(r, now, &RetryResult{})
+	return FindByID(id)
+}
+
+// retry makes another attempt, unless the gateway shows an earlier attempt went through
+// after all (e.g. the call timed out after the refund was created)
+func retry(r *Refund, now time.Time, result *RetryResult) {
+	p, err := gateway.FetchPayment(r.PaymentID)
+	if err != nil {
+		log.Printf("Error checking payment %s before retrying refund #%d: %v\n", r.PaymentID, r.ID, err)
+		result.Failed++
+		return // Picked up again once the lease runs out
+	}
+	accounted, err := sumPendingOrProcessed(r.PaymentID, r.ID)
+	if err != nil {
+		result.Failed++
+		return
+	}
+	if p.Refunded-accounted >= r.Amount {
+		// The webhook for it confirms it like any other refund
+		if err := markSent(r.ID, "", r.Attempts); err != nil {
+			result.Failed++
+			return
+		}
+		result.Recovered++
+		return
+	}
+
+	if err := send(r, now); err != nil || r.Status != StatusInitiated {
+		result.Failed++
+		return
+	}
+	result.Resent++
+}
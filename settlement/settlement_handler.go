@@ -0,0 +1,194 @@
+// settlement/settlement_handler.go
+package settlement
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JkD004/playarena-backend/venue"
+	"github.com/gin-gonic/gin"
+)
+
+// ownerFor is the owner whose ledger is being read: yourself, or ?owner_id= for admins
+func ownerFor(c *gin.Context) int64 {
+	userID := c.MustGet("userID").(int64)
+	if c.MustGet("userRole").(string) == "admin" {
+		if id, err := strconv.ParseInt(c.Query("owner_id"), 10, 64); err == nil {
+			return id
+		}
+	}
+	return userID
+}
+
+// GetBalanceHandler handles GET /api/v1/owner/settlements/balance
+func GetBalanceHandler(c *gin.Context) {
+	balance, err := GetOwnerBalance(ownerFor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch balance"})
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetStatementsHandler handles GET /api/v1/owner/settlements/statements
+func GetStatementsHandler(c *gin.Context) {
+	list, err := FindPayoutsByOwnerID(ownerFor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch statements"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetStatementHandler handles GET /api/v1/owner/settlements/statements/:id
+func GetStatementHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid statement ID"})
+		return
+	}
+
+	p, err := GetStatement(id, c.MustGet("userID").(int64), c.MustGet("userRole").(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// ExportStatementHandler handles GET /api/v1/owner/settlements/statements/:id/csv
+func ExportStatementHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid statement ID"})
+		return
+	}
+
+	p, err := GetStatement(id, c.MustGet("userID").(int64), c.MustGet("userRole").(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileName := fmt.Sprintf("STATEMENT-%d-%s.csv", p.ID, p.PeriodEnd.In(venue.IST).Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	if err := WriteStatementCSV(c.Writer, p); err != nil {
+		fmt.Println("Error writing statement CSV:", err)
+	}
+}
+
+// GetPayoutsHandler handles GET /api/v1/admin/payouts?status=pending
+func GetPayoutsHandler(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != PayoutPending && status != PayoutPaid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	list, err := FindPayouts(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch payouts"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GeneratePayoutsHandler handles POST /api/v1/admin/payouts/generate
+// Optional body {"period_end": "2026-10-12"} (IST midnight); defaults to the start of this week.
+func GeneratePayoutsHandler(c *gin.Context) {
+	var req struct {
+		PeriodEnd string `json:"period_end"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+
+	periodEnd, _ := PeriodFor(time.Now())
+	if req.PeriodEnd != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.PeriodEnd, venue.IST)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "period_end must be YYYY-MM-DD"})
+			return
+		}
+		if t.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "period_end cannot be in the future"})
+			return
+		}
+		periodEnd = t
+	}
+
+	created, err := GenerateStatements(periodEnd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate statements"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"period_end": periodEnd, "created": created})
+}
+
+// MarkPayoutPaidHandler handles PATCH /api/v1/admin/payouts/:id/paid
+func MarkPayoutPaidHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payout ID"})
+		return
+	}
+
+	var req struct {
+		Reference string `json:"reference" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transfer reference is required"})
+		return
+	}
+
+	p, err := MarkPaid(id, c.MustGet("userID").(int64), strings.TrimSpace(req.Reference))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// GetCommissionHandler handles GET /api/v1/admin/venues/:id/commission
+func GetCommissionHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+	_, err = FindCommissionRate(venueID)
+	c.JSON(http.StatusOK, gin.H{
+		"venue_id":           venueID,
+		"commission_percent": CommissionPercent(venueID),
+		"is_default":         err != nil,
+	})
+}
+
+// SetCommissionHandler handles PUT /api/v1/admin/venues/:id/commission
+func SetCommissionHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	var req struct {
+		CommissionPercent *float64 `json:"commission_percent" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "commission_percent is required"})
+		return
+	}
+
+	if err := SetCommissionPercent(venueID, *req.CommissionPercent); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"venue_id": venueID, "commission_percent": *req.CommissionPercent})
+}
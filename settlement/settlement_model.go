@@ -0,0 +1,89 @@
+// settlement/settlement_model.go
+package settlement
+
+import (
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Entry types
+const (
+	EntryBooking      = "booking"       // A confirmed, paid booking
+	EntryRefund       = "refund"        // Money given back on a booking
+	EntryAdjustment   = "adjustment"    // A reschedule changed the price of a paid booking
+	EntryPackPurchase = "pack_purchase" // A prepaid pack or pass sold at the venue
+)
+
+// Payout statuses
+const (
+	PayoutPending = "pending"
+	PayoutPaid    = "paid"
+)
+
+// DefaultCommissionPercent applies to venues without their own rate (PLATFORM_COMMISSION_PERCENT overrides it)
+const DefaultCommissionPercent = 10.0
+
+// DefaultGatewayFeePercent is Razorpay's standard 2% plus 18% GST on the fee (RAZORPAY_FEE_PERCENT overrides it)
+const DefaultGatewayFeePercent = 2.36
+
+// Sale is money collected for a venue, as handed over by the booking and pack packages
+type Sale struct {
+	Type           string // EntryBooking, EntryAdjustment or EntryPackPurchase
+	Reference      string // Unique per sale so it is only ever recorded once
+	VenueID        int64
+	BookingID      int64
+	PackPurchaseID int64
+	Gross          money.Money // What the player paid (negative for a price cut on reschedule)
+	GatewayCharged money.Money // The part that went through Razorpay and carries its fee
+}
+
+// Entry is one line of a venue owner's ledger. Net is what the owner is owed for it.
+type Entry struct {
+	ID             int64       `json:"id"`
+	VenueID        int64       `json:"venue_id"`
+	VenueName      string      `json:"venue_name"`
+	OwnerID        int64       `json:"owner_id"`
+	BookingID      int64       `json:"booking_id,omitempty"`
+	PackPurchaseID int64       `json:"pack_purchase_id,omitempty"`
+	Type           string      `json:"entry_type"`
+	Reference      string      `json:"reference"`
+	Gross          money.Money `json:"gross"`
+	CommissionRate float64     `json:"commission_rate"`
+	Commission     money.Money `json:"commission"`
+	GatewayFee     money.Money `json:"gateway_fee"`
+	Net            money.Money `json:"net"`
+	PayoutID       int64       `json:"payout_id,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// Payout is a periodic statement of what the platform owes an owner
+type Payout struct {
+	ID          int64       `json:"id"`
+	OwnerID     int64       `json:"owner_id"`
+	OwnerName   string      `json:"owner_name,omitempty"`
+	PeriodStart time.Time   `json:"period_start"`
+	PeriodEnd   time.Time   `json:"period_end"`
+	Gross       money.Money `json:"gross"`
+	Refunds     money.Money `json:"refunds"`
+	Commission  money.Money `json:"commission"`
+	GatewayFees money.Money `json:"gateway_fees"`
+	Net         money.Money `json:"net"`
+	EntryCount  int         `json:"entry_count"`
+	Status      string      `json:"status"` // 'pending' or 'paid'
+	PaidAt      *time.Time  `json:"paid_at,omitempty"`
+	PaidBy      int64       `json:"paid_by,omitempty"`
+	Reference   string      `json:"reference,omitempty"` // Bank transfer UTR
+	CreatedAt   time.Time   `json:"created_at"`
+	Entries     []Entry     `json:"entries,omitempty"`
+}
+
+// Balance is what an owner has earned since their last statement
+type Balance struct {
+	Gross       money.Money `json:"gross"`
+	Refunds     money.Money `json:"refunds"`
+	Commission  money.Money `json:"commission"`
+	GatewayFees money.Money `json:"gateway_fees"`
+	Net         money.Money `json:"net"`
+	Entries     []Entry     `json:"entries"`
+}
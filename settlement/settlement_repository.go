@@ -0,0 +1,278 @@
+// settlement/settlement_repository.go
+package settlement
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// FindCommissionRate returns the venue's own commission percent, or sql.ErrNoRows when it uses the default
+func FindCommissionRate(venueID int64) (float64, error) {
+	var percent float64
+	err := db.DB.QueryRow(`SELECT commission_percent FROM venue_commission_rates WHERE venue_id = ?`, venueID).Scan(&percent)
+	return percent, err
+}
+
+// UpsertCommissionRate sets the venue's commission percent
+func UpsertCommissionRate(venueID int64, percent float64) error {
+	query := `
+		INSERT INTO venue_commission_rates (venue_id, commission_percent) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE commission_percent = VALUES(commission_percent)
+	`
+	_, err := db.DB.Exec(query, venueID, percent)
+	if err != nil {
+		log.Println("Error saving commission rate:", err)
+	}
+	return err
+}
+
+// venueOwnerTx returns who owns the venue right now; entries keep that owner even if the venue changes hands
+func venueOwnerTx(tx *sql.Tx, venueID int64) (int64, error) {
+	var ownerID int64
+	err := tx.QueryRow(`SELECT owner_id FROM venues WHERE id = ?`, venueID).Scan(&ownerID)
+	return ownerID, err
+}
+
+// insertEntryTx records a ledger line. A reference that was already recorded is left alone.
+func insertEntryTx(tx *sql.Tx, e *Entry) error {
+	query := `
+		INSERT INTO settlement_entries (
+			venue_id, owner_id, booking_id, pack_purchase_id, entry_type, reference,
+			gross, commission_rate, commission, gateway_fee, net
+		) VALUES (?, ?, NULLIF(?, 0), NULLIF(?, 0), ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = id
+	`
+	_, err := tx.Exec(query,
+		e.VenueID, e.OwnerID, e.BookingID, e.PackPurchaseID, e.Type, e.Reference,
+		e.Gross, e.CommissionRate, e.Commission, e.GatewayFee, e.Net,
+	)
+	if err != nil {
+		log.Println("Error inserting settlement entry:", err)
+	}
+	return err
+}
+
+const entryColumns = `
+	e.id, e.venue_id, v.name, e.owner_id, COALESCE(e.booking_id, 0), COALESCE(e.pack_purchase_id, 0),
+	e.entry_type, e.reference, e.gross, e.commission_rate, e.commission, e.gateway_fee, e.net,
+	COALESCE(e.payout_id, 0), e.created_at
+`
+
+const entryFrom = ` FROM settlement_entries e JOIN venues v ON e.venue_id = v.id `
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row rowScanner) (*Entry, error) {
+	var e Entry
+	err := row.Scan(&e.ID, &e.VenueID, &e.VenueName, &e.OwnerID, &e.BookingID, &e.PackPurchaseID,
+		&e.Type, &e.Reference, &e.Gross, &e.CommissionRate, &e.Commission, &e.GatewayFee, &e.Net,
+		&e.PayoutID, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// findEntryByReferenceTx looks up a recorded line, e.g. the sale a refund reverses
+func findEntryByReferenceTx(tx *sql.Tx, reference string) (*Entry, error) {
+	return scanEntry(tx.QueryRow(`SELECT `+entryColumns+entryFrom+`WHERE e.reference = ?`, reference))
+}
+
+// soldTotalTx is what a booking brought in: its sale plus any reschedule adjustments
+func soldTotalTx(tx *sql.Tx, bookingID int64) (money.Money, error) {
+	var total money.Money
+	err := tx.QueryRow(`
+		SELECT COALESCE(SUM(gross), 0) FROM settlement_entries
+		WHERE booking_id = ? AND entry_type IN ('booking', 'adjustment')
+	`, bookingID).Scan(&total)
+	return total, err
+}
+
+func queryEntries(query string, args ...interface{}) ([]Entry, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching settlement entries:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Entry, 0)
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			log.Println("Error scanning settlement entry:", err)
+			continue
+		}
+		list = append(list, *e)
+	}
+	return list, nil
+}
+
+// FindUnsettledEntries lists an owner's lines not yet on a statement
+func FindUnsettledEntries(ownerID int64) ([]Entry, error) {
+	return queryEntries(`SELECT `+entryColumns+entryFrom+`WHERE e.owner_id = ? AND e.payout_id IS NULL ORDER BY e.created_at ASC, e.id ASC`, ownerID)
+}
+
+// FindEntriesByPayoutID lists the lines a statement covers
+func FindEntriesByPayoutID(payoutID int64) ([]Entry, error) {
+	return queryEntries(`SELECT `+entryColumns+entryFrom+`WHERE e.payout_id = ? ORDER BY e.created_at ASC, e.id ASC`, payoutID)
+}
+
+// FindOwnersWithUnsettled returns owners who have lines recorded before the cutoff and not yet on a statement
+func FindOwnersWithUnsettled(before time.Time) ([]int64, error) {
+	rows, err := db.DB.Query(`SELECT DISTINCT owner_id FROM settlement_entries WHERE payout_id IS NULL AND created_at < ?`, before)
+	if err != nil {
+		log.Println("Error fetching owners to settle:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err == nil {
+			owners = append(owners, id)
+		}
+	}
+	return owners, nil
+}
+
+// CreatePayoutForOwner moves an owner's unsettled lines from before periodEnd onto a new statement.
+// Nothing is written when the lines don't add up to a positive amount; they roll into the next period.
+func CreatePayoutForOwner(ownerID int64, periodStart, periodEnd time.Time) (*Payout, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO settlement_payouts (owner_id, period_start, period_end, status)
+		VALUES (?, ?, ?, 'pending')
+	`, ownerID, periodStart, periodEnd)
+	if err != nil {
+		log.Println("Error creating payout:", err)
+		return nil, err
+	}
+	payoutID, _ := result.LastInsertId()
+
+	_, err = tx.Exec(`
+		UPDATE settlement_entries SET payout_id = ?
+		WHERE owner_id = ? AND payout_id IS NULL AND created_at < ?
+	`, payoutID, ownerID, periodEnd)
+	if err != nil {
+		log.Println("Error assigning entries to payout:", err)
+		return nil, err
+	}
+
+	p := Payout{ID: payoutID, OwnerID: ownerID, PeriodStart: periodStart, PeriodEnd: periodEnd, Status: PayoutPending}
+	err = tx.QueryRow(`
+		SELECT COUNT(*),
+		       COALESCE(SUM(CASE WHEN gross > 0 THEN gross ELSE 0 END), 0),
+		       COALESCE(-SUM(CASE WHEN gross < 0 THEN gross ELSE 0 END), 0),
+		       COALESCE(SUM(commission), 0), COALESCE(SUM(gateway_fee), 0), COALESCE(SUM(net), 0)
+		FROM settlement_entries WHERE payout_id = ?
+	`, payoutID).Scan(&p.EntryCount, &p.Gross, &p.Refunds, &p.Commission, &p.GatewayFees, &p.Net)
+	if err != nil {
+		return nil, err
+	}
+	if p.EntryCount == 0 || p.Net <= 0 {
+		return nil, nil // Rolled back: carried forward
+	}
+
+	_, err = tx.Exec(`
+		UPDATE settlement_payouts
+		SET gross = ?, refunds = ?, commission = ?, gateway_fees = ?, net = ?, entry_count = ?
+		WHERE id = ?
+	`, p.Gross, p.Refunds, p.Commission, p.GatewayFees, p.Net, p.EntryCount, payoutID)
+	if err != nil {
+		log.Println("Error totalling payout:", err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+const payoutColumns = `
+	p.id, p.owner_id, CONCAT(u.first_name, ' ', u.last_name), p.period_start, p.period_end,
+	p.gross, p.refunds, p.commission, p.gateway_fees, p.net, p.entry_count, p.status,
+	p.paid_at, COALESCE(p.paid_by, 0), COALESCE(p.reference, ''), p.created_at
+`
+
+const payoutFrom = ` FROM settlement_payouts p JOIN users u ON p.owner_id = u.id `
+
+func scanPayout(row rowScanner) (*Payout, error) {
+	var p Payout
+	var paidAt sql.NullTime
+	err := row.Scan(&p.ID, &p.OwnerID, &p.OwnerName, &p.PeriodStart, &p.PeriodEnd,
+		&p.Gross, &p.Refunds, &p.Commission, &p.GatewayFees, &p.Net, &p.EntryCount, &p.Status,
+		&paidAt, &p.PaidBy, &p.Reference, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if paidAt.Valid {
+		p.PaidAt = &paidAt.Time
+	}
+	return &p, nil
+}
+
+func queryPayouts(query string, args ...interface{}) ([]Payout, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Println("Error fetching payouts:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Payout, 0)
+	for rows.Next() {
+		p, err := scanPayout(rows)
+		if err != nil {
+			log.Println("Error scanning payout:", err)
+			continue
+		}
+		list = append(list, *p)
+	}
+	return list, nil
+}
+
+// FindPayoutByID returns one statement
+func FindPayoutByID(id int64) (*Payout, error) {
+	return scanPayout(db.DB.QueryRow(`SELECT `+payoutColumns+payoutFrom+`WHERE p.id = ?`, id))
+}
+
+// FindPayoutsByOwnerID lists an owner's statements, newest first
+func FindPayoutsByOwnerID(ownerID int64) ([]Payout, error) {
+	return queryPayouts(`SELECT `+payoutColumns+payoutFrom+`WHERE p.owner_id = ? ORDER BY p.period_end DESC, p.id DESC`, ownerID)
+}
+
+// FindPayouts lists statements for the admin, optionally filtered by status
+func FindPayouts(status string) ([]Payout, error) {
+	if status == "" {
+		return queryPayouts(`SELECT ` + payoutColumns + payoutFrom + `ORDER BY p.period_end DESC, p.id DESC`)
+	}
+	return queryPayouts(`SELECT `+payoutColumns+payoutFrom+`WHERE p.status = ? ORDER BY p.period_end DESC, p.id DESC`, status)
+}
+
+// MarkPayoutPaid records the bank transfer for a pending statement
+func MarkPayoutPaid(id, adminID int64, reference string) (bool, error) {
+	result, err := db.DB.Exec(`
+		UPDATE settlement_payouts SET status = 'paid', paid_at = NOW(), paid_by = ?, reference = ?
+		WHERE id = ? AND status = 'pending'
+	`, adminID, reference, id)
+	if err != nil {
+		log.Println("Error marking payout paid:", err)
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
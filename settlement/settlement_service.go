@@ -0,0 +1,274 @@
+// settlement/settlement_service.go
+package settlement
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/notification"
+	"github.com/JkD004/playarena-backend/venue"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+func envPercent(key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
+
+// CommissionPercent is the platform's cut for a venue
+func CommissionPercent(venueID int64) float64 {
+	percent, err := FindCommissionRate(venueID)
+	if err == nil {
+		return percent
+	}
+	if err != sql.ErrNoRows {
+		log.Println("Error fetching commission rate:", err)
+	}
+	return envPercent("PLATFORM_COMMISSION_PERCENT", DefaultCommissionPercent)
+}
+
+// SetCommissionPercent gives a venue its own commission rate
+func SetCommissionPercent(venueID int64, percent float64) error {
+	if percent < 0 || percent > 100 {
+		return errors.New("commission must be between 0 and 100 percent")
+	}
+	return UpsertCommissionRate(venueID, percent)
+}
+
+// BookingReference and RefundReference key the ledger lines of a booking
+func BookingReference(bookingID int64) string { return fmt.Sprintf("booking:%d", bookingID) }
+func RefundReference(bookingID int64) string  { return fmt.Sprintf("refund:%d", bookingID) }
+
+// RecordSaleTx adds the ledger line for money collected on a venue's behalf.
+// Gateway fees are estimated from RAZORPAY_FEE_PERCENT on the part Razorpay charged.
+func RecordSaleTx(tx *sql.Tx, s Sale) error {
+	if s.Gross == 0 {
+		return nil
+	}
+	ownerID, err := venueOwnerTx(tx, s.VenueID)
+	if err != nil {
+		log.Println("Error fetching venue owner for settlement:", err)
+		return err
+	}
+
+	rate := CommissionPercent(s.VenueID)
+	if s.Type == EntryAdjustment {
+		// Adjustments follow the booking's own sale, at the rate it was sold at.
+		// Bookings sold before the ledger existed were never credited and are skipped.
+		original, err := findEntryByReferenceTx(tx, BookingReference(s.BookingID))
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		rate = original.CommissionRate
+	}
+	fee := money.Money(0)
+	if s.Gross > 0 && s.GatewayCharged > 0 {
+		fee = s.GatewayCharged.Percent(envPercent("RAZORPAY_FEE_PERCENT", DefaultGatewayFeePercent))
+	}
+	commission := s.Gross.Percent(rate)
+
+	return insertEntryTx(tx, &Entry{
+		VenueID:        s.VenueID,
+		OwnerID:        ownerID,
+		BookingID:      s.BookingID,
+		PackPurchaseID: s.PackPurchaseID,
+		Type:           s.Type,
+		Reference:      s.Reference,
+		Gross:          s.Gross,
+		CommissionRate: rate,
+		Commission:     commission,
+		GatewayFee:     fee,
+		Net:            s.Gross - commission - fee,
+	})
+}
+
+// RecordSale is RecordSaleTx in its own transaction
+func RecordSale(s Sale) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RecordSaleTx(tx, s); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecordRefundTx takes a refund off the owner's balance. The commission on the refunded
+// part is given back; Razorpay keeps its fee on refunds, so the fee line stays.
+// Bookings sold before the ledger existed were never credited and are skipped.
+func RecordRefundTx(tx *sql.Tx, bookingID int64, amount money.Money) error {
+	original, err := findEntryByReferenceTx(tx, BookingReference(bookingID))
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sold, err := soldTotalTx(tx, bookingID)
+	if err != nil {
+		return err
+	}
+	amount = money.Min(amount, sold)
+	if amount <= 0 {
+		return nil
+	}
+	commission := amount.Percent(original.CommissionRate)
+
+	return insertEntryTx(tx, &Entry{
+		VenueID:        original.VenueID,
+		OwnerID:        original.OwnerID,
+		BookingID:      bookingID,
+		Type:           EntryRefund,
+		Reference:      RefundReference(bookingID),
+		Gross:          -amount,
+		CommissionRate: original.CommissionRate,
+		Commission:     -commission,
+		Net:            -(amount - commission),
+	})
+}
+
+// PeriodFor returns the weekly settlement period (Monday to Monday, IST) containing t
+func PeriodFor(t time.Time) (time.Time, time.Time) {
+	t = t.In(venue.IST)
+	offset := (int(t.Weekday()) + 6) % 7 // Days since Monday
+	start := time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, venue.IST)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// GenerateStatements closes every owner's unsettled lines recorded before periodEnd
+// into a pending payout statement
+func GenerateStatements(periodEnd time.Time) ([]Payout, error) {
+	owners, err := FindOwnersWithUnsettled(periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	periodStart := periodEnd.AddDate(0, 0, -7)
+
+	created := make([]Payout, 0)
+	for _, ownerID := range owners {
+		p, err := CreatePayoutForOwner(ownerID, periodStart, periodEnd)
+		if err != nil {
+			log.Printf("Error creating payout for owner %d: %v\n", ownerID, err)
+			continue
+		}
+		if p == nil {
+			continue // Nothing owed yet, carried into the next period
+		}
+		created = append(created, *p)
+		msg := fmt.Sprintf("Your payout statement for %s - %s is ready: %s.",
+			periodStart.In(venue.IST).Format("02 Jan"), periodEnd.Add(-time.Second).In(venue.IST).Format("02 Jan 2006"), p.Net.Format())
+		_ = notification.CreateNotification(ownerID, msg, "info")
+	}
+	return created, nil
+}
+
+// GenerateDueStatements closes the last finished week. Safe to run repeatedly.
+func GenerateDueStatements(now time.Time) ([]Payout, error) {
+	start, _ := PeriodFor(now)
+	return GenerateStatements(start)
+}
+
+// GetOwnerBalance sums what an owner has earned since their last statement
+func GetOwnerBalance(ownerID int64) (*Balance, error) {
+	entries, err := FindUnsettledEntries(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	b := &Balance{Entries: entries}
+	for _, e := range entries {
+		if e.Gross > 0 {
+			b.Gross += e.Gross
+		} else {
+			b.Refunds -= e.Gross
+		}
+		b.Commission += e.Commission
+		b.GatewayFees += e.GatewayFee
+		b.Net += e.Net
+	}
+	return b, nil
+}
+
+// GetStatement returns a payout with its lines to its owner or an admin
+func GetStatement(payoutID, userID int64, userRole string) (*Payout, error) {
+	p, err := FindPayoutByID(payoutID)
+	if err != nil {
+		return nil, errors.New("statement not found")
+	}
+	if userRole != "admin" && p.OwnerID != userID {
+		return nil, errors.New("statement not found")
+	}
+	p.Entries, err = FindEntriesByPayoutID(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MarkPaid records that the bank transfer for a statement went out
+func MarkPaid(payoutID, adminID int64, reference string) (*Payout, error) {
+	if reference == "" {
+		return nil, errors.New("transfer reference is required")
+	}
+	ok, err := MarkPayoutPaid(payoutID, adminID, reference)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("payout not found or already paid")
+	}
+	p, err := FindPayoutByID(payoutID)
+	if err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("Your payout of %s has been sent (ref %s).", p.Net.Format(), reference)
+	_ = notification.CreateNotification(p.OwnerID, msg, "success")
+	return p, nil
+}
+
+// WriteStatementCSV writes a statement's lines followed by its totals
+func WriteStatementCSV(w io.Writer, p *Payout) error {
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"Date", "Venue", "Type", "Booking ID", "Pack Purchase ID", "Gross", "Commission %", "Commission", "Gateway Fee", "Net"})
+	for _, e := range p.Entries {
+		_ = out.Write([]string{
+			e.CreatedAt.In(venue.IST).Format("2006-01-02 15:04"),
+			e.VenueName,
+			e.Type,
+			idOrBlank(e.BookingID),
+			idOrBlank(e.PackPurchaseID),
+			e.Gross.String(),
+			strconv.FormatFloat(e.CommissionRate, 'f', 2, 64),
+			e.Commission.String(),
+			e.GatewayFee.String(),
+			e.Net.String(),
+		})
+	}
+	_ = out.Write([]string{})
+	_ = out.Write([]string{"Totals", "", "", "", "", (p.Gross - p.Refunds).String(), "", p.Commission.String(), p.GatewayFees.String(), p.Net.String()})
+	out.Flush()
+	return out.Error()
+}
+
+func idOrBlank(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
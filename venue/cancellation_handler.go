@@ -0,0 +1,60 @@
+// venue/cancellation_handler.go
+package venue
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCancellationPolicyHandler handles GET /api/v1/venues/:id/cancellation-policy
+func GetCancellationPolicyHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	policy, err := GetCancellationPolicy(venueID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch cancellation policy"})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateCancellationPolicyHandler handles PUT /api/v1/venues/:id/cancellation-policy
+func UpdateCancellationPolicyHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var req struct {
+		Tiers []CancellationTier `json:"tiers"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	policy, err := SetCancellationPolicy(venueID, req.Tiers)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
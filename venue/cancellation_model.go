@@ -0,0 +1,24 @@
+// venue/cancellation_model.go
+package venue
+
+import "time"
+
+// CancellationTier refunds RefundPercent of the price when a booking is canceled
+// at least MinHoursBefore hours before it starts. The tier with the largest
+// MinHoursBefore that still fits wins; cancellations below every tier get nothing.
+type CancellationTier struct {
+	ID             int64     `json:"id"`
+	VenueID        int64     `json:"venue_id"`
+	MinHoursBefore int       `json:"min_hours_before"`
+	RefundPercent  int       `json:"refund_percent"` // 0-100
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CancellationPolicy is what players see before booking
+type CancellationPolicy struct {
+	VenueID int64              `json:"venue_id"`
+	Tiers   []CancellationTier `json:"tiers"` // Largest MinHoursBefore first
+	// Manual is true when the venue has no tiers: paid cancellations then wait for the owner
+	Manual  bool     `json:"manual"`
+	Summary []string `json:"summary"` // Human-readable lines, e.g. "100% refund if canceled 24h or more before start"
+}
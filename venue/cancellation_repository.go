@@ -0,0 +1,65 @@
+// venue/cancellation_repository.go
+package venue
+
+import (
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// FindCancellationTiersByVenueID fetches a venue's tiers, largest notice first
+func FindCancellationTiersByVenueID(venueID int64) ([]CancellationTier, error) {
+	query := `
+		SELECT id, venue_id, min_hours_before, refund_percent, created_at
+		FROM cancellation_policy_tiers
+		WHERE venue_id = ?
+		ORDER BY min_hours_before DESC
+	`
+	rows, err := db.DB.Query(query, venueID)
+	if err != nil {
+		log.Println("Error fetching cancellation tiers:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := make([]CancellationTier, 0)
+	for rows.Next() {
+		var t CancellationTier
+		if err := rows.Scan(&t.ID, &t.VenueID, &t.MinHoursBefore, &t.RefundPercent, &t.CreatedAt); err != nil {
+			log.Println("Error scanning cancellation tier:", err)
+			continue
+		}
+		tiers = append(tiers, t)
+	}
+	return tiers, nil
+}
+
+// ReplaceCancellationTiers swaps the whole policy of a venue in one transaction
+func ReplaceCancellationTiers(venueID int64, tiers []CancellationTier) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Println("Error starting cancellation policy transaction:", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM cancellation_policy_tiers WHERE venue_id = ?`, venueID); err != nil {
+		log.Println("Error clearing cancellation tiers:", err)
+		return err
+	}
+
+	for i := range tiers {
+		result, err := tx.Exec(
+			`INSERT INTO cancellation_policy_tiers (venue_id, min_hours_before, refund_percent) VALUES (?, ?, ?)`,
+			venueID, tiers[i].MinHoursBefore, tiers[i].RefundPercent,
+		)
+		if err != nil {
+			log.Println("Error inserting cancellation tier:", err)
+			return err
+		}
+		tiers[i].ID, _ = result.LastInsertId()
+		tiers[i].VenueID = venueID
+	}
+
+	return tx.Commit()
+}
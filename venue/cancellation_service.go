@@ -0,0 +1,80 @@
+// venue/cancellation_service.go
+package venue
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// SetCancellationPolicy validates and stores a venue's tiers. An empty list
+// switches the venue back to manual refund approval.
+func SetCancellationPolicy(venueID int64, tiers []CancellationTier) (*CancellationPolicy, error) {
+	seen := make(map[int]bool)
+	for _, t := range tiers {
+		if t.MinHoursBefore < 0 || t.MinHoursBefore > 24*30 {
+			return nil, errors.New("min_hours_before must be between 0 and 720")
+		}
+		if t.RefundPercent < 0 || t.RefundPercent > 100 {
+			return nil, errors.New("refund_percent must be between 0 and 100")
+		}
+		if seen[t.MinHoursBefore] {
+			return nil, errors.New("each tier needs a different min_hours_before")
+		}
+		seen[t.MinHoursBefore] = true
+	}
+
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinHoursBefore > tiers[j].MinHoursBefore })
+	// More notice must never refund less
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i].RefundPercent > tiers[i-1].RefundPercent {
+			return nil, errors.New("refund percent cannot grow as the start time gets closer")
+		}
+	}
+
+	if err := ReplaceCancellationTiers(venueID, tiers); err != nil {
+		return nil, errors.New("failed to save cancellation policy")
+	}
+	return GetCancellationPolicy(venueID)
+}
+
+// GetCancellationPolicy returns the venue's policy with a readable summary
+func GetCancellationPolicy(venueID int64) (*CancellationPolicy, error) {
+	tiers, err := FindCancellationTiersByVenueID(venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &CancellationPolicy{VenueID: venueID, Tiers: tiers, Manual: len(tiers) == 0}
+	if policy.Manual {
+		policy.Summary = []string{
+			"Free cancellation up to 2 hours before start.",
+			"Refunds for paid bookings are reviewed by the venue.",
+		}
+		return policy, nil
+	}
+
+	for i, t := range tiers {
+		var line string
+		if i == 0 {
+			line = fmt.Sprintf("%d%% refund if canceled %dh or more before start", t.RefundPercent, t.MinHoursBefore)
+		} else {
+			line = fmt.Sprintf("%d%% refund if canceled between %dh and %dh before start", t.RefundPercent, t.MinHoursBefore, tiers[i-1].MinHoursBefore)
+		}
+		policy.Summary = append(policy.Summary, line)
+	}
+	if last := tiers[len(tiers)-1]; last.MinHoursBefore > 0 {
+		policy.Summary = append(policy.Summary, fmt.Sprintf("No refund if canceled less than %dh before start", last.MinHoursBefore))
+	}
+	return policy, nil
+}
+
+// RefundPercentFor picks the tier for a cancellation made hoursBefore the start
+func RefundPercentFor(tiers []CancellationTier, hoursBefore float64) int {
+	for _, t := range tiers { // largest notice first
+		if hoursBefore >= float64(t.MinHoursBefore) {
+			return t.RefundPercent
+		}
+	}
+	return 0
+}
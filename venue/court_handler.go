@@ -0,0 +1,130 @@
+// venue/court_handler.go
+package venue
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCourtsHandler handles GET /api/v1/venues/:id/courts
+func GetCourtsHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	courts, err := GetVenueCourts(venueID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch courts"})
+		return
+	}
+	c.JSON(http.StatusOK, courts)
+}
+
+// CreateCourtHandler handles POST /api/v1/venues/:id/courts
+func CreateCourtHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var court Court
+	if err := c.ShouldBindJSON(&court); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := AddCourt(venueID, &court); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, court)
+}
+
+// UpdateCourtHandler handles PUT /api/v1/courts/:id
+func UpdateCourtHandler(c *gin.Context) {
+	courtID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+		return
+	}
+
+	existing, err := FindCourtByID(courtID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := VerifyVenueOwnership(existing.VenueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var court Court
+	if err := c.ShouldBindJSON(&court); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := ModifyCourt(courtID, &court); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Court updated successfully"})
+}
+
+// DeleteCourtHandler handles DELETE /api/v1/courts/:id
+func DeleteCourtHandler(c *gin.Context) {
+	courtID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court ID"})
+		return
+	}
+
+	existing, err := FindCourtByID(courtID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := VerifyVenueOwnership(existing.VenueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := RemoveCourt(courtID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove court"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Court removed successfully"})
+}
@@ -0,0 +1,19 @@
+// venue/court_model.go
+package venue
+
+import (
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// Court is a separately bookable unit inside a venue (a snooker table, a second pitch, ...)
+type Court struct {
+	ID            int64        `json:"id"`
+	VenueID       int64        `json:"venue_id"`
+	Name          string       `json:"name"`
+	SportCategory string       `json:"sport_category"`
+	PricePerHour  *money.Money `json:"price_per_hour,omitempty"` // nil = use the venue's price
+	IsActive      bool         `json:"is_active"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
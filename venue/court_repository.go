@@ -0,0 +1,124 @@
+// venue/court_repository.go
+package venue
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// CreateCourt inserts a new court for a venue
+func CreateCourt(court *Court) error {
+	query := `INSERT INTO courts (venue_id, name, sport_category, price_per_hour) VALUES (?, ?, ?, ?)`
+
+	result, err := db.DB.Exec(query, court.VenueID, court.Name, court.SportCategory, court.PricePerHour)
+	if err != nil {
+		log.Println("Error inserting court:", err)
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	court.ID = id
+	court.IsActive = true
+	return nil
+}
+
+// FindCourtsByVenueID fetches the active courts of a venue
+func FindCourtsByVenueID(venueID int64) ([]Court, error) {
+	query := `
+		SELECT id, venue_id, name, sport_category, price_per_hour, is_active, created_at
+		FROM courts
+		WHERE venue_id = ? AND is_active = 1
+		ORDER BY name
+	`
+	rows, err := db.DB.Query(query, venueID)
+	if err != nil {
+		log.Println("Error fetching courts:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	courts := make([]Court, 0)
+	for rows.Next() {
+		c, err := scanCourt(rows)
+		if err != nil {
+			log.Println("Error scanning court:", err)
+			continue
+		}
+		courts = append(courts, *c)
+	}
+	return courts, nil
+}
+
+// FindCourtByID fetches a single court (active or not)
+func FindCourtByID(courtID int64) (*Court, error) {
+	query := `
+		SELECT id, venue_id, name, sport_category, price_per_hour, is_active, created_at
+		FROM courts
+		WHERE id = ?
+	`
+	rows, err := db.DB.Query(query, courtID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanCourt(rows)
+	}
+	return nil, sql.ErrNoRows
+}
+
+// CountActiveCourts tells whether a venue is split into courts
+func CountActiveCourts(venueID int64) (int, error) {
+	var count int
+	err := db.DB.QueryRow(`SELECT COUNT(*) FROM courts WHERE venue_id = ? AND is_active = 1`, venueID).Scan(&count)
+	if err != nil {
+		log.Println("Error counting courts:", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// UpdateCourtDetails updates the editable fields of a court
+func UpdateCourtDetails(court *Court) error {
+	query := `UPDATE courts SET name = ?, sport_category = ?, price_per_hour = ? WHERE id = ?`
+	_, err := db.DB.Exec(query, court.Name, court.SportCategory, court.PricePerHour, court.ID)
+	if err != nil {
+		log.Println("Error updating court:", err)
+		return err
+	}
+	return nil
+}
+
+// DeactivateCourt (Soft Delete) hides a court but keeps its booking history
+func DeactivateCourt(courtID int64) error {
+	result, err := db.DB.Exec(`UPDATE courts SET is_active = 0 WHERE id = ?`, courtID)
+	if err != nil {
+		log.Println("Error deactivating court:", err)
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("court not found")
+	}
+	return nil
+}
+
+func scanCourt(rows *sql.Rows) (*Court, error) {
+	var c Court
+	var price sql.Null[money.Money]
+
+	err := rows.Scan(&c.ID, &c.VenueID, &c.Name, &c.SportCategory, &price, &c.IsActive, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if price.Valid {
+		c.PricePerHour = &price.V
+	}
+	return &c, nil
+}
@@ -0,0 +1,81 @@
+// venue/court_service.go
+package venue
+
+import (
+	"errors"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// AddCourt validates and saves a new court under a venue
+func AddCourt(venueID int64, court *Court) error {
+	if court.Name == "" {
+		return errors.New("court name is required")
+	}
+	if court.PricePerHour != nil && *court.PricePerHour < 0 {
+		return errors.New("price cannot be negative")
+	}
+
+	court.VenueID = venueID
+	if court.SportCategory == "" {
+		// Default to the venue's sport
+		var sport string
+		v, err := GetVenueByID(venueID)
+		if err == nil {
+			sport = v.SportCategory
+		}
+		court.SportCategory = sport
+	}
+	return CreateCourt(court)
+}
+
+// GetVenueCourts lists the bookable courts of a venue
+func GetVenueCourts(venueID int64) ([]Court, error) {
+	return FindCourtsByVenueID(venueID)
+}
+
+// ModifyCourt updates a court's name, sport and price override
+func ModifyCourt(courtID int64, updates *Court) error {
+	if updates.Name == "" {
+		return errors.New("court name is required")
+	}
+	if updates.PricePerHour != nil && *updates.PricePerHour < 0 {
+		return errors.New("price cannot be negative")
+	}
+	updates.ID = courtID
+	return UpdateCourtDetails(updates)
+}
+
+// RemoveCourt deactivates a court
+func RemoveCourt(courtID int64) error {
+	return DeactivateCourt(courtID)
+}
+
+// ResolveCourt checks the court choice for a booking at this venue.
+// Venues without courts are booked as a whole (courtID 0); venues with courts need one.
+func ResolveCourt(venueID int64, courtID int64) (*Court, error) {
+	if courtID == 0 {
+		count, err := CountActiveCourts(venueID)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, errors.New("court_id is required for this venue")
+		}
+		return nil, nil
+	}
+
+	court, err := FindCourtByID(courtID)
+	if err != nil || court.VenueID != venueID || !court.IsActive {
+		return nil, errors.New("court not found at this venue")
+	}
+	return court, nil
+}
+
+// EffectivePricePerHour returns the court's override if it has one, otherwise the venue price
+func EffectivePricePerHour(v *Venue, court *Court) money.Money {
+	if court != nil && court.PricePerHour != nil {
+		return *court.PricePerHour
+	}
+	return v.PricePerHour
+}
@@ -0,0 +1,130 @@
+// venue/pricing_handler.go
+package venue
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPriceRulesHandler handles GET /api/v1/venues/:id/price-rules
+func GetPriceRulesHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	rules, err := GetVenuePriceRules(venueID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch price rules"})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreatePriceRuleHandler handles POST /api/v1/venues/:id/price-rules
+func CreatePriceRuleHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var rule PriceRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := AddPriceRule(venueID, &rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdatePriceRuleHandler handles PUT /api/v1/price-rules/:id
+func UpdatePriceRuleHandler(c *gin.Context) {
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	existing, err := FindPriceRuleByID(ruleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Price rule not found"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := VerifyVenueOwnership(existing.VenueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var rule PriceRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := ModifyPriceRule(existing, &rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price rule updated successfully"})
+}
+
+// DeletePriceRuleHandler handles DELETE /api/v1/price-rules/:id
+func DeletePriceRuleHandler(c *gin.Context) {
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	existing, err := FindPriceRuleByID(ruleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Price rule not found"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := VerifyVenueOwnership(existing.VenueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := RemovePriceRule(ruleID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete price rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price rule deleted successfully"})
+}
@@ -0,0 +1,51 @@
+// venue/pricing_model.go
+package venue
+
+import (
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// IST is the zone every venue's opening hours and price rules are written in
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+// PriceRule overrides the hourly rate on some days of the week between two clock times.
+// When several rules match the same minute, the highest Priority wins; on a tie a
+// court-specific rule beats a venue-wide one, then the older rule wins.
+type PriceRule struct {
+	ID           int64       `json:"id"`
+	VenueID      int64       `json:"venue_id"`
+	CourtID      int64       `json:"court_id,omitempty"` // 0 = applies to every court
+	Name         string      `json:"name"`               // e.g. "Weekend evening"
+	Days         []int       `json:"days"`               // 0 = Sunday ... 6 = Saturday; empty = every day
+	StartTime    string      `json:"start_time"`         // "HH:MM" (IST)
+	EndTime      string      `json:"end_time"`           // "HH:MM" (IST), "24:00" for midnight
+	PricePerHour money.Money `json:"price_per_hour"`
+	Priority     int         `json:"priority"`
+	IsActive     bool        `json:"is_active"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// PriceLineItem is one stretch of a booking charged at a single rate
+type PriceLineItem struct {
+	RuleID      int64       `json:"rule_id,omitempty"` // 0 = the standard venue/court rate
+	Label       string      `json:"label"`
+	StartTime   time.Time   `json:"start_time"`
+	EndTime     time.Time   `json:"end_time"`
+	Hours       float64     `json:"hours"`
+	RatePerHour money.Money `json:"rate_per_hour"`
+	Amount      money.Money `json:"amount"`
+}
+
+// PriceQuote is the itemised price of a time range
+type PriceQuote struct {
+	VenueID   int64           `json:"venue_id"`
+	CourtID   int64           `json:"court_id,omitempty"`
+	StartTime time.Time       `json:"start_time"`
+	EndTime   time.Time       `json:"end_time"`
+	Items     []PriceLineItem `json:"items"`
+	Total     money.Money     `json:"total"`
+	// Cancellation is the venue's refund policy, attached so players see it before booking
+	Cancellation *CancellationPolicy `json:"cancellation_policy,omitempty"`
+}
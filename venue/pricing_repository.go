@@ -0,0 +1,147 @@
+// venue/pricing_repository.go
+package venue
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// CreatePriceRule inserts a new price rule for a venue
+func CreatePriceRule(rule *PriceRule) error {
+	query := `
+		INSERT INTO price_rules (venue_id, court_id, name, days, start_time, end_time, price_per_hour, priority)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.DB.Exec(query,
+		rule.VenueID, nullableID(rule.CourtID), rule.Name, joinDays(rule.Days),
+		rule.StartTime, rule.EndTime, rule.PricePerHour, rule.Priority,
+	)
+	if err != nil {
+		log.Println("Error inserting price rule:", err)
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+	rule.ID = id
+	rule.IsActive = true
+	return nil
+}
+
+// FindPriceRulesByVenueID fetches the active rules of a venue, strongest first
+func FindPriceRulesByVenueID(venueID int64) ([]PriceRule, error) {
+	query := `
+		SELECT id, venue_id, COALESCE(court_id, 0), name, days, start_time, end_time, price_per_hour, priority, is_active, created_at
+		FROM price_rules
+		WHERE venue_id = ? AND is_active = 1
+		ORDER BY priority DESC, court_id IS NULL, id ASC
+	`
+	rows, err := db.DB.Query(query, venueID)
+	if err != nil {
+		log.Println("Error fetching price rules:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]PriceRule, 0)
+	for rows.Next() {
+		r, err := scanPriceRule(rows)
+		if err != nil {
+			log.Println("Error scanning price rule:", err)
+			continue
+		}
+		rules = append(rules, *r)
+	}
+	return rules, nil
+}
+
+// FindPriceRuleByID fetches a single rule
+func FindPriceRuleByID(ruleID int64) (*PriceRule, error) {
+	query := `
+		SELECT id, venue_id, COALESCE(court_id, 0), name, days, start_time, end_time, price_per_hour, priority, is_active, created_at
+		FROM price_rules
+		WHERE id = ?
+	`
+	rows, err := db.DB.Query(query, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanPriceRule(rows)
+	}
+	return nil, sql.ErrNoRows
+}
+
+// UpdatePriceRuleDetails updates the editable fields of a rule
+func UpdatePriceRuleDetails(rule *PriceRule) error {
+	query := `
+		UPDATE price_rules
+		SET court_id = ?, name = ?, days = ?, start_time = ?, end_time = ?, price_per_hour = ?, priority = ?
+		WHERE id = ?
+	`
+	_, err := db.DB.Exec(query,
+		nullableID(rule.CourtID), rule.Name, joinDays(rule.Days),
+		rule.StartTime, rule.EndTime, rule.PricePerHour, rule.Priority, rule.ID,
+	)
+	if err != nil {
+		log.Println("Error updating price rule:", err)
+		return err
+	}
+	return nil
+}
+
+// DeletePriceRule removes a rule. Past bookings keep their stored breakdown.
+func DeletePriceRule(ruleID int64) error {
+	result, err := db.DB.Exec(`DELETE FROM price_rules WHERE id = ?`, ruleID)
+	if err != nil {
+		log.Println("Error deleting price rule:", err)
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("price rule not found")
+	}
+	return nil
+}
+
+func scanPriceRule(rows *sql.Rows) (*PriceRule, error) {
+	var r PriceRule
+	var days string
+
+	err := rows.Scan(&r.ID, &r.VenueID, &r.CourtID, &r.Name, &days, &r.StartTime, &r.EndTime,
+		&r.PricePerHour, &r.Priority, &r.IsActive, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	r.Days = splitDays(days)
+	return &r, nil
+}
+
+// Days are stored as a comma separated list, e.g. "0,6" for weekends
+func joinDays(days []int) string {
+	parts := make([]string, 0, len(days))
+	for _, d := range days {
+		parts = append(parts, strconv.Itoa(d))
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitDays(s string) []int {
+	days := make([]int, 0)
+	for _, part := range strings.Split(s, ",") {
+		if d, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+func nullableID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
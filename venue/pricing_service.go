@@ -0,0 +1,224 @@
+// venue/pricing_service.go
+package venue
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// ParseClock turns "HH:MM" into minutes after midnight ("24:00" is allowed as an end time)
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		if s == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func validatePriceRule(venueID int64, rule *PriceRule) error {
+	if rule.Name == "" {
+		return errors.New("rule name is required")
+	}
+	if rule.PricePerHour < 0 {
+		return errors.New("price cannot be negative")
+	}
+	for _, d := range rule.Days {
+		if d < 0 || d > 6 {
+			return errors.New("days must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+
+	start, err := ParseClock(rule.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := ParseClock(rule.EndTime)
+	if err != nil {
+		return err
+	}
+	// Overnight ranges are written as two rules (e.g. 22:00-24:00 and 00:00-02:00)
+	if end <= start {
+		return errors.New("end time must be after start time")
+	}
+
+	if rule.CourtID != 0 {
+		court, err := FindCourtByID(rule.CourtID)
+		if err != nil || court.VenueID != venueID {
+			return errors.New("court not found at this venue")
+		}
+	}
+	return nil
+}
+
+// AddPriceRule validates and saves a new rule under a venue
+func AddPriceRule(venueID int64, rule *PriceRule) error {
+	if err := validatePriceRule(venueID, rule); err != nil {
+		return err
+	}
+	rule.VenueID = venueID
+	return CreatePriceRule(rule)
+}
+
+// GetVenuePriceRules lists the active rules of a venue
+func GetVenuePriceRules(venueID int64) ([]PriceRule, error) {
+	return FindPriceRulesByVenueID(venueID)
+}
+
+// ModifyPriceRule updates an existing rule
+func ModifyPriceRule(existing *PriceRule, updates *PriceRule) error {
+	if err := validatePriceRule(existing.VenueID, updates); err != nil {
+		return err
+	}
+	updates.ID = existing.ID
+	updates.VenueID = existing.VenueID
+	return UpdatePriceRuleDetails(updates)
+}
+
+// RemovePriceRule deletes a rule
+func RemovePriceRule(ruleID int64) error {
+	return DeletePriceRule(ruleID)
+}
+
+// matches tells whether the rule covers this weekday and minute of the day
+func (r *PriceRule) matches(weekday time.Weekday, minute int) bool {
+	if len(r.Days) > 0 {
+		found := false
+		for _, d := range r.Days {
+			if d == int(weekday) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	start, _ := ParseClock(r.StartTime)
+	end, _ := ParseClock(r.EndTime)
+	return minute >= start && minute < end
+}
+
+// Pricer prices ranges at one venue/court with the rules loaded once
+// (the slot grid prices dozens of slots per request)
+type Pricer struct {
+	venueID  int64
+	courtID  int64
+	baseRate money.Money
+	rules    []PriceRule // strongest first
+}
+
+// NewPricer loads the rules that apply to this venue/court
+func NewPricer(v *Venue, court *Court) (*Pricer, error) {
+	allRules, err := FindPriceRulesByVenueID(v.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the venue-wide rules plus the ones for this court (already sorted strongest first)
+	var courtID int64
+	if court != nil {
+		courtID = court.ID
+	}
+	rules := make([]PriceRule, 0, len(allRules))
+	for _, r := range allRules {
+		if r.CourtID == 0 || r.CourtID == courtID {
+			rules = append(rules, r)
+		}
+	}
+
+	return &Pricer{
+		venueID:  v.ID,
+		courtID:  courtID,
+		baseRate: EffectivePricePerHour(v, court),
+		rules:    rules,
+	}, nil
+}
+
+// QuotePrice prices [start, end) at this venue/court
+func QuotePrice(v *Venue, court *Court, start, end time.Time) (*PriceQuote, error) {
+	p, err := NewPricer(v, court)
+	if err != nil {
+		return nil, err
+	}
+	return p.Quote(start, end)
+}
+
+// Quote cuts [start, end) at midnight and at every rule boundary (in IST); each piece is
+// charged at the winning rule's rate, or the standard rate when no rule matches.
+// Adjacent pieces at the same rate are merged.
+func (p *Pricer) Quote(start, end time.Time) (*PriceQuote, error) {
+	if !end.After(start) {
+		return nil, errors.New("end time must be after start time")
+	}
+
+	rules := p.rules
+	baseRate := p.baseRate
+	quote := &PriceQuote{
+		VenueID:   p.venueID,
+		CourtID:   p.courtID,
+		StartTime: start,
+		EndTime:   end,
+		Items:     make([]PriceLineItem, 0),
+	}
+
+	cursor := start.In(IST)
+	stop := end.In(IST)
+	for cursor.Before(stop) {
+		midnight := time.Date(cursor.Year(), cursor.Month(), cursor.Day(), 0, 0, 0, 0, IST)
+		minute := int(cursor.Sub(midnight) / time.Minute)
+
+		// 1. Which rate applies right now?
+		var winner *PriceRule
+		for i := range rules {
+			if rules[i].matches(cursor.Weekday(), minute) {
+				winner = &rules[i]
+				break
+			}
+		}
+
+		// 2. How long does it last? Until the next midnight, rule boundary or the end.
+		next := midnight.AddDate(0, 0, 1)
+		for _, r := range rules {
+			for _, clock := range []string{r.StartTime, r.EndTime} {
+				m, _ := ParseClock(clock)
+				boundary := midnight.Add(time.Duration(m) * time.Minute)
+				if boundary.After(cursor) && boundary.Before(next) {
+					next = boundary
+				}
+			}
+		}
+		if stop.Before(next) {
+			next = stop
+		}
+
+		item := PriceLineItem{Label: "Standard rate", RatePerHour: baseRate, StartTime: cursor, EndTime: next}
+		if winner != nil {
+			item.RuleID = winner.ID
+			item.Label = winner.Name
+			item.RatePerHour = winner.PricePerHour
+		}
+
+		// 3. Merge with the previous piece when nothing changed
+		if n := len(quote.Items); n > 0 && quote.Items[n-1].RuleID == item.RuleID && quote.Items[n-1].RatePerHour == item.RatePerHour {
+			quote.Items[n-1].EndTime = next
+		} else {
+			quote.Items = append(quote.Items, item)
+		}
+		cursor = next
+	}
+
+	// Each piece is rounded to the paisa once; the total is their exact sum
+	for i := range quote.Items {
+		item := &quote.Items[i]
+		item.Hours = item.EndTime.Sub(item.StartTime).Hours()
+		item.Amount = item.RatePerHour.Mul(item.Hours)
+		quote.Total += item.Amount
+	}
+	return quote, nil
+}
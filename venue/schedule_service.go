@@ -0,0 +1,134 @@
+// venue/schedule_service.go
+package venue
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultSlotDurationMinutes is used when the owner hasn't picked a slot length
+const DefaultSlotDurationMinutes = 60
+
+// ErrInvalidSchedule wraps every validation error of the opening hours / slot length
+var ErrInvalidSchedule = errors.New("invalid venue schedule")
+
+// DaySchedule is a venue's operating window on one calendar day (IST)
+type DaySchedule struct {
+	Open       time.Time
+	Close      time.Time
+	LunchStart time.Time // zero when there is no lunch break
+	LunchEnd   time.Time
+}
+
+// HasLunch tells whether the venue closes for lunch
+func (d *DaySchedule) HasLunch() bool {
+	return !d.LunchStart.IsZero()
+}
+
+// validateSchedule checks opening hours, the lunch break and the slot length before saving
+func validateSchedule(v *Venue) error {
+	if v.OpeningTime == "" {
+		v.OpeningTime = "06:00"
+	}
+	if v.ClosingTime == "" {
+		v.ClosingTime = "23:00"
+	}
+	if v.SlotDurationMinutes <= 0 {
+		v.SlotDurationMinutes = DefaultSlotDurationMinutes
+	}
+
+	open, err := ParseClock(v.OpeningTime)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	closing, err := ParseClock(v.ClosingTime)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	if closing <= open {
+		return fmt.Errorf("%w: closing time must be after opening time", ErrInvalidSchedule)
+	}
+	if v.SlotDurationMinutes < 15 || v.SlotDurationMinutes > 240 {
+		return fmt.Errorf("%w: slot duration must be between 15 and 240 minutes", ErrInvalidSchedule)
+	}
+
+	if (v.LunchStart == "") != (v.LunchEnd == "") {
+		return fmt.Errorf("%w: both lunch start and lunch end are required", ErrInvalidSchedule)
+	}
+	if v.LunchStart != "" {
+		lStart, err := ParseClock(v.LunchStart)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		lEnd, err := ParseClock(v.LunchEnd)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		if lEnd <= lStart || lStart < open || lEnd > closing {
+			return fmt.Errorf("%w: lunch break must lie inside opening hours", ErrInvalidSchedule)
+		}
+	}
+	return nil
+}
+
+// ScheduleForDay returns the venue's operating window on the IST calendar day of `day`
+func ScheduleForDay(v *Venue, day time.Time) (*DaySchedule, error) {
+	day = day.In(IST)
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, IST)
+	at := func(clock string) (time.Time, error) {
+		m, err := ParseClock(clock)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return midnight.Add(time.Duration(m) * time.Minute), nil
+	}
+
+	var d DaySchedule
+	var err error
+	if d.Open, err = at(v.OpeningTime); err != nil {
+		return nil, err
+	}
+	if d.Close, err = at(v.ClosingTime); err != nil {
+		return nil, err
+	}
+	if v.LunchStart != "" && v.LunchEnd != "" {
+		if d.LunchStart, err = at(v.LunchStart); err != nil {
+			return nil, err
+		}
+		if d.LunchEnd, err = at(v.LunchEnd); err != nil {
+			return nil, err
+		}
+	}
+	return &d, nil
+}
+
+// SlotDuration returns the venue's grid step
+func SlotDuration(v *Venue) time.Duration {
+	if v.SlotDurationMinutes <= 0 {
+		return DefaultSlotDurationMinutes * time.Minute
+	}
+	return time.Duration(v.SlotDurationMinutes) * time.Minute
+}
+
+// CheckBookable rejects a range that is outside operating hours, touches the lunch
+// break or doesn't start and end on the venue's slot grid
+func CheckBookable(v *Venue, start, end time.Time) error {
+	d, err := ScheduleForDay(v, start)
+	if err != nil {
+		return errors.New("venue opening hours are not configured correctly")
+	}
+
+	if start.Before(d.Open) || end.After(d.Close) {
+		return fmt.Errorf("bookings are only possible between %s and %s", v.OpeningTime, v.ClosingTime)
+	}
+	if d.HasLunch() && start.Before(d.LunchEnd) && end.After(d.LunchStart) {
+		return fmt.Errorf("the venue is closed for lunch between %s and %s", v.LunchStart, v.LunchEnd)
+	}
+
+	step := SlotDuration(v)
+	if start.Sub(d.Open)%step != 0 || end.Sub(start)%step != 0 {
+		return fmt.Errorf("bookings must start and end on the %d-minute slot grid", int(step/time.Minute))
+	}
+	return nil
+}
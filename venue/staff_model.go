@@ -0,0 +1,15 @@
+// venue/staff_model.go
+package venue
+
+import "time"
+
+// VenueStaff is a user the owner allowed to work the front desk (e.g. check players in)
+type VenueStaff struct {
+	ID        int64     `json:"id"`
+	VenueID   int64     `json:"venue_id"`
+	UserID    int64     `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,66 @@
+// venue/staff_repository.go
+package venue
+
+import (
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+)
+
+// AddStaffMember links a user to a venue as staff (adding twice is a no-op)
+func AddStaffMember(venueID, userID int64) error {
+	_, err := db.DB.Exec(`INSERT IGNORE INTO venue_staff (venue_id, user_id) VALUES (?, ?)`, venueID, userID)
+	if err != nil {
+		log.Println("Error adding venue staff:", err)
+	}
+	return err
+}
+
+// RemoveStaffMember unlinks a staff user from a venue
+func RemoveStaffMember(venueID, userID int64) (bool, error) {
+	result, err := db.DB.Exec(`DELETE FROM venue_staff WHERE venue_id = ? AND user_id = ?`, venueID, userID)
+	if err != nil {
+		log.Println("Error removing venue staff:", err)
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// FindStaffByVenueID lists a venue's staff
+func FindStaffByVenueID(venueID int64) ([]VenueStaff, error) {
+	query := `
+		SELECT s.id, s.venue_id, s.user_id, u.first_name, u.last_name, u.email, s.created_at
+		FROM venue_staff s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.venue_id = ?
+		ORDER BY s.created_at
+	`
+	rows, err := db.DB.Query(query, venueID)
+	if err != nil {
+		log.Println("Error fetching venue staff:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	staff := make([]VenueStaff, 0)
+	for rows.Next() {
+		var s VenueStaff
+		if err := rows.Scan(&s.ID, &s.VenueID, &s.UserID, &s.FirstName, &s.LastName, &s.Email, &s.CreatedAt); err != nil {
+			log.Println("Error scanning venue staff:", err)
+			continue
+		}
+		staff = append(staff, s)
+	}
+	return staff, nil
+}
+
+// IsVenueStaff checks if a user is staff at a venue
+func IsVenueStaff(venueID int64, userID int64) (bool, error) {
+	var count int
+	err := db.DB.QueryRow(`SELECT COUNT(*) FROM venue_staff WHERE venue_id = ? AND user_id = ?`, venueID, userID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,52 @@
+// venue/staff_service.go
+package venue
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/JkD004/playarena-backend/user"
+)
+
+// AddVenueStaff gives an existing account staff access to a venue
+func AddVenueStaff(venueID int64, email string) ([]VenueStaff, error) {
+	u, err := user.FindUserByEmail(strings.TrimSpace(email))
+	if err != nil {
+		return nil, errors.New("no account found with this email")
+	}
+	if err := AddStaffMember(venueID, u.ID); err != nil {
+		return nil, errors.New("failed to add staff member")
+	}
+	return FindStaffByVenueID(venueID)
+}
+
+// RemoveVenueStaff takes a user's staff access away
+func RemoveVenueStaff(venueID, userID int64) error {
+	removed, err := RemoveStaffMember(venueID, userID)
+	if err != nil {
+		return errors.New("failed to remove staff member")
+	}
+	if !removed {
+		return errors.New("staff member not found")
+	}
+	return nil
+}
+
+// VerifyVenueStaffAccess allows the venue's owner and its staff (front-desk actions such as check-in)
+func VerifyVenueStaffAccess(venueID int64, userID int64) error {
+	isOwner, err := IsVenueOwner(venueID, userID)
+	if err != nil {
+		return err
+	}
+	if isOwner {
+		return nil
+	}
+	isStaff, err := IsVenueStaff(venueID, userID)
+	if err != nil {
+		return err
+	}
+	if !isStaff {
+		return errors.New("you are not staff at this venue")
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ package venue
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -34,6 +35,10 @@ func CreateVenueHandler(c *gin.Context) {
 
 	err := CreateNewVenue(&venue, userID)
 	if err != nil {
+		if errors.Is(err, ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create venue"})
 		return
 	}
@@ -299,6 +304,44 @@ func GetReviewsHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, reviews)
 }
 
+// -------------------------------------------------------
+// RESCHEDULE POLICY (OWNER OR ADMIN)
+// -------------------------------------------------------
+func UpdateReschedulePolicyHandler(c *gin.Context) {
+	venueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid venue ID"})
+		return
+	}
+
+	// 🔒 SECURITY CHECK
+	userID := c.MustGet("userID").(int64)
+	userRole := c.MustGet("userRole").(string)
+
+	if userRole != "admin" {
+		if err := VerifyVenueOwnership(venueID, userID); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var req struct {
+		MaxReschedules        int `json:"max_reschedules"`
+		RescheduleCutoffHours int `json:"reschedule_cutoff_hours"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := SetReschedulePolicy(venueID, req.MaxReschedules, req.RescheduleCutoffHours); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reschedule policy updated successfully"})
+}
+
 // -------------------------------------------------------
 // UPDATE VENUE (OWNER OR ADMIN)
 // -------------------------------------------------------
@@ -328,6 +371,10 @@ func UpdateVenueHandler(c *gin.Context) {
 
 	err = ModifyVenue(venueID, &venue)
 	if err != nil {
+		if errors.Is(err, ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update venue"})
 		return
 	}
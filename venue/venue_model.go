@@ -1,7 +1,11 @@
 // venue/venue_model.go
 package venue
 
-import "time"
+import (
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
 
 type Venue struct {
 	ID            int64     `json:"id"`
@@ -11,11 +15,14 @@ type Venue struct {
 	SportCategory string    `json:"sport_category"`
 	Description   string    `json:"description,omitempty"`
 	Address       string    `json:"address,omitempty"`
-	PricePerHour  float64   `json:"price_per_hour,omitempty"`
+	PricePerHour  money.Money `json:"price_per_hour,omitempty"`
 	OpeningTime   string    `json:"opening_time"`
 	ClosingTime   string    `json:"closing_time"`
 	LunchStart    string    `json:"lunch_start_time,omitempty"`
 	LunchEnd      string    `json:"lunch_end_time,omitempty"`
+	SlotDurationMinutes int `json:"slot_duration_minutes"` // Length of one slot in the booking grid
+	MaxReschedules        int `json:"max_reschedules"`         // How many times one booking may be moved
+	RescheduleCutoffHours int `json:"reschedule_cutoff_hours"` // No moves later than this before the start
 	CreatedAt     time.Time `json:"created_at"`
 	
 }
@@ -45,7 +52,7 @@ type VenueAdminDetail struct {
 	Name          string  `json:"name"`
 	Address       string  `json:"address"`
 	SportCategory string  `json:"sport_category"`
-	PricePerHour  float64 `json:"price_per_hour"`
+	PricePerHour  money.Money `json:"price_per_hour"`
 	Status        string  `json:"status"`
 	// Owner Details
 	OwnerID       int64   `json:"owner_id"`
@@ -7,13 +7,15 @@ import (
 	"github.com/JkD004/playarena-backend/db"
 	"log"
 	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
 )
 
 // CreateVenue inserts a new venue into the database
 func CreateVenue(venue *Venue) error {
 	query := `
-		INSERT INTO venues (owner_id, name, sport_category, description, address, price_per_hour, opening_time, closing_time, lunch_start_time, lunch_end_time, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'pending')
+		INSERT INTO venues (owner_id, name, sport_category, description, address, price_per_hour, opening_time, closing_time, lunch_start_time, lunch_end_time, slot_duration_minutes, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'pending')
 	`
 	// Handle nullable lunch times
 	var lunchStart, lunchEnd sql.NullString
@@ -26,7 +28,7 @@ func CreateVenue(venue *Venue) error {
 
 	result, err := db.DB.Exec(query,
 		venue.OwnerID, venue.Name, venue.SportCategory, venue.Description, venue.Address, venue.PricePerHour,
-		venue.OpeningTime, venue.ClosingTime, lunchStart, lunchEnd,
+		venue.OpeningTime, venue.ClosingTime, lunchStart, lunchEnd, venue.SlotDurationMinutes,
 	)
 
 	if err != nil {
@@ -56,7 +58,8 @@ func GetVenueOwner(tx *sql.Tx, venueID int64) (int64, error) {
 func FindVenuesByStatus(status string) ([]Venue, error) {
 	query := `
 		SELECT id, owner_id, status, name, sport_category, description, address, price_per_hour,
-		       opening_time, closing_time, lunch_start_time, lunch_end_time, created_at
+		       opening_time, closing_time, lunch_start_time, lunch_end_time, slot_duration_minutes,
+		       max_reschedules, reschedule_cutoff_hours, created_at
 		FROM venues WHERE status = ?
 	`
 	rows, err := db.DB.Query(query, status)
@@ -91,7 +94,8 @@ func UpdateVenueStatusInDB(tx *sql.Tx, venueID int64, newStatus string) error {
 func FindApprovedVenueByID(venueID int64) (*Venue, error) {
 	query := `
 		SELECT id, owner_id, status, name, sport_category, description, address, price_per_hour,
-		       opening_time, closing_time, lunch_start_time, lunch_end_time, created_at
+		       opening_time, closing_time, lunch_start_time, lunch_end_time, slot_duration_minutes,
+		       max_reschedules, reschedule_cutoff_hours, created_at
 		FROM venues 
 		WHERE id = ? AND status = 'approved'
 	`
@@ -154,7 +158,8 @@ func DeletePhoto(photoID int64) error {
 func FindVenuesByOwnerID(ownerID int64) ([]Venue, error) {
 	query := `
 		SELECT id, owner_id, status, name, sport_category, description, address, price_per_hour,
-		       opening_time, closing_time, lunch_start_time, lunch_end_time, created_at
+		       opening_time, closing_time, lunch_start_time, lunch_end_time, slot_duration_minutes,
+		       max_reschedules, reschedule_cutoff_hours, created_at
 		FROM venues WHERE owner_id = ?
 	`
 	rows, err := db.DB.Query(query, ownerID)
@@ -247,7 +252,7 @@ func UpdateVenueDetails(venue *Venue) error {
 	query := `
 		UPDATE venues 
 		SET name = ?, sport_category = ?, description = ?, address = ?, price_per_hour = ?,
-		    opening_time = ?, closing_time = ?, lunch_start_time = ?, lunch_end_time = ?
+		    opening_time = ?, closing_time = ?, lunch_start_time = ?, lunch_end_time = ?, slot_duration_minutes = ?
 		WHERE id = ?
 	`
 
@@ -261,7 +266,7 @@ func UpdateVenueDetails(venue *Venue) error {
 
 	_, err := db.DB.Exec(query,
 		venue.Name, venue.SportCategory, venue.Description, venue.Address, venue.PricePerHour,
-		venue.OpeningTime, venue.ClosingTime, lunchStart, lunchEnd,
+		venue.OpeningTime, venue.ClosingTime, lunchStart, lunchEnd, venue.SlotDurationMinutes,
 		venue.ID,
 	)
 	if err != nil {
@@ -271,17 +276,28 @@ func UpdateVenueDetails(venue *Venue) error {
 	return nil
 }
 
+// UpdateReschedulePolicy sets how often and how late bookings at a venue can be moved
+func UpdateReschedulePolicy(venueID int64, maxReschedules int, cutoffHours int) error {
+	query := `UPDATE venues SET max_reschedules = ?, reschedule_cutoff_hours = ? WHERE id = ?`
+	_, err := db.DB.Exec(query, maxReschedules, cutoffHours, venueID)
+	if err != nil {
+		log.Println("Error updating reschedule policy:", err)
+		return err
+	}
+	return nil
+}
+
 func scanVenue(rows *sql.Rows) (*Venue, error) {
 	var v Venue
 	var desc, addr, lStart, lEnd sql.NullString
-	var price sql.NullFloat64
+	var price sql.Null[money.Money]
 	var created sql.NullTime
 
 	err := rows.Scan(
 		&v.ID, &v.OwnerID, &v.Status, &v.Name, &v.SportCategory,
 		&desc, &addr, &price,
 		&v.OpeningTime, &v.ClosingTime, &lStart, &lEnd,
-		&created,
+		&v.SlotDurationMinutes, &v.MaxReschedules, &v.RescheduleCutoffHours, &created,
 	)
 	if err != nil {
 		return nil, err
@@ -289,7 +305,7 @@ func scanVenue(rows *sql.Rows) (*Venue, error) {
 
 	v.Description = desc.String
 	v.Address = addr.String
-	v.PricePerHour = price.Float64
+	v.PricePerHour = price.V
 	v.LunchStart = lStart.String
 	v.LunchEnd = lEnd.String
 	if created.Valid {
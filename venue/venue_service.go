@@ -18,6 +18,9 @@ func CreateNewVenue(venue *Venue, ownerID int64) error {
 
 	// You can add validation logic here later
 	// (e.g., check if name is empty, price is not negative)
+	if err := validateSchedule(venue); err != nil {
+		return err
+	}
 
 	// Call the repository to save to DB
 	err := CreateVenue(venue)
@@ -144,6 +147,9 @@ func AddReview(venueID, userID int64, rating int, comment string) error {
 
 func ModifyVenue(venueID int64, venueData *Venue) error {
 	// TODO: Add validation (e.g. ensure price is positive)
+	if err := validateSchedule(venueData); err != nil {
+		return err
+	}
 	venueData.ID = venueID
 	return UpdateVenueDetails(venueData)
 }
@@ -174,4 +180,15 @@ func GetVenueIdFromPhoto(photoID int64) (int64, error) {
 func ReplyToReview(reviewID int64, reply string) error {
 	// TODO: Check if the logged-in user actually owns the venue this review belongs to
 	return AddReviewReply(reviewID, reply)
-}
\ No newline at end of file
+}
+
+// SetReschedulePolicy validates and saves a venue's reschedule limits
+func SetReschedulePolicy(venueID int64, maxReschedules int, cutoffHours int) error {
+	if maxReschedules < 0 || maxReschedules > 10 {
+		return errors.New("max reschedules must be between 0 and 10")
+	}
+	if cutoffHours < 0 || cutoffHours > 168 {
+		return errors.New("cutoff must be between 0 and 168 hours")
+	}
+	return UpdateReschedulePolicy(venueID, maxReschedules, cutoffHours)
+}
@@ -0,0 +1,74 @@
+// wallet/wallet_handler.go
+package wallet
+
+import (
+	"net/http"
+
+	"github.com/JkD004/playarena-backend/gateway"
+	"github.com/gin-gonic/gin"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// GetWalletHandler handles GET /api/v1/wallet
+func GetWalletHandler(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	w, err := GetWallet(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch wallet"})
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+// CreateTopupHandler handles POST /api/v1/wallet/topup
+func CreateTopupHandler(c *gin.Context) {
+	var req struct {
+		Amount money.Money `json:"amount" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	t, err := StartTopup(userID, req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"topup_id": t.ID,
+		"order_id": t.RazorpayOrderID,
+		"amount":   t.Amount,
+		"key_id":   gateway.KeyID(),
+	})
+}
+
+// VerifyTopupHandler handles POST /api/v1/wallet/topup/verify
+func VerifyTopupHandler(c *gin.Context) {
+	var req struct {
+		TopupID           int64  `json:"topup_id" binding:"required"`
+		RazorpayOrderID   string `json:"razorpay_order_id"`
+		RazorpayPaymentID string `json:"razorpay_payment_id"`
+		RazorpaySignature string `json:"razorpay_signature"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userID := c.MustGet("userID").(int64)
+
+	t, err := CompleteTopup(userID, req.TopupID, req.RazorpayOrderID, req.RazorpayPaymentID, req.RazorpaySignature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	balance, _ := FindBalanceByUserID(userID)
+	c.JSON(http.StatusOK, gin.H{"status": t.Status, "balance": balance})
+}
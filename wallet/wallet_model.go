@@ -0,0 +1,66 @@
+// wallet/wallet_model.go
+package wallet
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// System accounts on the other side of every player movement.
+// Every transaction's entries sum to zero, so money is never created or lost.
+const (
+	AccountGateway        = "gateway"         // Money received through Razorpay top-ups
+	AccountBookingHolds   = "booking_holds"   // Wallet money set aside for unpaid bookings
+	AccountBookingRevenue = "booking_revenue" // Wallet money spent on confirmed bookings
+)
+
+// Transaction kinds; (kind, reference) is unique so replays post nothing
+const (
+	KindTopup          = "topup"
+	KindBookingHold    = "booking_hold"
+	KindBookingCapture = "booking_capture"
+	KindBookingRelease = "booking_release"
+	KindRefund         = "refund"
+)
+
+var (
+	ErrInsufficientFunds    = errors.New("insufficient wallet balance")
+	ErrDuplicateTransaction = errors.New("wallet transaction already recorded")
+)
+
+// Leg is one side of a transaction: a signed amount on an account (credit > 0)
+type Leg struct {
+	AccountID int64
+	Amount    money.Money
+}
+
+// Entry is one line of a player's statement
+type Entry struct {
+	ID            int64       `json:"id"`
+	TransactionID int64       `json:"transaction_id"`
+	Kind          string      `json:"kind"`
+	Reference     string      `json:"reference"`
+	Description   string      `json:"description,omitempty"`
+	Amount        money.Money `json:"amount"` // > 0 credit, < 0 debit
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// Wallet is the player's balance and recent statement
+type Wallet struct {
+	UserID  int64       `json:"user_id"`
+	Balance money.Money `json:"balance"`
+	Entries []Entry     `json:"entries"`
+}
+
+// Topup is a Razorpay payment that credits the wallet once verified
+type Topup struct {
+	ID              int64       `json:"id"`
+	UserID          int64       `json:"user_id"`
+	Amount          money.Money `json:"amount"`
+	RazorpayOrderID string      `json:"razorpay_order_id"`
+	PaymentID       string      `json:"razorpay_payment_id,omitempty"`
+	Status          string      `json:"status"` // 'pending' or 'completed'
+	CreatedAt       time.Time   `json:"created_at"`
+}
@@ -0,0 +1,172 @@
+// wallet/wallet_repository.go
+package wallet
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// userAccountTx returns (creating it on first use) the player's account, locked for the transaction
+func userAccountTx(tx *sql.Tx, userID int64) (int64, money.Money, error) {
+	if _, err := tx.Exec(`INSERT IGNORE INTO wallet_accounts (user_id) VALUES (?)`, userID); err != nil {
+		log.Println("Error creating wallet account:", err)
+		return 0, 0, err
+	}
+	var id int64
+	var balance money.Money
+	err := tx.QueryRow(`SELECT id, balance FROM wallet_accounts WHERE user_id = ? FOR UPDATE`, userID).Scan(&id, &balance)
+	if err != nil {
+		log.Println("Error locking wallet account:", err)
+	}
+	return id, balance, err
+}
+
+// systemAccountTx returns the ID of a system account (created on first use)
+func systemAccountTx(tx *sql.Tx, code string) (int64, error) {
+	if _, err := tx.Exec(`INSERT IGNORE INTO wallet_accounts (code) VALUES (?)`, code); err != nil {
+		log.Println("Error creating system account:", err)
+		return 0, err
+	}
+	var id int64
+	err := tx.QueryRow(`SELECT id FROM wallet_accounts WHERE code = ?`, code).Scan(&id)
+	return id, err
+}
+
+// postTx writes a balanced transaction and moves the account balances
+func postTx(tx *sql.Tx, kind, reference, description string, legs []Leg) error {
+	var sum money.Money
+	for _, l := range legs {
+		sum += l.Amount
+	}
+	if sum != 0 {
+		return fmt.Errorf("unbalanced wallet transaction %s/%s", kind, reference)
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO wallet_transactions (kind, reference, description) VALUES (?, ?, NULLIF(?, ''))`,
+		kind, reference, description,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return ErrDuplicateTransaction
+		}
+		log.Println("Error inserting wallet transaction:", err)
+		return err
+	}
+	txnID, _ := result.LastInsertId()
+
+	for _, l := range legs {
+		amount := l.Amount
+		if _, err := tx.Exec(
+			`INSERT INTO wallet_entries (transaction_id, account_id, amount) VALUES (?, ?, ?)`,
+			txnID, l.AccountID, amount,
+		); err != nil {
+			log.Println("Error inserting wallet entry:", err)
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE wallet_accounts SET balance = balance + ? WHERE id = ?`, amount, l.AccountID); err != nil {
+			log.Println("Error updating wallet balance:", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// transactionExistsTx tells whether (kind, reference) was already posted
+func transactionExistsTx(tx *sql.Tx, kind, reference string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM wallet_transactions WHERE kind = ? AND reference = ?`, kind, reference).Scan(&count)
+	return count > 0, err
+}
+
+// FindBalanceByUserID returns the player's balance (0 before the first top-up)
+func FindBalanceByUserID(userID int64) (money.Money, error) {
+	var balance money.Money
+	err := db.DB.QueryRow(`SELECT balance FROM wallet_accounts WHERE user_id = ?`, userID).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		log.Println("Error fetching wallet balance:", err)
+	}
+	return balance, err
+}
+
+// FindEntriesByUserID returns the player's latest statement lines
+func FindEntriesByUserID(userID int64, limit int) ([]Entry, error) {
+	query := `
+		SELECT e.id, t.id, t.kind, t.reference, COALESCE(t.description, ''), e.amount, e.created_at
+		FROM wallet_entries e
+		JOIN wallet_accounts a ON e.account_id = a.id
+		JOIN wallet_transactions t ON e.transaction_id = t.id
+		WHERE a.user_id = ?
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT ?
+	`
+	rows, err := db.DB.Query(query, userID, limit)
+	if err != nil {
+		log.Println("Error fetching wallet entries:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]Entry, 0)
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.TransactionID, &e.Kind, &e.Reference, &e.Description, &e.Amount, &e.CreatedAt); err != nil {
+			log.Println("Error scanning wallet entry:", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// CreateTopup records a pending top-up
+func CreateTopup(t *Topup) error {
+	result, err := db.DB.Exec(`INSERT INTO wallet_topups (user_id, amount, status) VALUES (?, ?, 'pending')`, t.UserID, t.Amount)
+	if err != nil {
+		log.Println("Error inserting top-up:", err)
+		return err
+	}
+	t.ID, _ = result.LastInsertId()
+	t.Status = "pending"
+	return nil
+}
+
+// SetTopupOrder stores the Razorpay order created for a top-up
+func SetTopupOrder(topupID int64, orderID string) error {
+	_, err := db.DB.Exec(`UPDATE wallet_topups SET razorpay_order_id = ? WHERE id = ?`, orderID, topupID)
+	if err != nil {
+		log.Println("Error saving top-up order:", err)
+	}
+	return err
+}
+
+// FindTopupByOrder returns the top-up (and its user) a Razorpay order was created for
+func FindTopupByOrder(orderID string) (int64, int64, error) {
+	var topupID, userID int64
+	err := db.DB.QueryRow(`SELECT id, user_id FROM wallet_topups WHERE razorpay_order_id = ?`, orderID).Scan(&topupID, &userID)
+	return topupID, userID, err
+}
+
+// lockTopupTx fetches and locks a top-up
+func lockTopupTx(tx *sql.Tx, topupID int64) (*Topup, error) {
+	var t Topup
+	err := tx.QueryRow(`
+		SELECT id, user_id, amount, COALESCE(razorpay_order_id, ''), COALESCE(razorpay_payment_id, ''), status, created_at
+		FROM wallet_topups WHERE id = ? FOR UPDATE
+	`, topupID).Scan(&t.ID, &t.UserID, &t.Amount, &t.RazorpayOrderID, &t.PaymentID, &t.Status, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,241 @@
+// wallet/wallet_service.go
+package wallet
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/JkD004/playarena-backend/db"
+	"github.com/JkD004/playarena-backend/gateway"
+
+	"github.com/JkD004/playarena-backend/pkg/money"
+)
+
+// MaxTopupAmount keeps a single top-up within a sane range
+const MaxTopupAmount = money.Money(50000 * 100)
+
+func bookingRef(bookingID int64) string {
+	return fmt.Sprintf("booking:%d", bookingID)
+}
+
+// GetWallet returns the balance and the latest 50 statement lines
+func GetWallet(userID int64) (*Wallet, error) {
+	balance, err := FindBalanceByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := FindEntriesByUserID(userID, 50)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{UserID: userID, Balance: balance, Entries: entries}, nil
+}
+
+// HoldForBookingTx moves up to `amount` from the player's wallet into the holds account
+// for an unpaid booking. Returns how much was taken (limited by the balance).
+func HoldForBookingTx(tx *sql.Tx, userID, bookingID int64, amount money.Money) (money.Money, error) {
+	accountID, balance, err := userAccountTx(tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	take := money.Min(balance, amount)
+	if take <= 0 {
+		return 0, ErrInsufficientFunds
+	}
+
+	holdsID, err := systemAccountTx(tx, AccountBookingHolds)
+	if err != nil {
+		return 0, err
+	}
+	err = postTx(tx, KindBookingHold, bookingRef(bookingID), "Paid towards booking", []Leg{
+		{AccountID: accountID, Amount: -take},
+		{AccountID: holdsID, Amount: take},
+	})
+	return take, err
+}
+
+// CaptureBookingTx turns a booking's held wallet money into revenue once the booking is confirmed
+func CaptureBookingTx(tx *sql.Tx, bookingID int64, amount money.Money) error {
+	return settleHoldTx(tx, bookingID, amount, KindBookingCapture, AccountBookingRevenue, 0)
+}
+
+// ReleaseBookingTx gives a booking's held wallet money back when the booking is dropped unpaid
+func ReleaseBookingTx(tx *sql.Tx, userID, bookingID int64, amount money.Money) error {
+	return settleHoldTx(tx, bookingID, amount, KindBookingRelease, "", userID)
+}
+
+// settleHoldTx empties a booking's hold either into a system account or back to the player.
+// A hold is settled only once: whichever of capture/release comes first wins.
+func settleHoldTx(tx *sql.Tx, bookingID int64, amount money.Money, kind, toCode string, toUserID int64) error {
+	ref := bookingRef(bookingID)
+	for _, k := range []string{KindBookingCapture, KindBookingRelease} {
+		done, err := transactionExistsTx(tx, k, ref)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+
+	holdsID, err := systemAccountTx(tx, AccountBookingHolds)
+	if err != nil {
+		return err
+	}
+	var toID int64
+	if toUserID != 0 {
+		toID, _, err = userAccountTx(tx, toUserID)
+	} else {
+		toID, err = systemAccountTx(tx, toCode)
+	}
+	if err != nil {
+		return err
+	}
+
+	description := "Booking confirmed"
+	if kind == KindBookingRelease {
+		description = "Returned from unpaid booking"
+	}
+	return postTx(tx, kind, ref, description, []Leg{
+		{AccountID: holdsID, Amount: -amount},
+		{AccountID: toID, Amount: amount},
+	})
+}
+
+// CreditRefund puts a booking refund into the player's wallet straight away
+func CreditRefund(userID, bookingID int64, amount money.Money) error {
+	return creditRefund(userID, bookingRef(bookingID), "Refund for canceled booking", amount)
+}
+
+// CreditRescheduleRefund puts the price difference of a move to a cheaper slot into the wallet
+func CreditRescheduleRefund(userID, rescheduleID int64, amount money.Money) error {
+	return creditRefund(userID, fmt.Sprintf("reschedule:%d", rescheduleID), "Refund of reschedule price difference", amount)
+}
+
+func creditRefund(userID int64, reference, description string, amount money.Money) error {
+	if amount <= 0 {
+		return errors.New("refund amount must be positive")
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	accountID, _, err := userAccountTx(tx, userID)
+	if err != nil {
+		return err
+	}
+	revenueID, err := systemAccountTx(tx, AccountBookingRevenue)
+	if err != nil {
+		return err
+	}
+	if err := postTx(tx, KindRefund, reference, description, []Leg{
+		{AccountID: revenueID, Amount: -amount},
+		{AccountID: accountID, Amount: amount},
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// StartTopup creates a Razorpay order for adding money to the wallet
+func StartTopup(userID int64, amount money.Money) (*Topup, error) {
+	if amount < money.FromPaise(100) || amount > MaxTopupAmount {
+		return nil, fmt.Errorf("top-up amount must be between ₹1 and %s", MaxTopupAmount.Format())
+	}
+
+	t := &Topup{UserID: userID, Amount: amount}
+	if err := CreateTopup(t); err != nil {
+		return nil, errors.New("failed to start top-up")
+	}
+
+	order, err := gateway.CreateOrderFor(gateway.PurposeTopup, t.ID, userID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetTopupOrder(t.ID, order.OrderID); err != nil {
+		return nil, errors.New("failed to start top-up")
+	}
+	t.RazorpayOrderID = order.OrderID
+	return t, nil
+}
+
+// CompleteTopup verifies the Razorpay payment and credits the wallet. Verifying twice credits once.
+func CompleteTopup(userID, topupID int64, orderID, paymentID, signature string) (*Topup, error) {
+	if _, err := gateway.VerifyCheckout(gateway.PurposeTopup, topupID, orderID, paymentID, signature); err != nil {
+		return nil, err
+	}
+	t, err := creditTopup(topupID, userID, orderID, paymentID)
+	if err == nil {
+		_ = gateway.MarkOrderPaid(orderID)
+	}
+	return t, err
+}
+
+// ConfirmTopupByOrder credits the top-up a captured Razorpay order belongs to (payment webhook).
+// It returns false when no top-up uses the order.
+func ConfirmTopupByOrder(orderID, paymentID string) (bool, error) {
+	topupID, userID, err := FindTopupByOrder(orderID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = creditTopup(topupID, userID, orderID, paymentID)
+	return true, err
+}
+
+// creditTopup moves a paid top-up into the user's wallet exactly once
+func creditTopup(topupID, userID int64, orderID, paymentID string) (*Topup, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := lockTopupTx(tx, topupID)
+	if err != nil || t.UserID != userID {
+		return nil, errors.New("top-up not found")
+	}
+	if t.RazorpayOrderID != orderID {
+		return nil, errors.New("payment does not belong to this top-up")
+	}
+	if t.Status == "completed" {
+		return t, nil
+	}
+
+	accountID, _, err := userAccountTx(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	gatewayID, err := systemAccountTx(tx, AccountGateway)
+	if err != nil {
+		return nil, err
+	}
+	err = postTx(tx, KindTopup, fmt.Sprintf("topup:%d", t.ID), "Wallet top-up", []Leg{
+		{AccountID: gatewayID, Amount: -t.Amount},
+		{AccountID: accountID, Amount: t.Amount},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(
+		`UPDATE wallet_topups SET status = 'completed', razorpay_payment_id = ? WHERE id = ?`,
+		paymentID, t.ID,
+	); err != nil {
+		log.Println("Error completing top-up:", err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	t.Status = "completed"
+	t.PaymentID = paymentID
+	return t, nil
+}
@@ -0,0 +1,25 @@
+// worker/calendar_sync.go
+package worker
+
+import (
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/booking"
+)
+
+// StartCalendarSyncTask keeps blocks imported from external calendars in sync
+func StartCalendarSyncTask() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	// Each source is re-fetched once booking.CalendarSyncInterval has passed
+	for range ticker.C {
+		synced, err := booking.SyncDueCalendarSources()
+		if err != nil {
+			log.Println("❌ Error running calendar sync:", err)
+		} else if synced > 0 {
+			log.Printf("📅 Calendar sync: refreshed %d source(s).\n", synced)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/JkD004/playarena-backend/booking"
+	"github.com/JkD004/playarena-backend/pkg/idempotency"
 )
 
 // StartCleanupTask runs a background loop to clean up old bookings
@@ -16,12 +17,18 @@ func StartCleanupTask() {
 
 	// Run forever in the background
 	for range ticker.C {
-		// Cancel bookings pending for more than 10 minutes
-		rowsAffected, err := booking.AutoCancelPendingBookings(10)
+		// Release expired slot holds (legacy rows without a hold: pending for more than 10 minutes)
+		// and offer the freed slots to the waitlist
+		rowsAffected, err := booking.ReleaseExpiredHolds(10)
 		if err != nil {
 			log.Println("❌ Error running cleanup task:", err)
 		} else if rowsAffected > 0 {
 			log.Printf("🧹 Cleanup: Canceled %d expired pending bookings.\n", rowsAffected)
 		}
+
+		// Forget idempotency keys past their replay window
+		if _, err := idempotency.PurgeExpired(time.Now()); err != nil {
+			log.Println("❌ Error purging idempotency keys:", err)
+		}
 	}
 }
\ No newline at end of file
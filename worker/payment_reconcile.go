@@ -0,0 +1,27 @@
+// worker/payment_reconcile.go
+package worker
+
+import (
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/payment"
+)
+
+// StartPaymentReconcileTask catches up on payments the checkout and webhook both missed
+func StartPaymentReconcileTask() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		result, err := payment.ReconcileOrders(time.Now())
+		if err != nil {
+			log.Println("❌ Error reconciling payments:", err)
+			continue
+		}
+		if result.Applied+result.Refunded+result.Expired+result.Errors > 0 {
+			log.Printf("💳 Payments reconciled: %d checked, %d applied, %d refunded, %d expired, %d errors\n",
+				result.Checked, result.Applied, result.Refunded, result.Expired, result.Errors)
+		}
+	}
+}
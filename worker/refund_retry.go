@@ -0,0 +1,27 @@
+// worker/refund_retry.go
+package worker
+
+import (
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/refund"
+)
+
+// StartRefundRetryTask sends refunds the gateway or the bank turned down back out on their schedule
+func StartRefundRetryTask() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		result, err := refund.RetryDue(time.Now())
+		if err != nil {
+			log.Println("❌ Error retrying refunds:", err)
+			continue
+		}
+		if result.Retried > 0 {
+			log.Printf("💸 Refunds retried: %d tried, %d resent, %d already through, %d failed\n",
+				result.Retried, result.Resent, result.Recovered, result.Failed)
+		}
+	}
+}
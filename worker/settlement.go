@@ -0,0 +1,25 @@
+// worker/settlement.go
+package worker
+
+import (
+	"log"
+	"time"
+
+	"github.com/JkD004/playarena-backend/settlement"
+)
+
+// StartSettlementTask closes each finished week into payout statements for venue owners
+func StartSettlementTask() {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	// Statements for a week are only generated once; later runs find nothing left to settle
+	for range ticker.C {
+		created, err := settlement.GenerateDueStatements(time.Now())
+		if err != nil {
+			log.Println("❌ Error generating payout statements:", err)
+		} else if len(created) > 0 {
+			log.Printf("💰 Settlement: created %d payout statement(s).\n", len(created))
+		}
+	}
+}